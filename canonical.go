@@ -0,0 +1,166 @@
+package capnp
+
+// Canonicalize encodes a struct into its canonical form: a single
+// segment without a stream header, with objects laid out in preorder,
+// trailing zero words truncated from every struct's data and pointer
+// sections, and no far pointers.  Semantically equal structs produce
+// identical bytes, so the result is suitable for hashing or signing.
+// See https://capnproto.org/encoding.html#canonicalization for details.
+func Canonicalize(s Struct) ([]byte, error) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+	if err := canonicalPtr(seg, 0, s); err != nil {
+		return nil, err
+	}
+	return seg.Data(), nil
+}
+
+// canonicalPtr writes the canonical form of p to dst, preferring
+// placement in dst, and points the pointer at paddr to it.  Since dst
+// must belong to a single segment message, the pointer is always near.
+func canonicalPtr(dst *Segment, paddr Address, p Pointer) error {
+	if !IsValid(p) {
+		dst.writeRawPointer(paddr, 0)
+		return nil
+	}
+	switch p := p.underlying().(type) {
+	case Struct:
+		sz := canonicalStructSize(p)
+		if sz.isZero() {
+			// An empty struct placed directly after its pointer would
+			// encode as null, so the spec gives it an offset of -1.
+			dst.writeRawPointer(paddr, rawStructPointer(-1, ObjectSize{}))
+			return nil
+		}
+		cs, err := NewStruct(dst, sz)
+		if err != nil {
+			return err
+		}
+		dst.writeRawPointer(paddr, cs.value(paddr))
+		return fillCanonicalStruct(cs, p)
+	case List:
+		cl, err := canonicalList(dst, p)
+		if err != nil {
+			return err
+		}
+		dst.writeRawPointer(paddr, cl.value(paddr))
+		return nil
+	case Interface:
+		dst.writeRawPointer(paddr, p.value(paddr))
+		return nil
+	default:
+		panic("unreachable")
+	}
+}
+
+// fillCanonicalStruct copies src's data section into dst and writes the
+// canonical forms of src's pointers into dst's pointer section.  dst
+// must have been allocated with at most canonicalStructSize(src).
+func fillCanonicalStruct(dst, src Struct) error {
+	copy(dst.seg.slice(dst.off, dst.size.DataSize), src.seg.slice(src.off, src.size.DataSize))
+	for i := uint16(0); i < dst.size.PointerCount; i++ {
+		p, err := src.Pointer(i)
+		if err != nil {
+			return err
+		}
+		if err := canonicalPtr(dst.seg, dst.pointerAddress(i), p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canonicalStructSize returns the size of s with trailing zero words
+// removed from its data and pointer sections.  A data section that
+// isn't a whole number of words, like that of a struct read from a list
+// of primitives, is treated as padded with zeros to the next word.
+func canonicalStructSize(s Struct) ObjectSize {
+	if s.seg == nil {
+		return ObjectSize{}
+	}
+	var sz ObjectSize
+	data := s.seg.slice(s.off, s.size.DataSize)
+	for end := len(data); end > 0; {
+		start := (end - 1) &^ (int(wordSize) - 1)
+		if !isZero(data[start:end]) {
+			sz.DataSize = Size(start) + wordSize
+			break
+		}
+		end = start
+	}
+	for i := int32(s.size.PointerCount) - 1; i >= 0; i-- {
+		if s.seg.readRawPointer(s.pointerAddress(uint16(i))) != 0 {
+			sz.PointerCount = uint16(i + 1)
+			break
+		}
+	}
+	return sz
+}
+
+// canonicalList allocates the canonical form of l in dst.
+func canonicalList(dst *Segment, l List) (List, error) {
+	switch {
+	case l.flags&isCompositeList != 0:
+		var sz ObjectSize
+		for i := 0; i < l.Len(); i++ {
+			esz := canonicalStructSize(l.Struct(i))
+			if esz.DataSize > sz.DataSize {
+				sz.DataSize = esz.DataSize
+			}
+			if esz.PointerCount > sz.PointerCount {
+				sz.PointerCount = esz.PointerCount
+			}
+		}
+		cl, err := NewCompositeList(dst, sz, l.length)
+		if err != nil {
+			return List{}, err
+		}
+		for i := 0; i < cl.Len(); i++ {
+			if err := fillCanonicalStruct(cl.Struct(i), l.Struct(i)); err != nil {
+				return List{}, err
+			}
+		}
+		return cl, nil
+	case l.size.PointerCount > 0:
+		cl, err := NewPointerList(dst, l.length)
+		if err != nil {
+			return List{}, err
+		}
+		pl := PointerList{l}
+		for i := 0; i < cl.Len(); i++ {
+			p, err := pl.At(i)
+			if err != nil {
+				return List{}, err
+			}
+			addr, _ := cl.elem(i)
+			if err := canonicalPtr(cl.seg, addr, p); err != nil {
+				return List{}, err
+			}
+		}
+		return cl.List, nil
+	default:
+		// Data-only lists are already canonical, except for the unused
+		// bits at the end of a bit list.
+		sz := dataSize(l)
+		seg, addr, err := alloc(dst, sz)
+		if err != nil {
+			return List{}, err
+		}
+		b := seg.slice(addr, sz)
+		copy(b, l.seg.slice(l.off, sz))
+		if n := l.length % 8; l.flags&isBitList != 0 && n != 0 {
+			// Bits past the end of the list must be zero.
+			b[len(b)-1] &= 1<<uint(n) - 1
+		}
+		return List{
+			seg:        seg,
+			off:        addr,
+			length:     l.length,
+			size:       l.size,
+			flags:      l.flags,
+			depthLimit: maxDepth,
+		}, nil
+	}
+}
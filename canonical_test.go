@@ -0,0 +1,168 @@
+package capnp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	{
+		// null
+		b, err := Canonicalize(Struct{})
+		if err != nil {
+			t.Fatal("Canonicalize(Struct{}):", err)
+		}
+		if want := make([]byte, 8); !bytes.Equal(b, want) {
+			t.Errorf("Canonicalize(Struct{}) = % 02x; want % 02x", b, want)
+		}
+	}
+	{
+		// empty struct
+		_, seg, _ := NewMessage(SingleSegment(nil))
+		s, _ := NewStruct(seg, ObjectSize{DataSize: 16, PointerCount: 2})
+		b, err := Canonicalize(s)
+		if err != nil {
+			t.Fatal("Canonicalize(empty struct):", err)
+		}
+		want := rawWords(rawStructPointer(-1, ObjectSize{}))
+		if !bytes.Equal(b, want) {
+			t.Errorf("Canonicalize(empty struct) = % 02x; want % 02x", b, want)
+		}
+	}
+	{
+		// truncated sections and preorder layout
+		_, seg, _ := NewMessage(SingleSegment(nil))
+		text, _ := NewText(seg, "hi")
+		s, _ := NewStruct(seg, ObjectSize{DataSize: 16, PointerCount: 2})
+		s.SetUint32(0, 0xdeadbeef)
+		if err := s.SetPointer(0, text); err != nil {
+			t.Fatal(err)
+		}
+		b, err := Canonicalize(s)
+		if err != nil {
+			t.Fatal("Canonicalize(struct with text):", err)
+		}
+		want := rawWords(
+			rawStructPointer(0, ObjectSize{DataSize: 8, PointerCount: 1}),
+			0xdeadbeef,
+			rawListPointer(0, byte1List, 3),
+			rawPointer('h'|'i'<<8),
+		)
+		if !bytes.Equal(b, want) {
+			t.Errorf("Canonicalize(struct with text) =\n% 02x\nwant\n% 02x", b, want)
+		}
+	}
+	{
+		// composite list element size is the largest canonical size
+		_, seg, _ := NewMessage(SingleSegment(nil))
+		s, _ := NewStruct(seg, ObjectSize{PointerCount: 1})
+		l, _ := NewCompositeList(seg, ObjectSize{DataSize: 24, PointerCount: 1}, 2)
+		l.Struct(0).SetUint64(0, 1)
+		l.Struct(1).SetUint64(8, 2)
+		if err := s.SetPointer(0, l); err != nil {
+			t.Fatal(err)
+		}
+		b, err := Canonicalize(s)
+		if err != nil {
+			t.Fatal("Canonicalize(struct with list):", err)
+		}
+		want := rawWords(
+			rawStructPointer(0, ObjectSize{PointerCount: 1}),
+			rawListPointer(0, compositeList, 4),
+			rawStructPointer(2, ObjectSize{DataSize: 16}),
+			1, 0,
+			0, 2,
+		)
+		if !bytes.Equal(b, want) {
+			t.Errorf("Canonicalize(struct with list) =\n% 02x\nwant\n% 02x", b, want)
+		}
+	}
+	{
+		// struct read from a list of primitives has a partial data word
+		_, seg, _ := NewMessage(SingleSegment(nil))
+		l, _ := NewInt32List(seg, 2)
+		l.Set(1, 7)
+		b, err := Canonicalize(l.Struct(1))
+		if err != nil {
+			t.Fatal("Canonicalize(Int32List element):", err)
+		}
+		want := rawWords(rawStructPointer(0, ObjectSize{DataSize: 8}), 7)
+		if !bytes.Equal(b, want) {
+			t.Errorf("Canonicalize(Int32List element) =\n% 02x\nwant\n% 02x", b, want)
+		}
+	}
+	{
+		// unused bits at the end of a bit list are cleared
+		_, seg, _ := NewMessage(SingleSegment(nil))
+		s, _ := NewStruct(seg, ObjectSize{PointerCount: 1})
+		l, _ := NewBitList(seg, 3)
+		l.Set(0, true)
+		l.Set(2, true)
+		seg.slice(l.off, 1)[0] |= 0xf8
+		if err := s.SetPointer(0, l); err != nil {
+			t.Fatal(err)
+		}
+		b, err := Canonicalize(s)
+		if err != nil {
+			t.Fatal("Canonicalize(struct with bit list):", err)
+		}
+		want := rawWords(
+			rawStructPointer(0, ObjectSize{PointerCount: 1}),
+			rawListPointer(0, bit1List, 3),
+			5,
+		)
+		if !bytes.Equal(b, want) {
+			t.Errorf("Canonicalize(struct with bit list) =\n% 02x\nwant\n% 02x", b, want)
+		}
+	}
+}
+
+func TestCanonicalizeAllocationOrder(t *testing.T) {
+	build := func(arena Arena, reverse bool) Struct {
+		_, seg, err := NewMessage(arena)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var a, b UInt8List
+		if reverse {
+			b, _ = NewText(seg, "second")
+			a, _ = NewText(seg, "first")
+		} else {
+			a, _ = NewText(seg, "first")
+			b, _ = NewText(seg, "second")
+		}
+		s, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.SetUint16(0, 42)
+		if err := s.SetPointer(0, a); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetPointer(1, b); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	b1, err := Canonicalize(build(SingleSegment(nil), false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := Canonicalize(build(MultiSegment([][]byte{make([]byte, 0, 8)}), true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b1, b2) {
+		t.Errorf("canonical forms differ:\n% 02x\n% 02x", b1, b2)
+	}
+}
+
+// rawWords encodes the given words in little-endian byte order.
+func rawWords(words ...rawPointer) []byte {
+	b := make([]byte, len(words)*8)
+	for i, w := range words {
+		binary.LittleEndian.PutUint64(b[i*8:], uint64(w))
+	}
+	return b
+}
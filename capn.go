@@ -17,6 +17,11 @@ type Segment struct {
 	msg  *Message
 	id   SegmentID
 	data []byte
+
+	// readOnly is set for segments whose data can't be written to, like
+	// those of a MappedArena.  Writing to them fails with errReadOnly
+	// instead of faulting.
+	readOnly bool
 }
 
 // Message returns the message that contains s.
@@ -71,18 +76,30 @@ func (s *Segment) readRawPointer(addr Address) rawPointer {
 }
 
 func (s *Segment) writeUint8(addr Address, val uint8) {
+	if s.readOnly {
+		panic(errReadOnly)
+	}
 	s.slice(addr, 1)[0] = val
 }
 
 func (s *Segment) writeUint16(addr Address, val uint16) {
+	if s.readOnly {
+		panic(errReadOnly)
+	}
 	binary.LittleEndian.PutUint16(s.slice(addr, 2), val)
 }
 
 func (s *Segment) writeUint32(addr Address, val uint32) {
+	if s.readOnly {
+		panic(errReadOnly)
+	}
 	binary.LittleEndian.PutUint32(s.slice(addr, 4), val)
 }
 
 func (s *Segment) writeUint64(addr Address, val uint64) {
+	if s.readOnly {
+		panic(errReadOnly)
+	}
 	binary.LittleEndian.PutUint64(s.slice(addr, 8), val)
 }
 
@@ -99,9 +116,10 @@ func (s *Segment) root() PointerList {
 		return PointerList{}
 	}
 	return PointerList{List{
-		seg:    s,
-		length: 1,
-		size:   sz,
+		seg:        s,
+		length:     1,
+		size:       sz,
+		depthLimit: s.msg.depthLimit(),
 	}}
 }
 
@@ -112,7 +130,11 @@ func (s *Segment) lookupSegment(id SegmentID) (*Segment, error) {
 	return s.msg.Segment(id)
 }
 
-func (s *Segment) readPtr(off Address) (Pointer, error) {
+// readPtr reads the pointer at off.  depthLimit is the depth limit of
+// the object that contains the pointer; the returned object will have a
+// limit one less.  The size of the object is charged against the
+// message's read limiter.
+func (s *Segment) readPtr(off Address, depthLimit uint) (Pointer, error) {
 	var err error
 	val := s.readRawPointer(off)
 	s, off, val, err = s.resolveFarPointer(off, val)
@@ -122,6 +144,62 @@ func (s *Segment) readPtr(off Address) (Pointer, error) {
 	if val == 0 {
 		return nil, nil
 	}
+	if depthLimit == 0 {
+		return nil, ErrDepthLimit
+	}
+	p, err := s.pointerValue(off, val, depthLimit-1)
+	if err != nil {
+		return nil, err
+	}
+	if !s.msg.ReadLimiter().canRead(readSize(p)) {
+		return nil, ErrTraverseLimit
+	}
+	return p, nil
+}
+
+// readSize returns the number of bytes that reading p is charged
+// against the read limit.  A struct or list element is charged at least
+// a word, even if it is empty, so that lists of empty elements can't be
+// used to amplify reads.
+func readSize(p Pointer) uint64 {
+	switch p := p.(type) {
+	case Struct:
+		if p.size.isZero() {
+			return uint64(wordSize)
+		}
+		return uint64(p.size.totalSize())
+	case List:
+		n := uint64(p.length)
+		if p.flags&isBitList != 0 {
+			return (n + 7) / 8
+		}
+		elem := uint64(p.size.totalSize())
+		if elem == 0 {
+			elem = uint64(wordSize)
+		}
+		sz := elem * n
+		if p.flags&isCompositeList != 0 {
+			// Include the tag word.
+			sz += uint64(wordSize)
+		}
+		return sz
+	default:
+		return 0
+	}
+}
+
+// dataSize returns the number of bytes taken by the elements of l, a
+// list without pointers.
+func dataSize(l List) Size {
+	if l.flags&isBitList != 0 {
+		return Size((l.length + 7) / 8)
+	}
+	return l.size.totalSize().times(l.length)
+}
+
+// pointerValue decodes the non-far, non-null pointer val located at off
+// into an object with the given depth limit.
+func (s *Segment) pointerValue(off Address, val rawPointer, depthLimit uint) (Pointer, error) {
 	// Be wary of overflow. Offset is 30 bits signed. List size is 29 bits
 	// unsigned. For both of these we need to check in terms of words if
 	// using 32 bit maths as bits or bytes will overflow.
@@ -136,9 +214,10 @@ func (s *Segment) readPtr(off Address) (Pointer, error) {
 			return nil, errPointerAddress
 		}
 		return Struct{
-			seg:  s,
-			off:  addr,
-			size: sz,
+			seg:        s,
+			off:        addr,
+			size:       sz,
+			depthLimit: depthLimit,
 		}, nil
 	case listPointer:
 		addr, ok := val.offset().resolve(off)
@@ -157,31 +236,35 @@ func (s *Segment) readPtr(off Address) (Pointer, error) {
 			}
 			sz := hdr.structSize()
 			n := int32(hdr.offset())
-			// TODO(light): check that this has the same end address
-			if !s.regionInBounds(addr, sz.totalSize().times(n)) {
-				return nil, errPointerAddress
+			// The tag's element count must fit in the words that the
+			// list pointer claims, excluding the tag itself.
+			if n < 0 || int64(sz.totalSize())*int64(n) > int64(lsize-wordSize) {
+				return nil, errBadTag
 			}
 			return List{
-				seg:    s,
-				size:   sz,
-				off:    addr,
-				length: n,
-				flags:  isCompositeList,
+				seg:        s,
+				size:       sz,
+				off:        addr,
+				length:     n,
+				flags:      isCompositeList,
+				depthLimit: depthLimit,
 			}, nil
 		}
 		if lt == bit1List {
 			return List{
-				seg:    s,
-				off:    addr,
-				length: val.numListElements(),
-				flags:  isBitList,
+				seg:        s,
+				off:        addr,
+				length:     val.numListElements(),
+				flags:      isBitList,
+				depthLimit: depthLimit,
 			}, nil
 		}
 		return List{
-			seg:    s,
-			size:   val.elementSize(),
-			off:    addr,
-			length: val.numListElements(),
+			seg:        s,
+			size:       val.elementSize(),
+			off:        addr,
+			length:     val.numListElements(),
+			depthLimit: depthLimit,
 		}, nil
 	case otherPointer:
 		if val.otherPointerType() != 0 {
@@ -299,6 +382,9 @@ func needsCopy(dest *Segment, src Pointer) bool {
 }
 
 func (destSeg *Segment) writePtr(cc copyContext, off Address, src Pointer) error {
+	if destSeg.readOnly {
+		return errReadOnly
+	}
 	// handle nulls
 	if !IsValid(src) {
 		destSeg.writeRawPointer(off, 0)
@@ -381,9 +467,10 @@ func copyPointer(cc copyContext, dstSeg *Segment, dstAddr Address, src Pointer)
 	switch src := src.underlying().(type) {
 	case Struct:
 		dst := Struct{
-			seg:  newSeg,
-			off:  newAddr,
-			size: src.size,
+			seg:        newSeg,
+			off:        newAddr,
+			size:       src.size,
+			depthLimit: maxDepth,
 			// clear flags
 		}
 		key.newval = dst
@@ -393,11 +480,12 @@ func copyPointer(cc copyContext, dstSeg *Segment, dstAddr Address, src Pointer)
 		}
 	case List:
 		dst := List{
-			seg:    newSeg,
-			off:    newAddr,
-			length: src.length,
-			size:   src.size,
-			flags:  src.flags,
+			seg:        newSeg,
+			off:        newAddr,
+			length:     src.length,
+			size:       src.size,
+			flags:      src.flags,
+			depthLimit: maxDepth,
 		}
 		if dst.flags&isCompositeList != 0 {
 			// Copy tag word
@@ -459,4 +547,5 @@ var (
 	errOverlap     = errors.New("capnp: overlapping data on copy")
 	errListSize    = errors.New("capnp: invalid list size")
 	errObjectType  = errors.New("capnp: invalid object type")
+	errReadOnly    = errors.New("capnp: segment is read-only")
 )
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"strings"
+
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// A brand holds the types bound to the generic parameters of a node
+// and its enclosing scopes.  A nil *brand binds nothing.
+type brand struct {
+	scopes []brandScope
+}
+
+type brandScope struct {
+	id     uint64       // ID of the node that declares the parameters
+	params []*boundType // nil for unbound parameters
+}
+
+// A boundType is a type whose generic parameters have been resolved
+// against a brand.
+type boundType struct {
+	schema.Type
+	brand *brand     // bindings of a struct or interface type
+	elem  *boundType // element type of a list
+}
+
+// param returns the type bound to a generic parameter or nil if the
+// parameter is unbound.
+func (b *brand) param(scopeID uint64, index uint16) *boundType {
+	if b == nil {
+		return nil
+	}
+	for _, s := range b.scopes {
+		if s.id == scopeID && int(index) < len(s.params) {
+			return s.params[index]
+		}
+	}
+	return nil
+}
+
+// scope returns the bindings for the parameters declared by the node
+// with the given ID.
+func (b *brand) scope(id uint64) []*boundType {
+	if b == nil {
+		return nil
+	}
+	for _, s := range b.scopes {
+		if s.id == id {
+			return s.params
+		}
+	}
+	return nil
+}
+
+// resolve replaces the generic parameters in t with the types b binds
+// them to.  Unbound parameters are left as AnyPointer.
+func (b *brand) resolve(t schema.Type) *boundType {
+	switch t.Which() {
+	case schema.Type_Which_anyPointer:
+		if ap := t.AnyPointer(); ap.Which() == schema.Type_anyPointer_Which_parameter {
+			if bt := b.param(ap.Parameter().ScopeId(), ap.Parameter().ParameterIndex()); bt != nil {
+				return bt
+			}
+		}
+	case schema.Type_Which_structGroup:
+		br, _ := t.StructGroup().Brand()
+		return &boundType{Type: t, brand: b.bind(br)}
+	case schema.Type_Which_interface:
+		br, _ := t.Interface().Brand()
+		return &boundType{Type: t, brand: b.bind(br)}
+	case schema.Type_Which_list:
+		et, _ := t.List().ElementType()
+		return &boundType{Type: t, elem: b.resolve(et)}
+	}
+	return &boundType{Type: t}
+}
+
+// bind returns the brand described by br, resolving the types it binds
+// against b.  It returns nil if br doesn't bind any parameters to
+// concrete types.
+func (b *brand) bind(br schema.Brand) *brand {
+	scopes, _ := br.Scopes()
+	var nb *brand
+	for i := 0; i < scopes.Len(); i++ {
+		s := scopes.At(i)
+		bs := brandScope{id: s.ScopeId()}
+		switch s.Which() {
+		case schema.Brand_Scope_Which_bind:
+			bindings, _ := s.Bind()
+			bs.params = make([]*boundType, bindings.Len())
+			for j := range bs.params {
+				bd := bindings.At(j)
+				if bd.Which() != schema.Brand_Binding_Which_type {
+					continue
+				}
+				t, _ := bd.Type()
+				if bt := b.resolve(t); bt.Which() != schema.Type_Which_anyPointer {
+					bs.params[j] = bt
+				}
+			}
+		case schema.Brand_Scope_Which_inherit:
+			bs.params = b.scope(bs.id)
+		}
+		if !bs.isBound() {
+			continue
+		}
+		if nb == nil {
+			nb = new(brand)
+		}
+		nb.scopes = append(nb.scopes, bs)
+	}
+	return nb
+}
+
+func (s brandScope) isBound() bool {
+	for _, p := range s.params {
+		if p != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// argNames returns the names of the bound types, in the order of the
+// parameters, for use in the name of a specialized type.
+func (b *brand) argNames() []string {
+	var names []string
+	for _, s := range b.scopes {
+		for _, p := range s.params {
+			names = append(names, p.argName())
+		}
+	}
+	return names
+}
+
+func (bt *boundType) argName() string {
+	if bt == nil {
+		return "AnyPointer"
+	}
+	switch bt.Which() {
+	case schema.Type_Which_text:
+		return "Text"
+	case schema.Type_Which_data:
+		return "Data"
+	case schema.Type_Which_anyPointer:
+		return "AnyPointer"
+	case schema.Type_Which_enum:
+		return findNode(bt.Enum().TypeId()).Name
+	case schema.Type_Which_structGroup:
+		n := findNode(bt.StructGroup().TypeId())
+		if bt.brand == nil {
+			return n.Name
+		}
+		return n.Name + "_" + strings.Join(bt.brand.argNames(), "_")
+	case schema.Type_Which_interface:
+		n := findNode(bt.Interface().TypeId())
+		if bt.brand == nil {
+			return n.Name
+		}
+		return n.Name + "_" + strings.Join(bt.brand.argNames(), "_")
+	case schema.Type_Which_list:
+		return bt.elem.argName() + "List"
+	default:
+		return title(bt.Which().String())
+	}
+}
+
+// specialize returns a node for n with its generic parameters bound by
+// b.  The first time a specialization is used in a Go package, it is
+// queued to be written to a file of its own in from's package.  If b is
+// nil, specialize returns n.
+func (n *node) specialize(b *brand, from *node) *node {
+	if b == nil {
+		return n
+	}
+	args := b.argNames()
+	name := n.Name + "_" + strings.Join(args, "_")
+	key := specKey{imp: from.imp, name: name}
+	if s := g_specs[key]; s != nil {
+		return s
+	}
+	s := &node{
+		Node:    n.Node,
+		pkg:     from.pkg,
+		imp:     from.imp,
+		Name:    name,
+		brand:   b,
+		generic: n,
+		args:    args,
+	}
+	g_specs[key] = s
+	g_specQueue = append(g_specQueue, s)
+	return s
+}
+
+// groupNode returns the node for the group field f of n.  Groups of a
+// specialized struct are specialized with the same bindings.
+func (n *node) groupNode(f field) *node {
+	g := findNode(f.Group().TypeId())
+	if n.brand == nil {
+		return g
+	}
+	return &node{
+		Node:  g.Node,
+		pkg:   n.pkg,
+		imp:   n.imp,
+		Name:  n.Name + "_" + f.Name,
+		brand: n.brand,
+	}
+}
+
+// annotations returns the annotations of n.  A specialized node is
+// documented as an instance of its generic node.
+func (n *node) annotations() *annotations {
+	nann, _ := n.Annotations()
+	ann := parseAnnotations(nann)
+	if n.generic != nil {
+		ann.Doc = n.Name + " is " + n.generic.Name + " with its type parameters bound to (" + strings.Join(n.args, ", ") + ")."
+	}
+	return ann
+}
@@ -1,5 +1,3 @@
-//go:generate bash -c "capnp compile -o- schema.capnp | capnpc-go -promises=false"
-
 /*
 capnpc-go is the Cap'n proto code generator for Go.  It reads a
 CodeGeneratorRequest from stdin and for a file foo.capnp it writes
@@ -7,11 +5,29 @@ foo.capnp.go.  This is usually invoked from `capnp compile -ogo`.
 
 See https://capnproto.org/otherlang.html#how-to-write-compiler-plugins
 for more details.
+
+Generic structs and interfaces are generated with capnp.Pointer
+accessors for their type parameters.  Each binding of a generic type
+used in a schema, like Map(Text, Person), is also generated as a type
+named after its bindings, Map_Text_Person, whose accessors use the
+bound types.  Both types have the same representation, so one can be
+converted to the other.  A binding is declared in a file of its own in
+the package that uses it, named after the type, like
+Map_Text_Person.capnp.go, so files that share a binding can be
+generated together or separately.  Parameters that are left unbound are
+AnyPointers, with capnp.Pointer accessors; typed wrappers for them are
+not generated.
+
+Unless -schemas=false is given, the schema nodes of each file are
+embedded in the generated package, compressed, and registered with the
+schemas package when the package is initialized.  This lets programs
+reflect on the types at run time without the .capnp files.
 */
 package main
 
 import (
 	"bytes"
+	"compress/zlib"
 	"flag"
 	"fmt"
 	"go/format"
@@ -19,21 +35,26 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/schema"
 )
 
 var (
 	genPromises = flag.Bool("promises", true, "generate code for promises")
+	genSchemas  = flag.Bool("schemas", true, "embed schema nodes and register them at init")
 )
 
 const (
 	go_capnproto_import = "zombiezen.com/go/capnproto"
 	server_import       = go_capnproto_import + "/server"
 	context_import      = "golang.org/x/net/context"
+	schemas_import      = go_capnproto_import + "/schemas"
 )
 
 var (
@@ -41,8 +62,21 @@ var (
 	g_imports imports
 	g_segment []byte
 	g_bufname string
+
+	// Specialized generic types, keyed by the Go import path of the
+	// package they are declared in and their name.  Each specialization
+	// is written to its own file once per request, so g_specs lasts for
+	// the whole request, and g_specQueue holds the ones that the file
+	// being generated uses for the first time.
+	g_specs     map[specKey]*node
+	g_specQueue []*node
 )
 
+type specKey struct {
+	imp  string
+	name string
+}
+
 type imports struct {
 	specs []importSpec
 	used  map[string]bool // keyed on import path
@@ -55,6 +89,7 @@ func (i *imports) init() {
 	i.reserve(importSpec{path: go_capnproto_import, name: "capnp"})
 	i.reserve(importSpec{path: server_import, name: "server"})
 	i.reserve(importSpec{path: context_import, name: "context"})
+	i.reserve(importSpec{path: schemas_import, name: "schemas"})
 
 	i.reserve(importSpec{path: "bufio", name: "bufio"})
 	i.reserve(importSpec{path: "bytes", name: "bytes"})
@@ -75,6 +110,10 @@ func (i *imports) context() string {
 	return i.add(importSpec{path: context_import, name: "context"})
 }
 
+func (i *imports) schemas() string {
+	return i.add(importSpec{path: schemas_import, name: "schemas"})
+}
+
 func (i *imports) math() string {
 	return i.add(importSpec{path: "math", name: "math"})
 }
@@ -163,6 +202,16 @@ func isIdent(r rune) bool {
 	return isLower(r) || 'A' <= r && r <= 'Z' || r >= 0x80 && unicode.IsLetter(r)
 }
 
+// title returns name with its first letter upper-cased, which exports
+// a Cap'n Proto identifier in Go.
+func title(name string) string {
+	r, n := utf8.DecodeRuneInString(name)
+	if n == 0 {
+		return name
+	}
+	return string(unicode.ToUpper(r)) + name[n:]
+}
+
 type importSpec struct {
 	path string
 	name string
@@ -176,15 +225,24 @@ func (spec importSpec) String() string {
 }
 
 type node struct {
-	Node
+	schema.Node
 	pkg   string
 	imp   string
 	nodes []*node
 	Name  string
+
+	// For file nodes, every node declared in the file, including
+	// groups.  These are embedded in the generated code.
+	schemaNodes []*node
+
+	// Set for specializations of generic nodes.
+	brand   *brand
+	generic *node
+	args    []string
 }
 
 type field struct {
-	Field
+	schema.Field
 	Name string
 }
 
@@ -242,7 +300,7 @@ type annotations struct {
 	Name      string
 }
 
-func parseAnnotations(list Annotation_List) *annotations {
+func parseAnnotations(list schema.Annotation_List) *annotations {
 	ann := new(annotations)
 	for i, n := 0, list.Len(); i < n; i++ {
 		a := list.At(i)
@@ -324,14 +382,15 @@ func (n *node) resolveName(base, name string, file *node) {
 	na, _ := n.Annotations()
 	name = parseAnnotations(na).Rename(name)
 	if base == "" {
-		n.Name = strings.Title(name)
+		n.Name = title(name)
 	} else {
 		n.Name = base + "_" + name
 	}
 	n.pkg = file.pkg
 	n.imp = file.imp
+	file.schemaNodes = append(file.schemaNodes, n)
 
-	if n.Which() != Node_Which_structGroup || !n.StructGroup().IsGroup() {
+	if n.Which() != schema.Node_Which_structGroup || !n.StructGroup().IsGroup() {
 		file.nodes = append(file.nodes, n)
 	}
 
@@ -344,18 +403,18 @@ func (n *node) resolveName(base, name string, file *node) {
 		}
 	}
 
-	if n.Which() == Node_Which_structGroup {
+	if n.Which() == schema.Node_Which_structGroup {
 		fields, _ := n.StructGroup().Fields()
 		for i := 0; i < fields.Len(); i++ {
 			f := fields.At(i)
-			if f.Which() == Field_Which_group {
+			if f.Which() == schema.Field_Which_group {
 				fa, _ := f.Annotations()
 				fname, _ := f.Name()
 				fname = parseAnnotations(fa).Rename(fname)
 				findNode(f.Group().TypeId()).resolveName(n.Name, fname, file)
 			}
 		}
-	} else if n.Which() == Node_Which_interface {
+	} else if n.Which() == schema.Node_Which_interface {
 		m, _ := n.Interface().Methods()
 		for i := 0; i < m.Len(); i++ {
 			mm := m.At(i)
@@ -374,14 +433,14 @@ func (n *node) resolveName(base, name string, file *node) {
 }
 
 type enumval struct {
-	Enumerant
+	schema.Enumerant
 	Name   string
 	Val    int
 	Tag    string
 	parent *node
 }
 
-func makeEnumval(enum *node, i int, e Enumerant) enumval {
+func makeEnumval(enum *node, i int, e schema.Enumerant) enumval {
 	eann, _ := e.Annotations()
 	ann := parseAnnotations(eann)
 	name, _ := e.Name()
@@ -409,44 +468,44 @@ func (n *node) defineEnum(w io.Writer) {
 	})
 }
 
-func (n *node) writeValue(w io.Writer, t Type, v Value) {
+func (n *node) writeValue(w io.Writer, t schema.Type, v schema.Value) {
 	switch t.Which() {
-	case Type_Which_void:
+	case schema.Type_Which_void:
 		fmt.Fprintf(w, "struct{}{}")
 
-	case Type_Which_interface:
+	case schema.Type_Which_interface:
 		// The only statically representable interface value is null.
 		fmt.Fprintf(w, "%s.Client(nil)", g_imports.capnp())
 
-	case Type_Which_bool:
-		assert(v.Which() == Value_Which_bool, "expected bool value")
+	case schema.Type_Which_bool:
+		assert(v.Which() == schema.Value_Which_bool, "expected bool value")
 		if v.Bool() {
 			fmt.Fprint(w, "true")
 		} else {
 			fmt.Fprint(w, "false")
 		}
 
-	case Type_Which_uint8, Type_Which_uint16, Type_Which_uint32, Type_Which_uint64:
+	case schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
 		fmt.Fprintf(w, "uint%d(%d)", intbits(t.Which()), uintValue(t, v))
 
-	case Type_Which_int8, Type_Which_int16, Type_Which_int32, Type_Which_int64:
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64:
 		fmt.Fprintf(w, "int%d(%d)", intbits(t.Which()), intValue(t, v))
 
-	case Type_Which_float32:
-		assert(v.Which() == Value_Which_float32, "expected float32 value")
+	case schema.Type_Which_float32:
+		assert(v.Which() == schema.Value_Which_float32, "expected float32 value")
 		fmt.Fprintf(w, "%s.Float32frombits(0x%x)", g_imports.math(), math.Float32bits(v.Float32()))
 
-	case Type_Which_float64:
-		assert(v.Which() == Value_Which_float64, "expected float64 value")
+	case schema.Type_Which_float64:
+		assert(v.Which() == schema.Value_Which_float64, "expected float64 value")
 		fmt.Fprintf(w, "%s.Float64frombits(0x%x)", g_imports.math(), math.Float64bits(v.Float64()))
 
-	case Type_Which_text:
-		assert(v.Which() == Value_Which_text, "expected text value")
+	case schema.Type_Which_text:
+		assert(v.Which() == schema.Value_Which_text, "expected text value")
 		text, _ := v.Text()
 		fmt.Fprintf(w, "%q", text)
 
-	case Type_Which_data:
-		assert(v.Which() == Value_Which_data, "expected data value")
+	case schema.Type_Which_data:
+		assert(v.Which() == schema.Value_Which_data, "expected data value")
 		fmt.Fprint(w, "[]byte{")
 		data, _ := v.Data()
 		for i, b := range data {
@@ -457,10 +516,10 @@ func (n *node) writeValue(w io.Writer, t Type, v Value) {
 		}
 		fmt.Fprint(w, "}")
 
-	case Type_Which_enum:
-		assert(v.Which() == Value_Which_enum, "expected enum value")
+	case schema.Type_Which_enum:
+		assert(v.Which() == schema.Value_Which_enum, "expected enum value")
 		en := findNode(t.Enum().TypeId())
-		assert(en.Which() == Node_Which_enum, "expected enum type ID")
+		assert(en.Which() == schema.Node_Which_enum, "expected enum type ID")
 		enums, _ := en.Enum().Enumerants()
 		if val := int(v.Enum()); val >= enums.Len() {
 			fmt.Fprintf(w, "%s(%d)", en.RemoteName(n), val)
@@ -469,19 +528,19 @@ func (n *node) writeValue(w io.Writer, t Type, v Value) {
 			fmt.Fprintf(w, "%s%s", en.remoteScope(n), ev.FullName())
 		}
 
-	case Type_Which_structGroup:
-		assert(v.Which() == Value_Which_structField, "expected struct value")
+	case schema.Type_Which_structGroup:
+		assert(v.Which() == schema.Value_Which_structField, "expected struct value")
 		c := g_imports.capnp()
 		data, _ := v.StructField()
 		fmt.Fprintf(w, "%s{Struct: %s.ToStruct(%s.MustUnmarshalRoot(%v))}", findNode(t.StructGroup().TypeId()).RemoteName(n), c, c, copyData(data))
 
-	case Type_Which_anyPointer:
-		assert(v.Which() == Value_Which_anyPointer, "expected pointer value")
+	case schema.Type_Which_anyPointer:
+		assert(v.Which() == schema.Value_Which_anyPointer, "expected pointer value")
 		data, _ := v.AnyPointer()
 		fmt.Fprintf(w, "%s.MustUnmarshalRoot(%v)", g_imports.capnp(), copyData(data))
 
-	case Type_Which_list:
-		assert(v.Which() == Value_Which_list, "expected list value")
+	case schema.Type_Which_list:
+		assert(v.Which() == schema.Value_Which_list, "expected list value")
 		c := g_imports.capnp()
 		typ := n.fieldType(t, new(annotations))
 		data, _ := v.List()
@@ -498,9 +557,9 @@ func (n *node) defineAnnotation(w io.Writer) {
 func constIsVar(n *node) bool {
 	t, _ := n.Const().Type()
 	switch t.Which() {
-	case Type_Which_bool, Type_Which_int8, Type_Which_uint8, Type_Which_int16,
-		Type_Which_uint16, Type_Which_int32, Type_Which_uint32, Type_Which_int64,
-		Type_Which_uint64, Type_Which_text, Type_Which_enum:
+	case schema.Type_Which_bool, schema.Type_Which_int8, schema.Type_Which_uint8, schema.Type_Which_int16,
+		schema.Type_Which_uint16, schema.Type_Which_int32, schema.Type_Which_uint32, schema.Type_Which_int64,
+		schema.Type_Which_uint64, schema.Type_Which_text, schema.Type_Which_enum:
 		return false
 	default:
 		return true
@@ -512,7 +571,7 @@ func defineConstNodes(w io.Writer, nodes []*node) {
 	any := false
 
 	for _, n := range nodes {
-		if n.Which() == Node_Which_const && !constIsVar(n) {
+		if n.Which() == schema.Node_Which_const && !constIsVar(n) {
 			if !any {
 				fmt.Fprintf(w, "const (\n")
 				any = true
@@ -532,7 +591,7 @@ func defineConstNodes(w io.Writer, nodes []*node) {
 	any = false
 
 	for _, n := range nodes {
-		if n.Which() == Node_Which_const && constIsVar(n) {
+		if n.Which() == schema.Node_Which_const && constIsVar(n) {
 			if !any {
 				fmt.Fprintf(w, "var (\n")
 				any = true
@@ -555,43 +614,51 @@ func (n *node) defineField(w io.Writer, f field) {
 	ann := parseAnnotations(fann)
 	t, _ := f.Slot().Type()
 	def, _ := f.Slot().DefaultValue()
+	bt := n.brand.resolve(t)
+	if t.Which() == schema.Type_Which_anyPointer && bt.Which() != schema.Type_Which_anyPointer {
+		// Parameters bound to a concrete type can only default to null.
+		def = schema.Value{}
+	}
+	t = bt.Type
 	params := structFieldParams{
 		Node:        n,
 		Field:       f,
 		Annotations: ann,
-		FieldType:   n.fieldType(t, ann),
+		FieldType:   n.typeName(bt),
 	}
 	switch t.Which() {
-	case Type_Which_void:
+	case schema.Type_Which_void:
 		templates.ExecuteTemplate(w, "structVoidField", params)
-	case Type_Which_bool:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_bool, "expected bool default")
+	case schema.Type_Which_bool:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_bool, "expected bool default")
 		templates.ExecuteTemplate(w, "structBoolField", structBoolFieldParams{
 			structFieldParams: params,
-			Default:           def.Which() == Value_Which_bool && def.Bool(),
+			Default:           def.Which() == schema.Value_Which_bool && def.Bool(),
 		})
 
-	case Type_Which_uint8, Type_Which_uint16, Type_Which_uint32, Type_Which_uint64:
+	case schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
 		templates.ExecuteTemplate(w, "structUintField", structUintFieldParams{
 			structFieldParams: params,
 			Bits:              intbits(t.Which()),
 			Default:           uintFieldDefault(t, def),
 		})
 
-	case Type_Which_int8, Type_Which_int16, Type_Which_int32, Type_Which_int64:
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64:
+		// The default is XORed with the field's bits, so a negative
+		// default is truncated to the field's width.
 		templates.ExecuteTemplate(w, "structIntField", structIntFieldParams{
 			structUintFieldParams: structUintFieldParams{
 				structFieldParams: params,
 				Bits:              intbits(t.Which()),
-				Default:           uint64(intFieldDefault(t, def)),
+				Default:           uint64(intFieldDefault(t, def)) & (1<<uint(intbits(t.Which())) - 1),
 			},
 		})
 
-	case Type_Which_enum:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_enum, "expected enum default")
+	case schema.Type_Which_enum:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_enum, "expected enum default")
 		ni := findNode(t.Enum().TypeId())
 		var d uint64
-		if def.Which() == Value_Which_enum {
+		if def.Which() == schema.Value_Which_enum {
 			d = uint64(def.Enum())
 		}
 		templates.ExecuteTemplate(w, "structIntField", structIntFieldParams{
@@ -602,10 +669,10 @@ func (n *node) defineField(w io.Writer, f field) {
 			},
 			EnumName: ni.RemoteName(n),
 		})
-	case Type_Which_float32:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_float32, "expected float32 default")
+	case schema.Type_Which_float32:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_float32, "expected float32 default")
 		var d uint64
-		if def.Which() == Value_Which_float32 && def.Float32() != 0 {
+		if def.Which() == schema.Value_Which_float32 && def.Float32() != 0 {
 			d = uint64(math.Float32bits(def.Float32()))
 		}
 		templates.ExecuteTemplate(w, "structFloatField", structUintFieldParams{
@@ -614,10 +681,10 @@ func (n *node) defineField(w io.Writer, f field) {
 			Default:           d,
 		})
 
-	case Type_Which_float64:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_float64, "expected float64 default")
+	case schema.Type_Which_float64:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_float64, "expected float64 default")
 		var d uint64
-		if def.Which() == Value_Which_float64 && def.Float64() != 0 {
+		if def.Which() == schema.Value_Which_float64 && def.Float64() != 0 {
 			d = math.Float64bits(def.Float64())
 		}
 		templates.ExecuteTemplate(w, "structFloatField", structUintFieldParams{
@@ -626,10 +693,10 @@ func (n *node) defineField(w io.Writer, f field) {
 			Default:           d,
 		})
 
-	case Type_Which_text:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_text, "expected text default")
+	case schema.Type_Which_text:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_text, "expected text default")
 		var d string
-		if def.Which() == Value_Which_text {
+		if def.Which() == schema.Value_Which_text {
 			d, _ = def.Text()
 		}
 		templates.ExecuteTemplate(w, "structTextField", structTextFieldParams{
@@ -637,10 +704,10 @@ func (n *node) defineField(w io.Writer, f field) {
 			Default:           d,
 		})
 
-	case Type_Which_data:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_data, "expected data default")
+	case schema.Type_Which_data:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_data, "expected data default")
 		var d []byte
-		if def.Which() == Value_Which_data {
+		if def.Which() == schema.Value_Which_data {
 			d, _ = def.Data()
 		}
 		templates.ExecuteTemplate(w, "structDataField", structDataFieldParams{
@@ -648,24 +715,24 @@ func (n *node) defineField(w io.Writer, f field) {
 			Default:           d,
 		})
 
-	case Type_Which_structGroup:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_structField, "expected struct default")
+	case schema.Type_Which_structGroup:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_structField, "expected struct default")
 		var defref staticDataRef
-		if def.Which() == Value_Which_structField {
+		if def.Which() == schema.Value_Which_structField {
 			if sf, _ := def.StructField(); capnp.HasData(sf) {
 				defref = copyData(sf)
 			}
 		}
 		templates.ExecuteTemplate(w, "structStructField", structObjectFieldParams{
 			structFieldParams: params,
-			TypeNode:          findNode(t.StructGroup().TypeId()),
+			TypeNode:          findNode(t.StructGroup().TypeId()).specialize(bt.brand, n),
 			Default:           defref,
 		})
 
-	case Type_Which_anyPointer:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_anyPointer, "expected object default")
+	case schema.Type_Which_anyPointer:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_anyPointer, "expected object default")
 		var defref staticDataRef
-		if def.Which() == Value_Which_anyPointer {
+		if def.Which() == schema.Value_Which_anyPointer {
 			if p, _ := def.AnyPointer(); capnp.HasData(p) {
 				defref = copyData(p)
 			}
@@ -675,10 +742,10 @@ func (n *node) defineField(w io.Writer, f field) {
 			Default:           defref,
 		})
 
-	case Type_Which_list:
-		assert(def.Which() == Value_Which_void || def.Which() == Value_Which_list, "expected list default")
+	case schema.Type_Which_list:
+		assert(def.Which() == schema.Value_Which_void || def.Which() == schema.Value_Which_list, "expected list default")
 		var defref staticDataRef
-		if def.Which() == Value_Which_list {
+		if def.Which() == schema.Value_Which_list {
 			if l, _ := def.List(); capnp.HasData(l) {
 				defref = copyData(l)
 			}
@@ -688,152 +755,157 @@ func (n *node) defineField(w io.Writer, f field) {
 			Default:           defref,
 		})
 
-	case Type_Which_interface:
+	case schema.Type_Which_interface:
 		templates.ExecuteTemplate(w, "structInterfaceField", params)
 	}
 }
 
-func (n *node) fieldType(t Type, ann *annotations) string {
+func (n *node) fieldType(t schema.Type, ann *annotations) string {
+	return n.typeName(n.brand.resolve(t))
+}
+
+// typeName returns the Go type used for t in n.
+func (n *node) typeName(t *boundType) string {
 	switch t.Which() {
-	case Type_Which_bool:
+	case schema.Type_Which_bool:
 		return "bool"
-	case Type_Which_int8:
+	case schema.Type_Which_int8:
 		return "int8"
-	case Type_Which_int16:
+	case schema.Type_Which_int16:
 		return "int16"
-	case Type_Which_int32:
+	case schema.Type_Which_int32:
 		return "int32"
-	case Type_Which_int64:
+	case schema.Type_Which_int64:
 		return "int64"
-	case Type_Which_uint8:
+	case schema.Type_Which_uint8:
 		return "uint8"
-	case Type_Which_uint16:
+	case schema.Type_Which_uint16:
 		return "uint16"
-	case Type_Which_uint32:
+	case schema.Type_Which_uint32:
 		return "uint32"
-	case Type_Which_uint64:
+	case schema.Type_Which_uint64:
 		return "uint64"
-	case Type_Which_float32:
+	case schema.Type_Which_float32:
 		return "float32"
-	case Type_Which_float64:
+	case schema.Type_Which_float64:
 		return "float64"
-	case Type_Which_text:
+	case schema.Type_Which_text:
 		return "string"
-	case Type_Which_data:
+	case schema.Type_Which_data:
 		return "[]byte"
-	case Type_Which_enum:
+	case schema.Type_Which_enum:
 		ni := findNode(t.Enum().TypeId())
 		return ni.RemoteName(n)
-	case Type_Which_structGroup:
-		ni := findNode(t.StructGroup().TypeId())
+	case schema.Type_Which_structGroup:
+		ni := findNode(t.StructGroup().TypeId()).specialize(t.brand, n)
 		return ni.RemoteName(n)
-	case Type_Which_interface:
-		ni := findNode(t.Interface().TypeId())
+	case schema.Type_Which_interface:
+		ni := findNode(t.Interface().TypeId()).specialize(t.brand, n)
 		return ni.RemoteName(n)
-	case Type_Which_anyPointer:
+	case schema.Type_Which_anyPointer:
 		return g_imports.capnp() + ".Pointer"
-	case Type_Which_list:
-		switch lt, _ := t.List().ElementType(); lt.Which() {
-		case Type_Which_void:
+	case schema.Type_Which_list:
+		switch lt := t.elem; lt.Which() {
+		case schema.Type_Which_void:
 			return g_imports.capnp() + ".VoidList"
-		case Type_Which_bool:
+		case schema.Type_Which_bool:
 			return g_imports.capnp() + ".BitList"
-		case Type_Which_int8:
+		case schema.Type_Which_int8:
 			return g_imports.capnp() + ".Int8List"
-		case Type_Which_uint8:
+		case schema.Type_Which_uint8:
 			return g_imports.capnp() + ".UInt8List"
-		case Type_Which_int16:
+		case schema.Type_Which_int16:
 			return g_imports.capnp() + ".Int16List"
-		case Type_Which_uint16:
+		case schema.Type_Which_uint16:
 			return g_imports.capnp() + ".UInt16List"
-		case Type_Which_int32:
+		case schema.Type_Which_int32:
 			return g_imports.capnp() + ".Int32List"
-		case Type_Which_uint32:
+		case schema.Type_Which_uint32:
 			return g_imports.capnp() + ".UInt32List"
-		case Type_Which_int64:
+		case schema.Type_Which_int64:
 			return g_imports.capnp() + ".Int64List"
-		case Type_Which_uint64:
+		case schema.Type_Which_uint64:
 			return g_imports.capnp() + ".UInt64List"
-		case Type_Which_float32:
+		case schema.Type_Which_float32:
 			return g_imports.capnp() + ".Float32List"
-		case Type_Which_float64:
+		case schema.Type_Which_float64:
 			return g_imports.capnp() + ".Float64List"
-		case Type_Which_text:
+		case schema.Type_Which_text:
 			return g_imports.capnp() + ".TextList"
-		case Type_Which_data:
+		case schema.Type_Which_data:
 			return g_imports.capnp() + ".DataList"
-		case Type_Which_enum:
+		case schema.Type_Which_enum:
 			ni := findNode(lt.Enum().TypeId())
 			return ni.RemoteName(n) + "_List"
-		case Type_Which_structGroup:
-			ni := findNode(lt.StructGroup().TypeId())
+		case schema.Type_Which_structGroup:
+			ni := findNode(lt.StructGroup().TypeId()).specialize(lt.brand, n)
 			return ni.RemoteName(n) + "_List"
-		case Type_Which_anyPointer, Type_Which_list, Type_Which_interface:
+		case schema.Type_Which_anyPointer, schema.Type_Which_list, schema.Type_Which_interface:
 			return g_imports.capnp() + ".PointerList"
 		}
 	}
 	return ""
 }
 
-func intFieldDefault(t Type, def Value) int64 {
-	if def.Which() == Value_Which_void {
+func intFieldDefault(t schema.Type, def schema.Value) int64 {
+	if def.Which() == schema.Value_Which_void {
 		return 0
 	}
 	return intValue(t, def)
 }
 
-func intValue(t Type, v Value) int64 {
+func intValue(t schema.Type, v schema.Value) int64 {
 	switch t.Which() {
-	case Type_Which_int8:
-		assert(v.Which() == Value_Which_int8, "expected int8 value")
+	case schema.Type_Which_int8:
+		assert(v.Which() == schema.Value_Which_int8, "expected int8 value")
 		return int64(v.Int8())
-	case Type_Which_int16:
-		assert(v.Which() == Value_Which_int16, "expected int16 value")
+	case schema.Type_Which_int16:
+		assert(v.Which() == schema.Value_Which_int16, "expected int16 value")
 		return int64(v.Int16())
-	case Type_Which_int32:
-		assert(v.Which() == Value_Which_int32, "expected int32 value")
+	case schema.Type_Which_int32:
+		assert(v.Which() == schema.Value_Which_int32, "expected int32 value")
 		return int64(v.Int32())
-	case Type_Which_int64:
-		assert(v.Which() == Value_Which_int64, "expected int64 value")
+	case schema.Type_Which_int64:
+		assert(v.Which() == schema.Value_Which_int64, "expected int64 value")
 		return v.Int64()
 	}
 	panic("unreachable")
 }
 
-func uintFieldDefault(t Type, def Value) uint64 {
-	if def.Which() == Value_Which_void {
+func uintFieldDefault(t schema.Type, def schema.Value) uint64 {
+	if def.Which() == schema.Value_Which_void {
 		return 0
 	}
 	return uintValue(t, def)
 }
 
-func uintValue(t Type, v Value) uint64 {
+func uintValue(t schema.Type, v schema.Value) uint64 {
 	switch t.Which() {
-	case Type_Which_uint8:
-		assert(v.Which() == Value_Which_uint8, "expected uint8 value")
+	case schema.Type_Which_uint8:
+		assert(v.Which() == schema.Value_Which_uint8, "expected uint8 value")
 		return uint64(v.Uint8())
-	case Type_Which_uint16:
-		assert(v.Which() == Value_Which_uint16, "expected uint16 value")
+	case schema.Type_Which_uint16:
+		assert(v.Which() == schema.Value_Which_uint16, "expected uint16 value")
 		return uint64(v.Uint16())
-	case Type_Which_uint32:
-		assert(v.Which() == Value_Which_uint32, "expected uint32 value")
+	case schema.Type_Which_uint32:
+		assert(v.Which() == schema.Value_Which_uint32, "expected uint32 value")
 		return uint64(v.Uint32())
-	case Type_Which_uint64:
-		assert(v.Which() == Value_Which_uint64, "expected uint64 value")
+	case schema.Type_Which_uint64:
+		assert(v.Which() == schema.Value_Which_uint64, "expected uint64 value")
 		return v.Uint64()
 	}
 	panic("unreachable")
 }
 
-func intbits(t Type_Which) int {
+func intbits(t schema.Type_Which) int {
 	switch t {
-	case Type_Which_uint8, Type_Which_int8:
+	case schema.Type_Which_uint8, schema.Type_Which_int8:
 		return 8
-	case Type_Which_uint16, Type_Which_int16:
+	case schema.Type_Which_uint16, schema.Type_Which_int16:
 		return 16
-	case Type_Which_uint32, Type_Which_int32:
+	case schema.Type_Which_uint32, schema.Type_Which_int32:
 		return 32
-	case Type_Which_uint64, Type_Which_int64:
+	case schema.Type_Which_uint64, schema.Type_Which_int64:
 		return 64
 	}
 	return 0
@@ -854,30 +926,28 @@ func (n *node) codeOrderFields() []field {
 }
 
 func (n *node) defineStructTypes(w io.Writer, baseNode *node) {
-	assert(n.Which() == Node_Which_structGroup, "invalid struct node")
+	assert(n.Which() == schema.Node_Which_structGroup, "invalid struct node")
 
-	nann, _ := n.Annotations()
-	ann := parseAnnotations(nann)
 	templates.ExecuteTemplate(w, "structTypes", structTypesParams{
 		Node:        n,
-		Annotations: ann,
+		Annotations: n.annotations(),
 		BaseNode:    baseNode,
 	})
 
 	for _, f := range n.codeOrderFields() {
-		if f.Which() == Field_Which_group {
-			findNode(f.Group().TypeId()).defineStructTypes(w, baseNode)
+		if f.Which() == schema.Field_Which_group {
+			n.groupNode(f).defineStructTypes(w, baseNode)
 		}
 	}
 }
 
 func (n *node) defineStructEnums(w io.Writer) {
-	assert(n.Which() == Node_Which_structGroup, "invalid struct node")
+	assert(n.Which() == schema.Node_Which_structGroup, "invalid struct node")
 	fields := n.codeOrderFields()
 	members := make([]field, 0, len(fields))
 	es := make(enumString, 0, len(fields))
 	for _, f := range fields {
-		if f.DiscriminantValue() != Field_noDiscriminant {
+		if f.DiscriminantValue() != schema.Field_noDiscriminant {
 			members = append(members, f)
 			es = append(es, f.Name)
 		}
@@ -890,14 +960,14 @@ func (n *node) defineStructEnums(w io.Writer) {
 		})
 	}
 	for _, f := range fields {
-		if f.Which() == Field_Which_group {
-			findNode(f.Group().TypeId()).defineStructEnums(w)
+		if f.Which() == schema.Field_Which_group {
+			n.groupNode(f).defineStructEnums(w)
 		}
 	}
 }
 
 func (n *node) defineStructFuncs(w io.Writer) {
-	assert(n.Which() == Node_Which_structGroup, "invalid struct node")
+	assert(n.Which() == schema.Node_Which_structGroup, "invalid struct node")
 
 	templates.ExecuteTemplate(w, "structFuncs", structFuncsParams{
 		Node: n,
@@ -905,10 +975,10 @@ func (n *node) defineStructFuncs(w io.Writer) {
 
 	for _, f := range n.codeOrderFields() {
 		switch f.Which() {
-		case Field_Which_slot:
+		case schema.Field_Which_slot:
 			n.defineField(w, f)
-		case Field_Which_group:
-			g := findNode(f.Group().TypeId())
+		case schema.Field_Which_group:
+			g := n.groupNode(f)
 			templates.ExecuteTemplate(w, "structGroup", structGroupParams{
 				Node:  n,
 				Group: g,
@@ -920,12 +990,12 @@ func (n *node) defineStructFuncs(w io.Writer) {
 }
 
 func (n *node) ObjectSize() string {
-	assert(n.Which() == Node_Which_structGroup, "ObjectSize for invalid struct node")
+	assert(n.Which() == schema.Node_Which_structGroup, "ObjectSize for invalid struct node")
 	return fmt.Sprintf("%s.ObjectSize{DataSize: %d, PointerCount: %d}", g_imports.capnp(), int(n.StructGroup().DataWordCount())*8, n.StructGroup().PointerCount())
 }
 
 func (n *node) defineNewStructFunc(w io.Writer) {
-	assert(n.Which() == Node_Which_structGroup, "invalid struct node")
+	assert(n.Which() == schema.Node_Which_structGroup, "invalid struct node")
 
 	templates.ExecuteTemplate(w, "newStructFunc", newStructParams{
 		Node: n,
@@ -933,7 +1003,7 @@ func (n *node) defineNewStructFunc(w io.Writer) {
 }
 
 func (n *node) defineStructList(w io.Writer) {
-	assert(n.Which() == Node_Which_structGroup, "invalid struct node")
+	assert(n.Which() == schema.Node_Which_structGroup, "invalid struct node")
 
 	templates.ExecuteTemplate(w, "structList", structListParams{
 		Node: n,
@@ -948,13 +1018,13 @@ func (n *node) defineStructPromise(w io.Writer) {
 
 	for _, f := range n.codeOrderFields() {
 		switch f.Which() {
-		case Field_Which_slot:
+		case schema.Field_Which_slot:
 			t, _ := f.Slot().Type()
-			if tw := t.Which(); tw == Type_Which_structGroup || tw == Type_Which_interface || tw == Type_Which_anyPointer {
+			if tw := n.brand.resolve(t).Which(); tw == schema.Type_Which_structGroup || tw == schema.Type_Which_interface || tw == schema.Type_Which_anyPointer {
 				n.definePromiseField(w, f)
 			}
-		case Field_Which_group:
-			g := findNode(f.Group().TypeId())
+		case schema.Field_Which_group:
+			g := n.groupNode(f)
 			templates.ExecuteTemplate(w, "promiseGroup", promiseGroupTemplateParams{
 				Node:  n,
 				Field: f,
@@ -967,36 +1037,37 @@ func (n *node) defineStructPromise(w io.Writer) {
 
 func (n *node) definePromiseField(w io.Writer, f field) {
 	slot := f.Slot()
-	switch t, _ := slot.Type(); t.Which() {
-	case Type_Which_structGroup:
-		ni := findNode(t.StructGroup().TypeId())
+	st, _ := slot.Type()
+	switch t := n.brand.resolve(st); t.Which() {
+	case schema.Type_Which_structGroup:
+		ni := findNode(t.StructGroup().TypeId()).specialize(t.brand, n)
 		params := promiseFieldStructTemplateParams{
 			Node:   n,
 			Field:  f,
 			Struct: ni,
 		}
-		if def, _ := slot.DefaultValue(); def.Which() == Value_Which_structField {
+		if def, _ := slot.DefaultValue(); def.Which() == schema.Value_Which_structField {
 			if sf, _ := def.StructField(); capnp.HasData(sf) {
 				params.Default = copyData(sf)
 			}
 		}
 		templates.ExecuteTemplate(w, "promiseFieldStruct", params)
-	case Type_Which_anyPointer:
+	case schema.Type_Which_anyPointer:
 		templates.ExecuteTemplate(w, "promiseFieldAnyPointer", promiseFieldAnyPointerTemplateParams{
 			Node:  n,
 			Field: f,
 		})
-	case Type_Which_interface:
+	case schema.Type_Which_interface:
 		templates.ExecuteTemplate(w, "promiseFieldInterface", promiseFieldInterfaceTemplateParams{
 			Node:      n,
 			Field:     f,
-			Interface: findNode(t.Interface().TypeId()),
+			Interface: findNode(t.Interface().TypeId()).specialize(t.brand, n),
 		})
 	}
 }
 
 type interfaceMethod struct {
-	Method
+	schema.Method
 	Interface    *node
 	ID           int
 	Name         string
@@ -1011,14 +1082,16 @@ func (n *node) methodSet(methods []interfaceMethod) []interfaceMethod {
 		m := ms.At(i)
 		mname, _ := m.Name()
 		mann, _ := m.Annotations()
+		pb, _ := m.ParamBrand()
+		rb, _ := m.ResultBrand()
 		methods = append(methods, interfaceMethod{
 			Method:       m,
 			Interface:    n,
 			ID:           i,
 			OriginalName: mname,
 			Name:         parseAnnotations(mann).Rename(mname),
-			Params:       findNode(m.ParamStructType()),
-			Results:      findNode(m.ResultStructType()),
+			Params:       findNode(m.ParamStructType()).specialize(n.brand.bind(pb), n),
+			Results:      findNode(m.ResultStructType()).specialize(n.brand.bind(rb), n),
 		})
 	}
 	// TODO(light): sort added methods by code order
@@ -1026,31 +1099,51 @@ func (n *node) methodSet(methods []interfaceMethod) []interfaceMethod {
 	supers, _ := n.Interface().Superclasses()
 	for i := 0; i < supers.Len(); i++ {
 		s := supers.At(i)
-		methods = findNode(s.Id()).methodSet(methods)
+		sb, _ := s.Brand()
+		methods = findNode(s.Id()).specialize(n.brand.bind(sb), n).methodSet(methods)
 	}
 	return methods
 }
 
 func (n *node) defineInterfaceClient(w io.Writer) {
 	m := n.methodSet(nil)
-	nann, _ := n.Annotations()
 	templates.ExecuteTemplate(w, "interfaceClient", interfaceClientTemplateParams{
 		Node:        n,
-		Annotations: parseAnnotations(nann),
+		Annotations: n.annotations(),
 		Methods:     m,
 	})
 }
 
 func (n *node) defineInterfaceServer(w io.Writer) {
 	m := n.methodSet(nil)
-	nann, _ := n.Annotations()
 	templates.ExecuteTemplate(w, "interfaceServer", interfaceServerTemplateParams{
 		Node:        n,
-		Annotations: parseAnnotations(nann),
+		Annotations: n.annotations(),
 		Methods:     m,
 	})
 }
 
+func (n *node) defineNode(w io.Writer) {
+	switch n.Which() {
+	case schema.Node_Which_enum:
+		n.defineEnum(w)
+	case schema.Node_Which_structGroup:
+		if !n.StructGroup().IsGroup() {
+			n.defineStructTypes(w, n)
+			n.defineStructEnums(w)
+			n.defineNewStructFunc(w)
+			n.defineStructFuncs(w)
+			n.defineStructList(w)
+			if *genPromises {
+				n.defineStructPromise(w)
+			}
+		}
+	case schema.Node_Which_interface:
+		n.defineInterfaceClient(w)
+		n.defineInterfaceServer(w)
+	}
+}
+
 type enumString []string
 
 func (es enumString) ValueString() string {
@@ -1065,7 +1158,7 @@ func (es enumString) SliceFor(i int) string {
 	return fmt.Sprintf("[%d:%d]", n, n+len(es[i]))
 }
 
-func generateFile(reqf CodeGeneratorRequest_RequestedFile) (generr error) {
+func generateFile(reqf schema.CodeGeneratorRequest_RequestedFile) (generr error) {
 	defer func() {
 		e := recover()
 		if ae, ok := e.(assertionError); ok {
@@ -1080,9 +1173,10 @@ func generateFile(reqf CodeGeneratorRequest_RequestedFile) (generr error) {
 	g_imports.init()
 	g_segment = make([]byte, 0, 4096)
 	g_bufname = fmt.Sprintf("x_%x", f.Id())
+	g_specQueue = nil
 
 	for _, n := range f.nodes {
-		if n.Which() == Node_Which_annotation {
+		if n.Which() == schema.Node_Which_annotation {
 			n.defineAnnotation(&buf)
 		}
 	}
@@ -1090,24 +1184,7 @@ func generateFile(reqf CodeGeneratorRequest_RequestedFile) (generr error) {
 	defineConstNodes(&buf, f.nodes)
 
 	for _, n := range f.nodes {
-		switch n.Which() {
-		case Node_Which_enum:
-			n.defineEnum(&buf)
-		case Node_Which_structGroup:
-			if !n.StructGroup().IsGroup() {
-				n.defineStructTypes(&buf, n)
-				n.defineStructEnums(&buf)
-				n.defineNewStructFunc(&buf)
-				n.defineStructFuncs(&buf)
-				n.defineStructList(&buf)
-				if *genPromises {
-					n.defineStructPromise(&buf)
-				}
-			}
-		case Node_Which_interface:
-			n.defineInterfaceClient(&buf)
-			n.defineInterfaceServer(&buf)
-		}
+		n.defineNode(&buf)
 	}
 
 	fname, _ := reqf.Filename()
@@ -1115,22 +1192,52 @@ func generateFile(reqf CodeGeneratorRequest_RequestedFile) (generr error) {
 		return fmt.Errorf("missing package annotation for %s", fname)
 	}
 
-	if dirPath, _ := filepath.Split(fname); dirPath != "" {
+	if *genSchemas {
+		if err := defineSchemaVar(&buf, f); err != nil {
+			return fmt.Errorf("embedding schema for %s: %v", fname, err)
+		}
+	}
+
+	dirPath, _ := filepath.Split(fname)
+	if dirPath != "" {
 		err := os.MkdirAll(dirPath, os.ModePerm)
 		if err != nil {
 			return err
 		}
 	}
+	if err := writeGoFile(fname+".go", f.pkg, buf.Bytes()); err != nil {
+		return err
+	}
+
+	// Defining a specialization may use further specializations, which
+	// are appended to the queue.
+	for i := 0; i < len(g_specQueue); i++ {
+		s := g_specQueue[i]
+		g_imports.init()
+		g_segment = make([]byte, 0, 4096)
+		g_bufname = "x_" + s.Name
+		var sbuf bytes.Buffer
+		s.defineNode(&sbuf)
+		if err := writeGoFile(filepath.Join(dirPath, s.Name+".capnp.go"), s.pkg, sbuf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// writeGoFile formats the declarations in body as a file of package pkg
+// and writes it to fname, along with the imports in g_imports and the
+// data in g_segment that body uses.
+func writeGoFile(fname, pkg string, body []byte) error {
 	var unformatted bytes.Buffer
-	fmt.Fprintf(&unformatted, "package %s\n\n", f.pkg)
+	fmt.Fprintf(&unformatted, "package %s\n\n", pkg)
 	fmt.Fprintf(&unformatted, "// AUTO GENERATED - DO NOT EDIT\n\n")
 	fmt.Fprintf(&unformatted, "import (\n")
 	for _, imp := range g_imports.usedImports() {
 		fmt.Fprintf(&unformatted, "%v\n", imp)
 	}
 	fmt.Fprintf(&unformatted, ")\n")
-	unformatted.Write(buf.Bytes())
+	unformatted.Write(body)
 	if len(g_segment) > 0 {
 		fmt.Fprintf(&unformatted, "var %s = []byte{", g_bufname)
 		for i, b := range g_segment {
@@ -1147,7 +1254,7 @@ func generateFile(reqf CodeGeneratorRequest_RequestedFile) (generr error) {
 		formatted = unformatted.Bytes()
 	}
 
-	file, err := os.Create(fname + ".go")
+	file, err := os.Create(fname)
 	if err != nil {
 		return err
 	}
@@ -1162,6 +1269,77 @@ func generateFile(reqf CodeGeneratorRequest_RequestedFile) (generr error) {
 	return nil
 }
 
+// defineSchemaVar writes a constant holding the compressed schema nodes
+// of the file f and an init function that registers them.
+func defineSchemaVar(w io.Writer, f *node) error {
+	nodes := append([]*node{f}, f.schemaNodes...)
+	ids := make([]uint64, len(nodes))
+	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		return err
+	}
+	req, err := schema.NewRootCodeGeneratorRequest(seg)
+	if err != nil {
+		return err
+	}
+	list, err := schema.NewNode_List(seg, int32(len(nodes)))
+	if err != nil {
+		return err
+	}
+	if err := req.SetNodes(list); err != nil {
+		return err
+	}
+	for i, n := range nodes {
+		if err := list.Set(i, n.Node); err != nil {
+			return err
+		}
+		ids[i] = n.Id()
+	}
+	data, err := msg.Marshal()
+	if err != nil {
+		return err
+	}
+	var zbuf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&zbuf, zlib.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("schema_%x", f.Id())
+	fmt.Fprintf(w, "const %s = ", name)
+	z := zbuf.Bytes()
+	for len(z) > 0 {
+		n := 64
+		if n > len(z) {
+			n = len(z)
+		}
+		fmt.Fprintf(w, "%q", z[:n])
+		z = z[n:]
+		if len(z) > 0 {
+			fmt.Fprintf(w, " +\n")
+		}
+	}
+	fmt.Fprintf(w, "\n\nfunc init() {\n%s.RegisterCompressed(%s", g_imports.schemas(), name)
+	sort.Sort(uint64Slice(ids))
+	for _, id := range ids {
+		fmt.Fprintf(w, ",\n%#x", id)
+	}
+	fmt.Fprintf(w, ")\n}\n")
+	return nil
+}
+
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int           { return len(s) }
+func (s uint64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
 func main() {
 	flag.Parse()
 
@@ -1171,12 +1349,22 @@ func main() {
 		os.Exit(1)
 	}
 
-	req, err := ReadRootCodeGeneratorRequest(msg)
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "capnpc-go: Reading input:", err)
 		os.Exit(1)
 	}
+	if !generate(req) {
+		os.Exit(1)
+	}
+}
+
+// generate writes the Go files for the files requested in req to the
+// current directory.  Errors are printed to stderr, and generate
+// reports whether all of the files were written.
+func generate(req schema.CodeGeneratorRequest) bool {
 	allfiles := []*node{}
+	g_specs = make(map[specKey]*node)
 
 	nodes, _ := req.Nodes()
 	for i := 0; i < nodes.Len(); i++ {
@@ -1184,7 +1372,7 @@ func main() {
 		n := &node{Node: ni}
 		g_nodes[n.Id()] = n
 
-		if n.Which() == Node_Which_file {
+		if n.Which() == schema.Node_Which_file {
 			allfiles = append(allfiles, n)
 		}
 	}
@@ -1215,7 +1403,5 @@ func main() {
 			success = false
 		}
 	}
-	if !success {
-		os.Exit(1)
-	}
+	return success
 }
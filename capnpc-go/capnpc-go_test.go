@@ -0,0 +1,146 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// readTestRequest reads a CodeGeneratorRequest from testdata.  The
+// requests are made with capnp compile -o-, for example:
+//
+//	capnp compile -o- map.capnp people.capnp places.capnp > generics.capnp.out
+//	capnp compile -o- people.capnp > people.capnp.out
+func readTestRequest(t *testing.T, name string) schema.CodeGeneratorRequest {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := capnp.Unmarshal(data)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
+	if err != nil {
+		t.Fatal("ReadRootCodeGeneratorRequest:", err)
+	}
+	return req
+}
+
+// generateIn runs the generator on req in dir.
+func generateIn(t *testing.T, dir string, req schema.CodeGeneratorRequest) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if !generate(req) {
+		t.Fatal("generate failed")
+	}
+}
+
+func TestSpecializationsSharedInPackage(t *testing.T) {
+	// map.capnp declares the generic Map, and people.capnp and
+	// places.capnp, which are in the same Go package, both use
+	// Map(Text, Text).
+	dir, err := ioutil.TempDir("", "capnpc-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	generateIn(t, dir, readTestRequest(t, "generics.capnp.out"))
+	checkDecls(t, dir, "Map", "Map_Text_Text", "Map_Entry_Text_Text", "Person", "Place")
+}
+
+func TestSpecializationsGeneratedSeparately(t *testing.T) {
+	// Map(Text, Text) must be declared once even if each file that uses
+	// it is generated on its own.
+	dir, err := ioutil.TempDir("", "capnpc-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	generateIn(t, dir, readTestRequest(t, "people.capnp.out"))
+	generateIn(t, dir, readTestRequest(t, "places.capnp.out"))
+	checkDecls(t, dir, "Map_Text_Text", "Map_Entry_Text_Text", "Person", "Place")
+}
+
+// checkDecls parses the Go files in dir, reporting names that are
+// declared more than once and names in want that aren't declared.
+// Specializations must be declared in files named after them.
+func checkDecls(t *testing.T, dir string, want ...string) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	declared := make(map[string]string) // name -> file
+	for _, name := range names {
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name = filepath.Base(name)
+		for _, d := range f.Decls {
+			for _, id := range declNames(d) {
+				if prev, ok := declared[id]; ok {
+					t.Errorf("%s declared in both %s and %s", id, prev, name)
+				}
+				declared[id] = name
+			}
+		}
+	}
+	for _, id := range want {
+		if _, ok := declared[id]; !ok {
+			t.Errorf("%s not declared", id)
+		}
+	}
+	if f := declared["Map_Text_Text"]; f != "Map_Text_Text.capnp.go" {
+		t.Errorf("Map_Text_Text declared in %s; want Map_Text_Text.capnp.go", f)
+	}
+}
+
+// declNames returns the package-level names that d declares.  Methods
+// are named by their receiver type and method name.
+func declNames(d ast.Decl) []string {
+	var names []string
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Name.Name == "init" {
+			break
+		}
+		name := d.Name.Name
+		if d.Recv != nil {
+			typ := d.Recv.List[0].Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			name = typ.(*ast.Ident).Name + "." + name
+		}
+		names = append(names, name)
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, spec.Name.Name)
+			case *ast.ValueSpec:
+				for _, id := range spec.Names {
+					if id.Name != "_" {
+						names = append(names, id.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
@@ -2,8 +2,9 @@ package main
 
 import (
 	"fmt"
-	"strings"
 	"text/template"
+
+	"zombiezen.com/go/capnproto/schema"
 )
 
 var templates = template.Must(template.New("").Funcs(template.FuncMap{
@@ -12,9 +13,9 @@ var templates = template.Must(template.New("").Funcs(template.FuncMap{
 	"server":  g_imports.server,
 	"context": g_imports.context,
 	"strconv": g_imports.strconv,
-	"title":   strings.Title,
+	"title":   title,
 	"hasDiscriminant": func(f field) bool {
-		return f.DiscriminantValue() != Field_noDiscriminant
+		return f.DiscriminantValue() != schema.Field_noDiscriminant
 	},
 	"discriminantOffset": func(n *node) uint32 {
 		return n.StructGroup().DiscriminantOffset() * 2
@@ -161,7 +162,7 @@ func (s {{.Node.Name}}) {{.Field.Name|title}}() {{.ReturnType}} {
 
 func (s {{.Node.Name}}) Set{{.Field.Name|title}}(v {{.ReturnType}}) {
 	{{template "settag" .}}
-	s.Struct.SetUint{{.Bits}}({{.Offset}}, uint{{.Bits}}(v{{with .Default}}^{{.}}{{end}}))
+	s.Struct.SetUint{{.Bits}}({{.Offset}}, uint{{.Bits}}(v){{with .Default}}^{{.}}{{end}})
 }
 {{end}}
 
@@ -185,7 +186,7 @@ func (s {{.Node.Name}}) {{.Field.Name|title}}() (string, error) {
 		return "", err
 	}
 	{{with .Default}}
-	return {{capnp}}.ToTextDefault(p, {{printf "%q" .}})
+	return {{capnp}}.ToTextDefault(p, {{printf "%q" .}}), nil
 	{{else}}
 	return {{capnp}}.ToText(p), nil
 	{{end}}
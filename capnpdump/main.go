@@ -0,0 +1,77 @@
+//go:build !nocapnpstrings
+// +build !nocapnpstrings
+
+/*
+capnpdump prints the layout of Cap'n Proto messages word by word.  It
+reads a stream of messages from stdin, or from the files named on the
+command line, and prints each with the annotations from capnp.Dump.
+No schema is needed.
+
+Usage:
+
+	capnpdump [-packed] [FILE [...]]
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"zombiezen.com/go/capnproto"
+)
+
+var packed = flag.Bool("packed", false, "read packed messages")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		if err := dump(os.Stdin); err != nil {
+			fmt.Fprintln(os.Stderr, "capnpdump:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	failed := false
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "capnpdump:", err)
+			failed = true
+			continue
+		}
+		err = dump(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "capnpdump: %s: %v\n", name, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// dump prints every message in r.
+func dump(r io.Reader) error {
+	var dec *capnp.Decoder
+	if *packed {
+		dec = capnp.NewPackedDecoder(r)
+	} else {
+		dec = capnp.NewDecoder(r)
+	}
+	for i := 0; ; i++ {
+		msg, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("message %d\n", i)
+		if err := capnp.Dump(os.Stdout, msg); err != nil {
+			return err
+		}
+	}
+}
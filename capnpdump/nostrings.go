@@ -0,0 +1,16 @@
+//go:build nocapnpstrings
+// +build nocapnpstrings
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// capnp.Dump is left out by the nocapnpstrings tag, so there is nothing
+// to print messages with.
+func main() {
+	fmt.Fprintln(os.Stderr, "capnpdump: built with the nocapnpstrings tag, which leaves out capnp.Dump")
+	os.Exit(1)
+}
@@ -0,0 +1,199 @@
+//go:build !nocapnpstrings
+// +build !nocapnpstrings
+
+package capnp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Dump writes a listing of every word in msg's segments to w, one word
+// per line.  Pointers, list tags, and far pointer landing pads are
+// decoded and annotated with their targets.  Dump walks the message
+// from the root to find out what each word holds, so no schema is
+// needed.  Words that can't be reached from the root are marked with
+// an exclamation point.
+//
+// Dump is intended for debugging encoders and will list malformed
+// messages as far as it can.  Pointers that can't be followed are
+// annotated with the reason.
+func Dump(w io.Writer, msg *Message) error {
+	d := &dumper{
+		words:   make(map[SegmentID][]wordInfo),
+		visited: make(map[objectKey]bool),
+	}
+	nsegs := msg.NumSegments()
+	for i := int64(0); i < nsegs; i++ {
+		seg, err := msg.Segment(SegmentID(i))
+		if err != nil {
+			return err
+		}
+		d.words[seg.id] = make([]wordInfo, len(seg.data)/int(wordSize))
+	}
+	if nsegs > 0 {
+		seg, _ := msg.Segment(0)
+		if seg.regionInBounds(0, wordSize) {
+			d.pointer(seg, 0, msg.depthLimit())
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := int64(0); i < nsegs; i++ {
+		seg, _ := msg.Segment(SegmentID(i))
+		fmt.Fprintf(bw, "segment %d (%d words)\n", seg.id, len(seg.data)/int(wordSize))
+		for j, info := range d.words[seg.id] {
+			addr := Address(j) * Address(wordSize)
+			mark := ' '
+			if info.kind == unreachableWord {
+				mark = '!'
+			}
+			fmt.Fprintf(bw, "%c %08x: %016x", mark, uint32(addr), seg.readUint64(addr))
+			if i == 0 && j == 0 {
+				bw.WriteString("  root")
+			}
+			switch info.kind {
+			case unreachableWord:
+				bw.WriteString("  unreachable")
+			case pointerWord, landingPadWord, tagWord:
+				fmt.Fprintf(bw, "  %s%#v", info.kind.prefix(), seg.readRawPointer(addr))
+			}
+			if info.note != "" {
+				bw.WriteString("  " + info.note)
+			}
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// wordKind classifies a word found while walking a message.
+type wordKind uint8
+
+const (
+	unreachableWord wordKind = iota
+	dataWord
+	pointerWord
+	landingPadWord
+	tagWord
+)
+
+func (k wordKind) prefix() string {
+	switch k {
+	case landingPadWord:
+		return "landing pad "
+	case tagWord:
+		return "list tag "
+	default:
+		return ""
+	}
+}
+
+type wordInfo struct {
+	kind wordKind
+	note string
+}
+
+// A dumper classifies the words of a message by walking it from the root.
+type dumper struct {
+	words   map[SegmentID][]wordInfo
+	visited map[objectKey]bool
+}
+
+// mark sets the kind of the word containing addr, if it is in bounds.
+func (d *dumper) mark(s *Segment, addr Address, kind wordKind, note string) {
+	words := d.words[s.id]
+	i := int(addr / Address(wordSize))
+	if i >= len(words) {
+		return
+	}
+	words[i] = wordInfo{kind: kind, note: note}
+}
+
+// markRange marks the data words in [addr, addr+sz) that haven't
+// already been classified.
+func (d *dumper) markRange(s *Segment, addr Address, sz Size) {
+	words := d.words[s.id]
+	end := int64(addr) + int64(sz)
+	for a := int64(addr) &^ int64(wordSize-1); a < end; a += int64(wordSize) {
+		i := int(a / int64(wordSize))
+		if i >= len(words) {
+			return
+		}
+		if words[i].kind == unreachableWord {
+			words[i].kind = dataWord
+		}
+	}
+}
+
+// pointer classifies the pointer at off and, recursively, the object it
+// points to.
+func (d *dumper) pointer(s *Segment, off Address, depthLimit uint) {
+	val := s.readRawPointer(off)
+	d.mark(s, off, pointerWord, "")
+	if val == 0 {
+		return
+	}
+	ps, poff, pval, err := s.resolveFarPointer(off, val)
+	if err != nil {
+		d.mark(s, off, pointerWord, "error: "+err.Error())
+		return
+	}
+	switch val.pointerType() {
+	case farPointer:
+		d.mark(ps, poff, landingPadWord, "")
+	case doubleFarPointer:
+		faroff := val.farAddress()
+		pad, _ := s.lookupSegment(val.farSegment())
+		d.mark(pad, faroff, landingPadWord, "")
+		d.mark(pad, faroff.addSize(wordSize), tagWord, "")
+	}
+	if depthLimit == 0 {
+		d.mark(s, off, pointerWord, "error: "+ErrDepthLimit.Error())
+		return
+	}
+	p, err := ps.pointerValue(poff, pval, depthLimit-1)
+	if err != nil {
+		d.mark(s, off, pointerWord, "error: "+err.Error())
+		return
+	}
+	switch p := p.(type) {
+	case Struct:
+		d.mark(s, off, pointerWord, fmt.Sprintf("-> segment %d, %08x", p.seg.id, uint32(p.off)))
+		key := objectKey{p.seg.id, p.off}
+		if d.visited[key] {
+			return
+		}
+		d.visited[key] = true
+		d.structWords(p)
+	case List:
+		d.mark(s, off, pointerWord, fmt.Sprintf("-> segment %d, %08x", p.seg.id, uint32(p.off)))
+		key := objectKey{p.seg.id, p.off}
+		if d.visited[key] {
+			return
+		}
+		d.visited[key] = true
+		switch {
+		case p.flags&isCompositeList != 0:
+			d.mark(p.seg, p.off-Address(wordSize), tagWord, "")
+			for i := 0; i < p.Len(); i++ {
+				d.structWords(p.Struct(i))
+			}
+		case p.size.PointerCount > 0:
+			for i := 0; i < p.Len(); i++ {
+				addr, _ := p.primitiveElem(i, ObjectSize{PointerCount: 1})
+				d.pointer(p.seg, addr, p.depthLimit)
+			}
+		default:
+			d.markRange(p.seg, p.off, dataSize(p))
+		}
+	}
+}
+
+func (d *dumper) structWords(s Struct) {
+	d.markRange(s.seg, s.off, s.size.DataSize)
+	for i := uint16(0); i < s.size.PointerCount; i++ {
+		d.pointer(s.seg, s.pointerAddress(i), s.depthLimit)
+	}
+}
@@ -0,0 +1,66 @@
+//go:build !nocapnpstrings
+// +build !nocapnpstrings
+
+package capnp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	msg, seg, err := NewMessage(MultiSegment([][]byte{make([]byte, 0, 24)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetUint64(0, 0xdeadbeef)
+	text, err := NewText(seg, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := root.SetPointer(0, text); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewText(seg, "garbage"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Dump(&buf, msg); err != nil {
+		t.Fatal("Dump:", err)
+	}
+	const want = `segment 0 (3 words)
+  00000000: 0001000100000000  root  rawStructPointer(0, capnp.ObjectSize{DataSize: 8, PointerCount: 1})  -> segment 0, 00000008
+  00000008: 00000000deadbeef
+  00000010: 000000010000000a  rawFarPointer(1, 0x0000000000000008)  -> segment 1, 00000000
+segment 1 (3 words)
+  00000000: 0000006f6c6c6568
+  00000008: 00000032fffffff9  landing pad rawListPointer(-2, byte1List, 6)
+! 00000010: 0065676162726167  unreachable
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Dump =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDumpMalformed(t *testing.T) {
+	msg := &Message{Arena: SingleSegment(rawWords(
+		rawStructPointer(0, ObjectSize{PointerCount: 1}),
+		rawListPointer(5, byte1List, 8),
+	))}
+	var buf bytes.Buffer
+	if err := Dump(&buf, msg); err != nil {
+		t.Fatal("Dump:", err)
+	}
+	const want = `segment 0 (2 words)
+  00000000: 0001000000000000  root  rawStructPointer(0, capnp.ObjectSize{DataSize: 0, PointerCount: 1})  -> segment 0, 00000008
+  00000008: 0000004200000015  rawListPointer(5, byte1List, 8)  error: capnp: invalid pointer address
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Dump =\n%s\nwant\n%s", got, want)
+	}
+}
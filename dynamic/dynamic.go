@@ -0,0 +1,405 @@
+// Package dynamic reads and writes Cap'n Proto structs using their
+// schema nodes instead of generated code.  This lets general-purpose
+// tools handle messages of types that they don't import.
+//
+// Field values are passed as Go values of these types:
+//
+//	Void                   nil
+//	Bool                   bool
+//	Int8 ... Int64         int8 ... int64
+//	UInt8 ... UInt64       uint8 ... uint64
+//	Float32, Float64       float32, float64
+//	Text                   string
+//	Data                   []byte
+//	enum                   Enum
+//	struct, group          Struct
+//	List(T)                List
+//	AnyPointer             capnp.Pointer
+//	interface              capnp.Interface
+//
+// Set also accepts a []byte for Text, a uint16 or an enumerant name for
+// an enum, a capnp.Struct for a struct, and a capnp.List for a list.
+// Setting a pointer field to nil clears it.
+package dynamic
+
+import (
+	"fmt"
+	"sync"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/internal/nodemap"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// A Finder finds schema nodes by ID.
+type Finder interface {
+	Find(id uint64) (schema.Node, error)
+}
+
+// Registry returns a Finder for the nodes in the default schemas
+// registry, which holds the nodes of every generated package linked
+// into the program.
+func Registry() Finder {
+	return registry
+}
+
+var registry = new(registryFinder)
+
+type registryFinder struct {
+	mu sync.Mutex
+	m  nodemap.Map
+}
+
+func (r *registryFinder) Find(id uint64) (schema.Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.m.Find(id)
+}
+
+// RequestFinder returns a Finder for the nodes in a
+// CodeGeneratorRequest.
+func RequestFinder(req schema.CodeGeneratorRequest) (Finder, error) {
+	nodes, err := req.Nodes()
+	if err != nil {
+		return nil, err
+	}
+	f := make(requestFinder, nodes.Len())
+	for i := 0; i < nodes.Len(); i++ {
+		n := nodes.At(i)
+		f[n.Id()] = n
+	}
+	return f, nil
+}
+
+type requestFinder map[uint64]schema.Node
+
+func (f requestFinder) Find(id uint64) (schema.Node, error) {
+	n, ok := f[id]
+	if !ok {
+		return schema.Node{}, fmt.Errorf("dynamic: node %#x not found in request", id)
+	}
+	return n, nil
+}
+
+// A Struct is a capnp.Struct with its schema node.  A Struct for a
+// group refers to the same capnp.Struct as its parent.
+type Struct struct {
+	capnp.Struct
+	node   schema.Node
+	finder Finder
+}
+
+// New returns s as a Struct of the struct type with the given ID.  The
+// node and the nodes of the types that s refers to are found with f, or
+// in the default registry if f is nil.
+func New(s capnp.Struct, typeID uint64, f Finder) (Struct, error) {
+	if f == nil {
+		f = registry
+	}
+	n, err := f.Find(typeID)
+	if err != nil {
+		return Struct{}, err
+	}
+	return FromNode(s, n, f)
+}
+
+// FromNode returns s as a Struct of the struct type described by n.
+// The nodes of the types that s refers to are found with f, or in the
+// default registry if f is nil.
+func FromNode(s capnp.Struct, n schema.Node, f Finder) (Struct, error) {
+	if f == nil {
+		f = registry
+	}
+	if n.Which() != schema.Node_Which_structGroup {
+		return Struct{}, fmt.Errorf("dynamic: node %#x is not a struct", n.Id())
+	}
+	return Struct{Struct: s, node: n, finder: f}, nil
+}
+
+// Node returns the schema node of s's type.
+func (s Struct) Node() schema.Node {
+	return s.node
+}
+
+// Fields returns the fields of s's type, including union members and
+// groups, in the order they are listed in the node.
+func (s Struct) Fields() ([]schema.Field, error) {
+	list, err := s.node.StructGroup().Fields()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]schema.Field, list.Len())
+	for i := range fields {
+		fields[i] = list.At(i)
+	}
+	return fields, nil
+}
+
+// Which returns the name of the active union member, or "" if s's type
+// has no unnamed union.
+func (s Struct) Which() (string, error) {
+	if s.node.StructGroup().DiscriminantCount() == 0 {
+		return "", nil
+	}
+	which := s.discriminant()
+	fields, err := s.Fields()
+	if err != nil {
+		return "", err
+	}
+	for _, f := range fields {
+		if f.DiscriminantValue() == which {
+			return f.Name()
+		}
+	}
+	return "", fmt.Errorf("dynamic: unknown discriminant %d in %s", which, s.typeName())
+}
+
+// Has reports whether the named field is set: it must be the active
+// union member if it is in a union, and it must be non-null if it is a
+// pointer.
+func (s Struct) Has(name string) (bool, error) {
+	f, err := s.field(name)
+	if err != nil {
+		return false, err
+	}
+	if !s.isActive(f) {
+		return false, nil
+	}
+	if f.Which() != schema.Field_Which_slot {
+		return true, nil
+	}
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return false, err
+	}
+	if !isPointer(typ.Which()) {
+		return true, nil
+	}
+	p, err := s.Struct.Pointer(uint16(f.Slot().Offset()))
+	if err != nil {
+		return false, err
+	}
+	return capnp.IsValid(p), nil
+}
+
+// Get returns the value of the named field.  A null pointer field
+// returns the field's default value.  It is an error to get a union
+// member that isn't active.
+func (s Struct) Get(name string) (interface{}, error) {
+	f, err := s.field(name)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isActive(f) {
+		return nil, fmt.Errorf("dynamic: field %s of %s is not the active union member", name, s.typeName())
+	}
+	if f.Which() == schema.Field_Which_group {
+		return s.group(f)
+	}
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return nil, err
+	}
+	def, err := f.Slot().DefaultValue()
+	if err != nil {
+		return nil, err
+	}
+	off := f.Slot().Offset()
+	if bits := primitiveBits(typ.Which()); bits > 0 {
+		x := readBits(s.Struct, bits, off) ^ defaultBits(def)
+		return primitiveValue(typ, x, s.finder)
+	}
+	if typ.Which() == schema.Type_Which_void {
+		return nil, nil
+	}
+	p, err := s.Struct.Pointer(uint16(off))
+	if err != nil {
+		return nil, err
+	}
+	if !capnp.IsValid(p) {
+		if p, err = defaultPointer(def); err != nil {
+			return nil, err
+		}
+	}
+	return pointerValue(typ, p, s.finder)
+}
+
+// Set sets the value of the named field.  Setting a union member makes
+// it the active member.  Groups can't be set; use Init.
+func (s Struct) Set(name string, v interface{}) error {
+	f, err := s.field(name)
+	if err != nil {
+		return err
+	}
+	if f.Which() == schema.Field_Which_group {
+		return fmt.Errorf("dynamic: can't set group %s of %s; use Init", name, s.typeName())
+	}
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	def, err := f.Slot().DefaultValue()
+	if err != nil {
+		return err
+	}
+	off := f.Slot().Offset()
+	if bits := primitiveBits(typ.Which()); bits > 0 {
+		x, err := encodePrimitive(typ, v, s.finder)
+		if err != nil {
+			return fieldError(name, err)
+		}
+		if err := s.setActive(f); err != nil {
+			return err
+		}
+		return writeBits(s.Struct, bits, off, x^defaultBits(def))
+	}
+	if typ.Which() == schema.Type_Which_void {
+		if v != nil {
+			return fieldError(name, typeError(typ.Which(), v))
+		}
+		return s.setActive(f)
+	}
+	p, err := newPointer(s.Segment(), typ, v, s.finder)
+	if err != nil {
+		return fieldError(name, err)
+	}
+	if err := s.setActive(f); err != nil {
+		return err
+	}
+	return s.Struct.SetPointer(uint16(off), p)
+}
+
+// Init makes the named field its struct's active union member, if it
+// is in a union, and returns it as a Struct.  A struct field is set to
+// a newly allocated struct.  A group field is returned as it is.
+func (s Struct) Init(name string) (Struct, error) {
+	f, err := s.field(name)
+	if err != nil {
+		return Struct{}, err
+	}
+	if f.Which() == schema.Field_Which_group {
+		if err := s.setActive(f); err != nil {
+			return Struct{}, err
+		}
+		return s.group(f)
+	}
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return Struct{}, err
+	}
+	if typ.Which() != schema.Type_Which_structGroup {
+		return Struct{}, fmt.Errorf("dynamic: can't init field %s of %s: not a struct", name, s.typeName())
+	}
+	st, err := s.newStruct(typ.StructGroup().TypeId())
+	if err != nil {
+		return Struct{}, err
+	}
+	if err := s.setActive(f); err != nil {
+		return Struct{}, err
+	}
+	if err := s.Struct.SetPointer(uint16(f.Slot().Offset()), st.Struct); err != nil {
+		return Struct{}, err
+	}
+	return st, nil
+}
+
+// InitList sets the named list field to a newly allocated list of n
+// elements and returns it.
+func (s Struct) InitList(name string, n int) (List, error) {
+	f, err := s.field(name)
+	if err != nil {
+		return List{}, err
+	}
+	var typ schema.Type
+	if f.Which() == schema.Field_Which_slot {
+		if typ, err = f.Slot().Type(); err != nil {
+			return List{}, err
+		}
+	}
+	if f.Which() != schema.Field_Which_slot || typ.Which() != schema.Type_Which_list {
+		return List{}, fmt.Errorf("dynamic: can't init field %s of %s: not a list", name, s.typeName())
+	}
+	et, err := typ.List().ElementType()
+	if err != nil {
+		return List{}, err
+	}
+	l, err := newList(s.Segment(), et, n, s.finder)
+	if err != nil {
+		return List{}, err
+	}
+	if err := s.setActive(f); err != nil {
+		return List{}, err
+	}
+	if err := s.Struct.SetPointer(uint16(f.Slot().Offset()), l.List); err != nil {
+		return List{}, err
+	}
+	return l, nil
+}
+
+func (s Struct) field(name string) (schema.Field, error) {
+	fields, err := s.node.StructGroup().Fields()
+	if err != nil {
+		return schema.Field{}, err
+	}
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		if n, _ := f.Name(); n == name {
+			return f, nil
+		}
+	}
+	return schema.Field{}, fmt.Errorf("dynamic: no field %s in %s", name, s.typeName())
+}
+
+func (s Struct) group(f schema.Field) (Struct, error) {
+	n, err := s.finder.Find(f.Group().TypeId())
+	if err != nil {
+		return Struct{}, err
+	}
+	return FromNode(s.Struct, n, s.finder)
+}
+
+func (s Struct) discriminant() uint16 {
+	return s.Struct.Uint16(capnp.DataOffset(s.node.StructGroup().DiscriminantOffset() * 2))
+}
+
+func (s Struct) isActive(f schema.Field) bool {
+	dv := f.DiscriminantValue()
+	return dv == schema.Field_noDiscriminant || dv == s.discriminant()
+}
+
+func (s Struct) setActive(f schema.Field) error {
+	dv := f.DiscriminantValue()
+	if dv == schema.Field_noDiscriminant {
+		return nil
+	}
+	off := capnp.DataOffset(s.node.StructGroup().DiscriminantOffset() * 2)
+	return s.Struct.SetUint16Checked(off, dv)
+}
+
+// newStruct allocates a struct of the given type in s's segment.
+func (s Struct) newStruct(typeID uint64) (Struct, error) {
+	n, err := s.finder.Find(typeID)
+	if err != nil {
+		return Struct{}, err
+	}
+	if n.Which() != schema.Node_Which_structGroup {
+		return Struct{}, fmt.Errorf("dynamic: node %#x is not a struct", typeID)
+	}
+	st, err := capnp.NewStruct(s.Segment(), structSize(n))
+	if err != nil {
+		return Struct{}, err
+	}
+	return Struct{Struct: st, node: n, finder: s.finder}, nil
+}
+
+func (s Struct) typeName() string {
+	name, err := s.node.DisplayName()
+	if err != nil || name == "" {
+		return fmt.Sprintf("struct %#x", s.node.Id())
+	}
+	return name
+}
+
+func fieldError(name string, err error) error {
+	return fmt.Errorf("%v (field %s)", err, name)
+}
@@ -0,0 +1,454 @@
+package dynamic
+
+import (
+	"bytes"
+	"testing"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/internal/schematest"
+	"zombiezen.com/go/capnproto/schema"
+	"zombiezen.com/go/capnproto/schemas"
+)
+
+const (
+	colorID = schematest.ColorID
+	pointID = schematest.PointID
+	shapeID = schematest.ShapeID
+)
+
+// testFinder returns a finder for the nodes in the schema that
+// schematest registers, read with RequestFinder rather than from the
+// registry.
+func testFinder(t *testing.T) Finder {
+	data := schemas.Find(shapeID)
+	if data == nil {
+		t.Fatalf("schema for %#x not registered", shapeID)
+	}
+	msg, err := capnp.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := RequestFinder(req)
+	if err != nil {
+		t.Fatal("RequestFinder:", err)
+	}
+	return f
+}
+
+func newShape(t *testing.T) Struct {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := schematest.NewRootShape(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(st.Struct, shapeID, testFinder(t))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	return s
+}
+
+// newEverything returns an Everything struct whose node is found in
+// the default registry.
+func newEverything(t *testing.T) Struct {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := schematest.NewRootEverything(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(st.Struct, schematest.EverythingID, nil)
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	return s
+}
+
+func TestDefaults(t *testing.T) {
+	s := newShape(t)
+	tests := []struct {
+		name string
+		want interface{}
+	}{
+		{"name", ""},
+		{"color", Enum{Value: 1, Name: "green"}},
+		{"circle", float64(0)},
+		{"visible", false},
+	}
+	for _, test := range tests {
+		v, err := s.Get(test.name)
+		if err != nil || v != test.want {
+			t.Errorf("Get(%q) = %#v, %v; want %#v, <nil>", test.name, v, err, test.want)
+		}
+	}
+	if v, err := s.Get("data"); err != nil || v.([]byte) != nil {
+		t.Errorf("Get(\"data\") = %#v, %v; want nil, <nil>", v, err)
+	}
+	if v, err := s.Get("tags"); err != nil || v.(List).Len() != 0 {
+		t.Errorf("Get(\"tags\") = %v, %v; want empty list", v, err)
+	}
+	if has, err := s.Has("name"); err != nil || has {
+		t.Errorf("Has(\"name\") = %t, %v; want false, <nil>", has, err)
+	}
+
+	e := newEverything(t)
+	if v, err := e.Get("text"); err != nil || v != "hi" {
+		t.Errorf("Everything Get(\"text\") = %#v, %v; want \"hi\", <nil>", v, err)
+	}
+	if v, err := e.Get("i32"); err != nil || v != int32(-5) {
+		t.Errorf("Everything Get(\"i32\") = %#v, %v; want -5, <nil>", v, err)
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	s := newShape(t)
+	tests := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{"name", "ball", "ball"},
+		{"name", []byte("box"), "box"},
+		{"color", Enum{Value: 2}, Enum{Value: 2, Name: "blue"}},
+		{"color", "red", Enum{Value: 0, Name: "red"}},
+		{"color", uint16(7), Enum{Value: 7}},
+		{"circle", 2.5, 2.5},
+		{"visible", true, true},
+	}
+	for _, test := range tests {
+		if err := s.Set(test.name, test.in); err != nil {
+			t.Errorf("Set(%q, %#v): %v", test.name, test.in, err)
+			continue
+		}
+		v, err := s.Get(test.name)
+		if err != nil || v != test.want {
+			t.Errorf("after Set(%q, %#v), Get = %#v, %v; want %#v, <nil>", test.name, test.in, v, err, test.want)
+		}
+	}
+	if err := s.Set("data", []byte{1, 2}); err != nil {
+		t.Fatal("Set(\"data\"):", err)
+	}
+	if v, err := s.Get("data"); err != nil || !bytes.Equal(v.([]byte), []byte{1, 2}) {
+		t.Errorf("Get(\"data\") = %v, %v; want [1 2], <nil>", v, err)
+	}
+	e := newEverything(t)
+	if err := e.Set("text", "bye"); err != nil {
+		t.Fatal("Set(\"text\"):", err)
+	}
+	if err := e.Set("text", nil); err != nil {
+		t.Fatal("Set(\"text\", nil):", err)
+	}
+	if v, _ := e.Get("text"); v != "hi" {
+		t.Errorf("after clearing, Get(\"text\") = %q; want default \"hi\"", v)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	s := newShape(t)
+	if w, err := s.Which(); err != nil || w != "circle" {
+		t.Errorf("Which() = %q, %v; want \"circle\", <nil>", w, err)
+	}
+	sq, err := s.Init("square")
+	if err != nil {
+		t.Fatal("Init(\"square\"):", err)
+	}
+	if err := sq.Set("x", int32(3)); err != nil {
+		t.Fatal("square.Set(\"x\"):", err)
+	}
+	if w, _ := s.Which(); w != "square" {
+		t.Errorf("after Init(\"square\"), Which() = %q; want \"square\"", w)
+	}
+	if _, err := s.Get("circle"); err == nil {
+		t.Error("Get of inactive union member succeeded; want error")
+	}
+	if has, _ := s.Has("circle"); has {
+		t.Error("Has(\"circle\") = true for inactive member")
+	}
+	v, err := s.Get("square")
+	if err != nil {
+		t.Fatal("Get(\"square\"):", err)
+	}
+	p := v.(Struct)
+	if x, _ := p.Get("x"); x != int32(3) {
+		t.Errorf("square.x = %v; want 3", x)
+	}
+	if y, _ := p.Get("y"); y != int32(-5) {
+		t.Errorf("square.y = %v; want default -5", y)
+	}
+
+	pos, err := s.Init("position")
+	if err != nil {
+		t.Fatal("Init(\"position\"):", err)
+	}
+	if err := pos.Set("label", "top"); err != nil {
+		t.Fatal("position.Set(\"label\"):", err)
+	}
+	if w, _ := s.Which(); w != "position" {
+		t.Errorf("after Init(\"position\"), Which() = %q; want \"position\"", w)
+	}
+	if pt, _ := s.Struct.Pointer(1); capnp.ToText(pt) != "top" {
+		t.Errorf("position.label stored as %q; want \"top\" in pointer 1", capnp.ToText(pt))
+	}
+	if err := s.Set("position", nil); err == nil {
+		t.Error("Set of group succeeded; want error")
+	}
+}
+
+func TestLists(t *testing.T) {
+	s := newShape(t)
+	tags, err := s.InitList("tags", 2)
+	if err != nil {
+		t.Fatal("InitList(\"tags\"):", err)
+	}
+	if err := tags.Set(0, "a"); err != nil {
+		t.Error("tags.Set(0):", err)
+	}
+	if err := tags.Set(1, "b"); err != nil {
+		t.Error("tags.Set(1):", err)
+	}
+	if err := tags.Set(2, "c"); err == nil {
+		t.Error("tags.Set(2) on 2-element list succeeded; want error")
+	}
+	v, err := s.Get("tags")
+	if err != nil {
+		t.Fatal("Get(\"tags\"):", err)
+	}
+	l := v.(List)
+	for i, want := range []string{"a", "b"} {
+		if e, err := l.At(i); err != nil || e != want {
+			t.Errorf("tags.At(%d) = %#v, %v; want %q, <nil>", i, e, err, want)
+		}
+	}
+
+	f := testFinder(t)
+	pointType := func() schema.Type {
+		_, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
+		typ, _ := schema.NewRootType(seg)
+		typ.SetStructGroup()
+		typ.StructGroup().SetTypeId(pointID)
+		return typ
+	}()
+	points, err := NewList(s.Segment(), pointType, 2, f)
+	if err != nil {
+		t.Fatal("NewList:", err)
+	}
+	e, err := points.At(1)
+	if err != nil {
+		t.Fatal("points.At(1):", err)
+	}
+	if err := e.(Struct).Set("x", int32(9)); err != nil {
+		t.Fatal("Set(\"x\") on list element:", err)
+	}
+	if e, _ := points.At(1); e == nil {
+		t.Error("points.At(1) = nil")
+	} else if x, _ := e.(Struct).Get("x"); x != int32(9) {
+		t.Errorf("points[1].x = %v; want 9", x)
+	}
+	if err := points.Set(0, "x"); err == nil {
+		t.Error("points.Set(0, string) succeeded; want error")
+	}
+
+	matrixType := func() schema.Type {
+		_, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
+		typ, _ := schema.NewRootType(seg)
+		typ.SetList()
+		et, _ := typ.List().NewElementType()
+		et.SetFloat64()
+		return typ
+	}()
+	matrix, err := NewList(s.Segment(), matrixType, 2, f)
+	if err != nil {
+		t.Fatal("NewList:", err)
+	}
+	row, err := matrix.InitList(1, 3)
+	if err != nil {
+		t.Fatal("matrix.InitList:", err)
+	}
+	if err := row.Set(2, 0.5); err != nil {
+		t.Fatal("row.Set:", err)
+	}
+	r, _ := matrix.At(1)
+	if x, err := r.(List).At(2); err != nil || x != 0.5 {
+		t.Errorf("matrix[1][2] = %v, %v; want 0.5, <nil>", x, err)
+	}
+	if r, _ := matrix.At(0); r.(List).Len() != 0 {
+		t.Errorf("matrix[0] has %d elements; want 0", r.(List).Len())
+	}
+}
+
+func TestFields(t *testing.T) {
+	s := newShape(t)
+	fields, err := s.Fields()
+	if err != nil {
+		t.Fatal("Fields:", err)
+	}
+	var names []string
+	for _, f := range fields {
+		name, _ := f.Name()
+		names = append(names, name)
+	}
+	want := []string{"name", "color", "circle", "square", "position", "none", "tags", "points", "data", "visible"}
+	if len(names) != len(want) {
+		t.Fatalf("Fields() names = %q; want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Fields()[%d] = %q; want %q", i, names[i], want[i])
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := newShape(t)
+	if _, err := s.Get("nope"); err == nil {
+		t.Error("Get of unknown field succeeded; want error")
+	}
+	if err := s.Set("circle", float32(1)); err == nil {
+		t.Error("Set of Float64 field to float32 succeeded; want error")
+	}
+	if err := s.Set("color", "purple"); err == nil {
+		t.Error("Set of enum to unknown name succeeded; want error")
+	}
+	if _, err := s.Init("name"); err == nil {
+		t.Error("Init of text field succeeded; want error")
+	}
+	if _, err := s.InitList("name", 1); err == nil {
+		t.Error("InitList of text field succeeded; want error")
+	}
+	pos, err := s.Init("position")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("square", pos); err == nil {
+		t.Error("Set of struct field to group of different type succeeded; want error")
+	}
+	if _, err := New(s.Struct, colorID, testFinder(t)); err == nil {
+		t.Error("New with enum ID succeeded; want error")
+	}
+	if _, err := New(s.Struct, 0x1234, testFinder(t)); err == nil {
+		t.Error("New with unknown ID succeeded; want error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// unterminate replaces the NUL at the end of the text at p.
+	unterminate := func(p capnp.Pointer) {
+		b := capnp.ToData(p)
+		b[len(b)-1] = 'x'
+	}
+	// pointToStruct sets pointer i of sh to a new struct.
+	pointToStruct := func(sh schematest.Shape, i uint16) error {
+		st, err := capnp.NewStruct(sh.Segment(), capnp.ObjectSize{DataSize: 8})
+		if err != nil {
+			return err
+		}
+		return sh.Struct.SetPointer(i, st)
+	}
+	const (
+		notTerminated = "capnp: text is not NUL-terminated"
+		notByteList   = "capnp: text is not a list of bytes"
+	)
+	tests := []struct {
+		name   string
+		damage func(sh schematest.Shape) error
+		path   string // path in the error, or "" for no error
+		err    string
+	}{
+		{"well formed", func(sh schematest.Shape) error {
+			return nil
+		}, "", ""},
+		{"unterminated name", func(sh schematest.Shape) error {
+			p, err := sh.Struct.Pointer(0)
+			unterminate(p)
+			return err
+		}, "root.ptr[0]", notTerminated},
+		{"name is a struct", func(sh schematest.Shape) error {
+			return pointToStruct(sh, 0)
+		}, "root.ptr[0]", notByteList},
+		{"unterminated tag", func(sh schematest.Shape) error {
+			tags, err := sh.Tags()
+			if err != nil {
+				return err
+			}
+			p, err := capnp.PointerList{List: tags.List}.At(1)
+			unterminate(p)
+			return err
+		}, "root.ptr[2].list[1]", notTerminated},
+		{"unterminated label", func(sh schematest.Shape) error {
+			sh.SetPosition()
+			p, err := sh.Struct.Pointer(1)
+			unterminate(p)
+			return err
+		}, "root.ptr[1]", notTerminated},
+		{"label is a struct", func(sh schematest.Shape) error {
+			return pointToStruct(sh, 1)
+		}, "root.ptr[1]", notByteList},
+		{"unterminated label of inactive member", func(sh schematest.Shape) error {
+			p, err := sh.Struct.Pointer(1)
+			unterminate(p)
+			sh.SetCircle(1)
+			return err
+		}, "", ""},
+		{"square shares the label's pointer", func(sh schematest.Shape) error {
+			_, err := sh.NewSquare()
+			return err
+		}, "", ""},
+	}
+	for _, test := range tests {
+		msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sh, err := schematest.NewRootShape(seg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sh.SetName("ball"); err != nil {
+			t.Fatal(err)
+		}
+		sh.SetPosition()
+		if err := sh.Position().SetLabel("top"); err != nil {
+			t.Fatal(err)
+		}
+		tags, err := capnp.NewTextList(seg, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tags.Set(0, "a")
+		tags.Set(1, "b")
+		if err := sh.SetTags(tags); err != nil {
+			t.Fatal(err)
+		}
+		if err := sh.SetData([]byte("xyz")); err != nil {
+			t.Fatal(err)
+		}
+		if err := test.damage(sh); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		err = Validate(msg, shapeID, testFinder(t))
+		if test.path == "" {
+			if err != nil {
+				t.Errorf("%s: Validate: %v", test.name, err)
+			}
+			continue
+		}
+		verr, ok := err.(*capnp.ValidationError)
+		if !ok {
+			t.Errorf("%s: Validate error = %v; want *capnp.ValidationError", test.name, err)
+			continue
+		}
+		if verr.Path != test.path || verr.Err.Error() != test.err {
+			t.Errorf("%s: Validate error = %v; want %s at %s", test.name, err, test.err, test.path)
+		}
+	}
+}
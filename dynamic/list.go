@@ -0,0 +1,187 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// A List is a capnp.List with its element type.  Elements are passed as
+// the same Go types as struct fields.
+type List struct {
+	capnp.List
+	elem   schema.Type
+	finder Finder
+}
+
+// NewList allocates a list of n elements of type elem in seg.  The nodes
+// of the types that the list refers to are found with f, or in the
+// default registry if f is nil.
+func NewList(seg *capnp.Segment, elem schema.Type, n int, f Finder) (List, error) {
+	if f == nil {
+		f = registry
+	}
+	return newList(seg, elem, n, f)
+}
+
+// ElementType returns the type of l's elements.
+func (l List) ElementType() schema.Type {
+	return l.elem
+}
+
+// At returns the i'th element of l.
+func (l List) At(i int) (interface{}, error) {
+	if i < 0 || i >= l.Len() {
+		return nil, errOutOfRange
+	}
+	if bits := primitiveBits(l.elem.Which()); bits > 0 {
+		var x uint64
+		switch bits {
+		case 1:
+			if (capnp.BitList{List: l.List}).At(i) {
+				x = 1
+			}
+		case 8:
+			x = uint64(capnp.UInt8List{List: l.List}.At(i))
+		case 16:
+			x = uint64(capnp.UInt16List{List: l.List}.At(i))
+		case 32:
+			x = uint64(capnp.UInt32List{List: l.List}.At(i))
+		case 64:
+			x = capnp.UInt64List{List: l.List}.At(i)
+		}
+		return primitiveValue(l.elem, x, l.finder)
+	}
+	switch l.elem.Which() {
+	case schema.Type_Which_void:
+		return nil, nil
+	case schema.Type_Which_structGroup:
+		n, err := l.finder.Find(l.elem.StructGroup().TypeId())
+		if err != nil {
+			return nil, err
+		}
+		return FromNode(l.List.Struct(i), n, l.finder)
+	}
+	p, err := capnp.PointerList{List: l.List}.At(i)
+	if err != nil {
+		return nil, err
+	}
+	return pointerValue(l.elem, p, l.finder)
+}
+
+// Set sets the i'th element of l to v.  Struct elements are copied.
+func (l List) Set(i int, v interface{}) error {
+	if i < 0 || i >= l.Len() {
+		return errOutOfRange
+	}
+	if bits := primitiveBits(l.elem.Which()); bits > 0 {
+		x, err := encodePrimitive(l.elem, v, l.finder)
+		if err != nil {
+			return err
+		}
+		switch bits {
+		case 1:
+			return capnp.BitList{List: l.List}.SetChecked(i, x != 0)
+		case 8:
+			return capnp.UInt8List{List: l.List}.SetChecked(i, uint8(x))
+		case 16:
+			return capnp.UInt16List{List: l.List}.SetChecked(i, uint16(x))
+		case 32:
+			return capnp.UInt32List{List: l.List}.SetChecked(i, uint32(x))
+		default:
+			return capnp.UInt64List{List: l.List}.SetChecked(i, x)
+		}
+	}
+	switch l.elem.Which() {
+	case schema.Type_Which_void:
+		if v != nil {
+			return typeError(l.elem.Which(), v)
+		}
+		return nil
+	case schema.Type_Which_structGroup:
+		p, err := newPointer(l.Segment(), l.elem, v, l.finder)
+		if err != nil {
+			return err
+		}
+		return l.List.SetStruct(i, capnp.ToStruct(p))
+	}
+	p, err := newPointer(l.Segment(), l.elem, v, l.finder)
+	if err != nil {
+		return err
+	}
+	return capnp.PointerList{List: l.List}.Set(i, p)
+}
+
+// InitList sets the i'th element of a list of lists to a newly
+// allocated list of n elements and returns it.
+func (l List) InitList(i, n int) (List, error) {
+	if i < 0 || i >= l.Len() {
+		return List{}, errOutOfRange
+	}
+	if l.elem.Which() != schema.Type_Which_list {
+		return List{}, fmt.Errorf("dynamic: can't init element of List(%v): not a list", l.elem.Which())
+	}
+	et, err := l.elem.List().ElementType()
+	if err != nil {
+		return List{}, err
+	}
+	sub, err := newList(l.Segment(), et, n, l.finder)
+	if err != nil {
+		return List{}, err
+	}
+	if err := (capnp.PointerList{List: l.List}).Set(i, sub.List); err != nil {
+		return List{}, err
+	}
+	return sub, nil
+}
+
+func newList(seg *capnp.Segment, elem schema.Type, n int, f Finder) (List, error) {
+	var l capnp.List
+	var err error
+	switch primitiveBits(elem.Which()) {
+	case 1:
+		var bl capnp.BitList
+		bl, err = capnp.NewBitList(seg, int32(n))
+		l = bl.List
+	case 8:
+		var ul capnp.UInt8List
+		ul, err = capnp.NewUInt8List(seg, int32(n))
+		l = ul.List
+	case 16:
+		var ul capnp.UInt16List
+		ul, err = capnp.NewUInt16List(seg, int32(n))
+		l = ul.List
+	case 32:
+		var ul capnp.UInt32List
+		ul, err = capnp.NewUInt32List(seg, int32(n))
+		l = ul.List
+	case 64:
+		var ul capnp.UInt64List
+		ul, err = capnp.NewUInt64List(seg, int32(n))
+		l = ul.List
+	default:
+		switch elem.Which() {
+		case schema.Type_Which_void:
+			l = capnp.NewVoidList(seg, int32(n)).List
+		case schema.Type_Which_structGroup:
+			var node schema.Node
+			node, err = f.Find(elem.StructGroup().TypeId())
+			if err != nil {
+				return List{}, err
+			}
+			l, err = capnp.NewCompositeList(seg, structSize(node), int32(n))
+		default:
+			var pl capnp.PointerList
+			pl, err = capnp.NewPointerList(seg, int32(n))
+			l = pl.List
+		}
+	}
+	if err != nil {
+		return List{}, err
+	}
+	return List{List: l, elem: elem, finder: f}, nil
+}
+
+var errOutOfRange = errors.New("dynamic: list index out of range")
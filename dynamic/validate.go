@@ -0,0 +1,90 @@
+package dynamic
+
+import (
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// Validate checks msg like capnp.ValidateType, using the schema of its
+// root, a struct of the type with the given ID, to find the Text that
+// must be NUL-terminated.  Only the active members of unions are
+// checked.  The nodes are found with f, or in the default registry if f
+// is nil.  Problems with the message are reported as a
+// *capnp.ValidationError.
+func Validate(msg *capnp.Message, typeID uint64, f Finder) error {
+	if f == nil {
+		f = registry
+	}
+	return capnp.ValidateType(msg, structType(typeID, f))
+}
+
+// structType returns the type of a pointer to a struct of the type with
+// the given ID.
+func structType(id uint64, f Finder) capnp.PointerType {
+	return capnp.PointerType{
+		Pointer: func(s capnp.Struct, i uint16) (capnp.PointerType, error) {
+			ds, err := New(s, id, f)
+			if err != nil {
+				return capnp.PointerType{}, err
+			}
+			t, _, err := fieldType(ds, i)
+			return t, err
+		},
+	}
+}
+
+// fieldType returns the type of pointer i of s, as given by the active
+// field that it holds.  ok is false if no active field holds it.
+func fieldType(s Struct, i uint16) (t capnp.PointerType, ok bool, err error) {
+	fields, err := s.Fields()
+	if err != nil {
+		return capnp.PointerType{}, false, err
+	}
+	for _, f := range fields {
+		if !s.isActive(f) {
+			continue
+		}
+		if f.Which() == schema.Field_Which_group {
+			g, err := s.group(f)
+			if err != nil {
+				return capnp.PointerType{}, false, err
+			}
+			if t, ok, err := fieldType(g, i); ok || err != nil {
+				return t, ok, err
+			}
+			continue
+		}
+		typ, err := f.Slot().Type()
+		if err != nil {
+			return capnp.PointerType{}, false, err
+		}
+		if isPointer(typ.Which()) && f.Slot().Offset() == uint32(i) {
+			t, err := pointerType(typ, s.finder)
+			return t, true, err
+		}
+	}
+	return capnp.PointerType{}, false, nil
+}
+
+// pointerType returns the type of a pointer that holds a value of type
+// typ.
+func pointerType(typ schema.Type, f Finder) (capnp.PointerType, error) {
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		return capnp.PointerType{Text: true}, nil
+	case schema.Type_Which_structGroup:
+		return structType(typ.StructGroup().TypeId(), f), nil
+	case schema.Type_Which_list:
+		et, err := typ.List().ElementType()
+		if err != nil {
+			return capnp.PointerType{}, err
+		}
+		elem, err := pointerType(et, f)
+		if err != nil {
+			return capnp.PointerType{}, err
+		}
+		return capnp.PointerType{Elem: &elem}, nil
+	default:
+		return capnp.PointerType{}, nil
+	}
+}
@@ -0,0 +1,361 @@
+package dynamic
+
+import (
+	"fmt"
+	"math"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// An Enum is a value of an enum type.
+type Enum struct {
+	Value uint16
+
+	// Name is the name of the enumerant, or "" if Value isn't one of the
+	// enumerants in the schema.  Set ignores Name.
+	Name string
+}
+
+// String returns the enumerant name, or the value if it has no name.
+func (e Enum) String() string {
+	if e.Name == "" {
+		return fmt.Sprint(e.Value)
+	}
+	return e.Name
+}
+
+// enumerants returns the names of the enum with the given ID.
+func enumerants(id uint64, f Finder) ([]string, error) {
+	n, err := f.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if n.Which() != schema.Node_Which_enum {
+		return nil, fmt.Errorf("dynamic: node %#x is not an enum", id)
+	}
+	list, err := n.Enum().Enumerants()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, list.Len())
+	for i := range names {
+		if names[i], err = list.At(i).Name(); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// primitiveBits returns the size of a primitive type in bits or zero
+// if t is not a primitive type.
+func primitiveBits(t schema.Type_Which) uint32 {
+	switch t {
+	case schema.Type_Which_bool:
+		return 1
+	case schema.Type_Which_int8, schema.Type_Which_uint8:
+		return 8
+	case schema.Type_Which_int16, schema.Type_Which_uint16, schema.Type_Which_enum:
+		return 16
+	case schema.Type_Which_int32, schema.Type_Which_uint32, schema.Type_Which_float32:
+		return 32
+	case schema.Type_Which_int64, schema.Type_Which_uint64, schema.Type_Which_float64:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// isPointer reports whether values of type t are stored in a pointer.
+func isPointer(t schema.Type_Which) bool {
+	return t != schema.Type_Which_void && primitiveBits(t) == 0
+}
+
+// defaultBits returns the bits that a primitive field's value is
+// XORed with to encode it.
+func defaultBits(v schema.Value) uint64 {
+	switch v.Which() {
+	case schema.Value_Which_bool:
+		if v.Bool() {
+			return 1
+		}
+	case schema.Value_Which_int8:
+		return uint64(uint8(v.Int8()))
+	case schema.Value_Which_int16:
+		return uint64(uint16(v.Int16()))
+	case schema.Value_Which_int32:
+		return uint64(uint32(v.Int32()))
+	case schema.Value_Which_int64:
+		return uint64(v.Int64())
+	case schema.Value_Which_uint8:
+		return uint64(v.Uint8())
+	case schema.Value_Which_uint16:
+		return uint64(v.Uint16())
+	case schema.Value_Which_uint32:
+		return uint64(v.Uint32())
+	case schema.Value_Which_uint64:
+		return v.Uint64()
+	case schema.Value_Which_float32:
+		return uint64(math.Float32bits(v.Float32()))
+	case schema.Value_Which_float64:
+		return math.Float64bits(v.Float64())
+	case schema.Value_Which_enum:
+		return uint64(v.Enum())
+	}
+	return 0
+}
+
+// defaultPointer returns the default value of a pointer field.
+func defaultPointer(def schema.Value) (capnp.Pointer, error) {
+	switch def.Which() {
+	case schema.Value_Which_text, schema.Value_Which_data:
+		// Text and data are stored in the value's only pointer.
+		return def.Struct.Pointer(0)
+	case schema.Value_Which_list:
+		return def.List()
+	case schema.Value_Which_structField:
+		return def.StructField()
+	case schema.Value_Which_anyPointer:
+		return def.AnyPointer()
+	default:
+		return nil, nil
+	}
+}
+
+func structSize(n schema.Node) capnp.ObjectSize {
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructGroup().DataWordCount()) * 8,
+		PointerCount: n.StructGroup().PointerCount(),
+	}
+}
+
+// readBits returns the bits of the primitive at offset off, in units
+// of the primitive's size, in s's data section.
+func readBits(s capnp.Struct, bits uint32, off uint32) uint64 {
+	switch bits {
+	case 1:
+		if s.Bit(capnp.BitOffset(off)) {
+			return 1
+		}
+		return 0
+	case 8:
+		return uint64(s.Uint8(capnp.DataOffset(off)))
+	case 16:
+		return uint64(s.Uint16(capnp.DataOffset(off * 2)))
+	case 32:
+		return uint64(s.Uint32(capnp.DataOffset(off * 4)))
+	default:
+		return s.Uint64(capnp.DataOffset(off * 8))
+	}
+}
+
+// writeBits is the inverse of readBits.
+func writeBits(s capnp.Struct, bits uint32, off uint32, x uint64) error {
+	switch bits {
+	case 1:
+		return s.SetBitChecked(capnp.BitOffset(off), x != 0)
+	case 8:
+		return s.SetUint8Checked(capnp.DataOffset(off), uint8(x))
+	case 16:
+		return s.SetUint16Checked(capnp.DataOffset(off*2), uint16(x))
+	case 32:
+		return s.SetUint32Checked(capnp.DataOffset(off*4), uint32(x))
+	default:
+		return s.SetUint64Checked(capnp.DataOffset(off*8), x)
+	}
+}
+
+// primitiveValue returns the Go value of a primitive type from its
+// encoded bits.
+func primitiveValue(typ schema.Type, x uint64, f Finder) (interface{}, error) {
+	switch typ.Which() {
+	case schema.Type_Which_bool:
+		return x != 0, nil
+	case schema.Type_Which_int8:
+		return int8(x), nil
+	case schema.Type_Which_int16:
+		return int16(x), nil
+	case schema.Type_Which_int32:
+		return int32(x), nil
+	case schema.Type_Which_int64:
+		return int64(x), nil
+	case schema.Type_Which_uint8:
+		return uint8(x), nil
+	case schema.Type_Which_uint16:
+		return uint16(x), nil
+	case schema.Type_Which_uint32:
+		return uint32(x), nil
+	case schema.Type_Which_uint64:
+		return x, nil
+	case schema.Type_Which_float32:
+		return math.Float32frombits(uint32(x)), nil
+	case schema.Type_Which_float64:
+		return math.Float64frombits(x), nil
+	case schema.Type_Which_enum:
+		names, err := enumerants(typ.Enum().TypeId(), f)
+		if err != nil {
+			return nil, err
+		}
+		e := Enum{Value: uint16(x)}
+		if int(e.Value) < len(names) {
+			e.Name = names[e.Value]
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("dynamic: %v is not a primitive type", typ.Which())
+	}
+}
+
+// encodePrimitive returns the encoded bits of v, which must be a Go
+// value of the primitive type typ.
+func encodePrimitive(typ schema.Type, v interface{}, f Finder) (uint64, error) {
+	ok := false
+	var x uint64
+	switch typ.Which() {
+	case schema.Type_Which_bool:
+		var b bool
+		if b, ok = v.(bool); ok && b {
+			x = 1
+		}
+	case schema.Type_Which_int8:
+		var i int8
+		i, ok = v.(int8)
+		x = uint64(uint8(i))
+	case schema.Type_Which_int16:
+		var i int16
+		i, ok = v.(int16)
+		x = uint64(uint16(i))
+	case schema.Type_Which_int32:
+		var i int32
+		i, ok = v.(int32)
+		x = uint64(uint32(i))
+	case schema.Type_Which_int64:
+		var i int64
+		i, ok = v.(int64)
+		x = uint64(i)
+	case schema.Type_Which_uint8:
+		var u uint8
+		u, ok = v.(uint8)
+		x = uint64(u)
+	case schema.Type_Which_uint16:
+		var u uint16
+		u, ok = v.(uint16)
+		x = uint64(u)
+	case schema.Type_Which_uint32:
+		var u uint32
+		u, ok = v.(uint32)
+		x = uint64(u)
+	case schema.Type_Which_uint64:
+		x, ok = v.(uint64)
+	case schema.Type_Which_float32:
+		var f float32
+		f, ok = v.(float32)
+		x = uint64(math.Float32bits(f))
+	case schema.Type_Which_float64:
+		var f float64
+		f, ok = v.(float64)
+		x = math.Float64bits(f)
+	case schema.Type_Which_enum:
+		switch v := v.(type) {
+		case Enum:
+			return uint64(v.Value), nil
+		case uint16:
+			return uint64(v), nil
+		case string:
+			names, err := enumerants(typ.Enum().TypeId(), f)
+			if err != nil {
+				return 0, err
+			}
+			for i, name := range names {
+				if name == v {
+					return uint64(i), nil
+				}
+			}
+			return 0, fmt.Errorf("dynamic: no enumerant %s in enum %#x", v, typ.Enum().TypeId())
+		}
+	}
+	if !ok {
+		return 0, typeError(typ.Which(), v)
+	}
+	return x, nil
+}
+
+// pointerValue returns the Go value of a pointer type.
+func pointerValue(typ schema.Type, p capnp.Pointer, f Finder) (interface{}, error) {
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		return capnp.ToText(p), nil
+	case schema.Type_Which_data:
+		return capnp.ToData(p), nil
+	case schema.Type_Which_structGroup:
+		n, err := f.Find(typ.StructGroup().TypeId())
+		if err != nil {
+			return nil, err
+		}
+		return FromNode(capnp.ToStruct(p), n, f)
+	case schema.Type_Which_list:
+		et, err := typ.List().ElementType()
+		if err != nil {
+			return nil, err
+		}
+		return List{List: capnp.ToList(p), elem: et, finder: f}, nil
+	case schema.Type_Which_anyPointer:
+		return p, nil
+	case schema.Type_Which_interface:
+		return capnp.ToInterface(p), nil
+	default:
+		return nil, fmt.Errorf("dynamic: %v is not a pointer type", typ.Which())
+	}
+}
+
+// newPointer returns a pointer to v, which must be a Go value of the
+// pointer type typ, allocating text and data in seg.  A nil v returns a
+// null pointer.
+func newPointer(seg *capnp.Segment, typ schema.Type, v interface{}, f Finder) (capnp.Pointer, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		switch v := v.(type) {
+		case string:
+			return capnp.NewText(seg, v)
+		case []byte:
+			return capnp.NewText(seg, string(v))
+		}
+	case schema.Type_Which_data:
+		if v, ok := v.([]byte); ok {
+			return capnp.NewData(seg, v)
+		}
+	case schema.Type_Which_structGroup:
+		switch v := v.(type) {
+		case Struct:
+			if id := typ.StructGroup().TypeId(); v.node.Id() != id {
+				return nil, fmt.Errorf("dynamic: can't use %s as struct %#x", v.typeName(), id)
+			}
+			return v.Struct, nil
+		case capnp.Struct:
+			return v, nil
+		}
+	case schema.Type_Which_list:
+		switch v := v.(type) {
+		case List:
+			return v.List, nil
+		case capnp.List:
+			return v, nil
+		}
+	case schema.Type_Which_anyPointer:
+		if v, ok := v.(capnp.Pointer); ok {
+			return v, nil
+		}
+	case schema.Type_Which_interface:
+		if v, ok := v.(capnp.Interface); ok {
+			return v, nil
+		}
+	}
+	return nil, typeError(typ.Which(), v)
+}
+
+func typeError(t schema.Type_Which, v interface{}) error {
+	return fmt.Errorf("dynamic: can't use Go type %T as %v", v, t)
+}
@@ -0,0 +1,418 @@
+package text
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"zombiezen.com/go/capnproto/dynamic"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// Decode parses text, the text format of a struct, and sets the fields
+// it lists in s.  Objects that the fields point to are allocated in s's
+// segment.
+func Decode(s dynamic.Struct, text string) error {
+	err := decode(s, text)
+	if e, ok := err.(*syntaxError); ok {
+		e.pos = positionOf(text, e.off)
+	}
+	return err
+}
+
+func decode(s dynamic.Struct, text string) error {
+	p := &parser{lex: lexer{s: text}}
+	p.next()
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	if p.tok.kind != tokEOF {
+		return p.errorf("unexpected %v after struct", p.tok)
+	}
+	return setStruct(s, v)
+}
+
+// A value is a parsed value whose type isn't known yet.
+type value struct {
+	kind   valueKind
+	off    int
+	text   string // for identifiers, numbers, and strings
+	data   []byte // for data literals
+	fields []fieldValue
+	elems  []*value
+}
+
+type valueKind int
+
+const (
+	identValue valueKind = iota
+	numberValue
+	stringValue
+	dataValue
+	structValue
+	listValue
+)
+
+var valueKindNames = [...]string{
+	identValue:  "identifier",
+	numberValue: "number",
+	stringValue: "string",
+	dataValue:   "data",
+	structValue: "struct",
+	listValue:   "list",
+}
+
+func (k valueKind) String() string {
+	return valueKindNames[k]
+}
+
+type fieldValue struct {
+	name string
+	val  *value
+}
+
+func setStruct(s dynamic.Struct, v *value) error {
+	if v.kind != structValue {
+		return v.errorf("expected struct, found %v", v.kind)
+	}
+	fields, err := s.Fields()
+	if err != nil {
+		return err
+	}
+	for _, fv := range v.fields {
+		f, ok := findField(fields, fv.name)
+		if !ok {
+			return fv.val.errorf("no field %s", fv.name)
+		}
+		if err := setField(s, f, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findField(fields []schema.Field, name string) (schema.Field, bool) {
+	for _, f := range fields {
+		if n, _ := f.Name(); n == name {
+			return f, true
+		}
+	}
+	return schema.Field{}, false
+}
+
+func setField(s dynamic.Struct, f schema.Field, fv fieldValue) error {
+	if f.Which() == schema.Field_Which_group {
+		g, err := s.Init(fv.name)
+		if err != nil {
+			return err
+		}
+		return setStruct(g, fv.val)
+	}
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	switch typ.Which() {
+	case schema.Type_Which_structGroup:
+		if fv.val.kind != structValue {
+			return fv.val.errorf("expected struct for field %s, found %v", fv.name, fv.val.kind)
+		}
+		st, err := s.Init(fv.name)
+		if err != nil {
+			return err
+		}
+		return setStruct(st, fv.val)
+	case schema.Type_Which_list:
+		if fv.val.kind != listValue {
+			return fv.val.errorf("expected list for field %s, found %v", fv.name, fv.val.kind)
+		}
+		l, err := s.InitList(fv.name, len(fv.val.elems))
+		if err != nil {
+			return err
+		}
+		return setList(l, fv.val)
+	}
+	x, err := scalar(typ, fv.val)
+	if err != nil {
+		return err
+	}
+	return s.Set(fv.name, x)
+}
+
+func setList(l dynamic.List, v *value) error {
+	et := l.ElementType()
+	for i, e := range v.elems {
+		switch et.Which() {
+		case schema.Type_Which_structGroup:
+			st, err := l.At(i)
+			if err != nil {
+				return err
+			}
+			if err := setStruct(st.(dynamic.Struct), e); err != nil {
+				return err
+			}
+		case schema.Type_Which_list:
+			if e.kind != listValue {
+				return e.errorf("expected list, found %v", e.kind)
+			}
+			sub, err := l.InitList(i, len(e.elems))
+			if err != nil {
+				return err
+			}
+			if err := setList(sub, e); err != nil {
+				return err
+			}
+		default:
+			x, err := scalar(et, e)
+			if err != nil {
+				return err
+			}
+			if err := l.Set(i, x); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scalar converts v to the Go value that the dynamic package uses for
+// typ, which must not be a struct or list type.
+func scalar(typ schema.Type, v *value) (interface{}, error) {
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		if v.kind == identValue && v.text == "void" {
+			return nil, nil
+		}
+	case schema.Type_Which_bool:
+		if v.kind == identValue {
+			switch v.text {
+			case "true":
+				return true, nil
+			case "false":
+				return false, nil
+			}
+		}
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64:
+		if v.kind != numberValue {
+			break
+		}
+		bits := intBits[typ.Which()]
+		i, err := strconv.ParseInt(v.text, 0, bits)
+		if err != nil {
+			return nil, v.errorf("invalid %v %s", typ.Which(), v.text)
+		}
+		switch bits {
+		case 8:
+			return int8(i), nil
+		case 16:
+			return int16(i), nil
+		case 32:
+			return int32(i), nil
+		default:
+			return i, nil
+		}
+	case schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
+		if v.kind != numberValue {
+			break
+		}
+		bits := intBits[typ.Which()]
+		u, err := strconv.ParseUint(v.text, 0, bits)
+		if err != nil {
+			return nil, v.errorf("invalid %v %s", typ.Which(), v.text)
+		}
+		switch bits {
+		case 8:
+			return uint8(u), nil
+		case 16:
+			return uint16(u), nil
+		case 32:
+			return uint32(u), nil
+		default:
+			return u, nil
+		}
+	case schema.Type_Which_float32, schema.Type_Which_float64:
+		f, ok := parseFloat(v)
+		if !ok {
+			break
+		}
+		if typ.Which() == schema.Type_Which_float32 {
+			return float32(f), nil
+		}
+		return f, nil
+	case schema.Type_Which_enum:
+		switch v.kind {
+		case identValue:
+			return v.text, nil
+		case numberValue:
+			u, err := strconv.ParseUint(v.text, 0, 16)
+			if err != nil {
+				return nil, v.errorf("invalid enum value %s", v.text)
+			}
+			return uint16(u), nil
+		}
+	case schema.Type_Which_text:
+		if v.kind == stringValue {
+			return v.text, nil
+		}
+	case schema.Type_Which_data:
+		switch v.kind {
+		case dataValue:
+			return v.data, nil
+		case stringValue:
+			return []byte(v.text), nil
+		}
+	case schema.Type_Which_anyPointer, schema.Type_Which_interface:
+		return nil, v.errorf("can't decode %v values", typ.Which())
+	}
+	return nil, v.errorf("can't use %v as %v", v.kind, typ.Which())
+}
+
+var intBits = map[schema.Type_Which]int{
+	schema.Type_Which_int8:   8,
+	schema.Type_Which_int16:  16,
+	schema.Type_Which_int32:  32,
+	schema.Type_Which_int64:  64,
+	schema.Type_Which_uint8:  8,
+	schema.Type_Which_uint16: 16,
+	schema.Type_Which_uint32: 32,
+	schema.Type_Which_uint64: 64,
+}
+
+func parseFloat(v *value) (float64, bool) {
+	if v.kind == identValue {
+		switch v.text {
+		case "inf":
+			return math.Inf(1), true
+		case "nan":
+			return math.NaN(), true
+		}
+		return 0, false
+	}
+	if v.kind != numberValue {
+		return 0, false
+	}
+	switch v.text {
+	case "-inf":
+		return math.Inf(-1), true
+	case "-nan":
+		return math.NaN(), true
+	}
+	if strings.HasPrefix(v.text, "0x") || strings.HasPrefix(v.text, "-0x") {
+		i, err := strconv.ParseInt(v.text, 0, 64)
+		return float64(i), err == nil
+	}
+	f, err := strconv.ParseFloat(v.text, 64)
+	return f, err == nil
+}
+
+type parser struct {
+	lex lexer
+	tok token
+	err error
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+}
+
+func (p *parser) parseValue() (*value, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	v := &value{off: tok.off}
+	switch tok.kind {
+	case tokIdent:
+		v.kind, v.text = identValue, tok.text
+	case tokNumber:
+		v.kind, v.text = numberValue, tok.text
+	case tokString:
+		v.kind, v.text = stringValue, tok.text
+	case tokData:
+		v.kind, v.data = dataValue, []byte(tok.text)
+	case tokLParen:
+		v.kind = structValue
+		p.next()
+		for p.err == nil && p.tok.kind != tokRParen {
+			if p.tok.kind != tokIdent {
+				return nil, p.errorf("expected field name, found %v", p.tok)
+			}
+			name := p.tok.text
+			p.next()
+			if p.err == nil && p.tok.kind != tokEquals {
+				return nil, p.errorf("expected = after field name %s, found %v", name, p.tok)
+			}
+			p.next()
+			fv, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			v.fields = append(v.fields, fieldValue{name, fv})
+			if err := p.parseSeparator(tokRParen); err != nil {
+				return nil, err
+			}
+		}
+	case tokLBracket:
+		v.kind = listValue
+		p.next()
+		for p.err == nil && p.tok.kind != tokRBracket {
+			e, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			v.elems = append(v.elems, e)
+			if err := p.parseSeparator(tokRBracket); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, p.errorf("unexpected %v", tok)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.next()
+	return v, nil
+}
+
+// parseSeparator consumes the comma after an element of a struct or
+// list, or stops before the closing token.
+func (p *parser) parseSeparator(end tokenKind) error {
+	if p.err != nil {
+		return p.err
+	}
+	switch p.tok.kind {
+	case tokComma:
+		p.next()
+		return p.err
+	case end:
+		return nil
+	default:
+		return p.errorf("expected , or %v, found %v", end, p.tok)
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &syntaxError{off: p.tok.off, msg: fmt.Sprintf(format, args...)}
+}
+
+func (v *value) errorf(format string, args ...interface{}) error {
+	return &syntaxError{off: v.off, msg: fmt.Sprintf(format, args...)}
+}
+
+// A syntaxError is an error in the input.  pos is filled in from off
+// by Decode.
+type syntaxError struct {
+	off int
+	pos position
+	msg string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("text: %v: %s", e.pos, e.msg)
+}
@@ -0,0 +1,259 @@
+package text
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/dynamic"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// Encode writes the text format of s to w.
+func Encode(w io.Writer, s dynamic.Struct) error {
+	enc := new(encoder)
+	if err := enc.writeStruct(s); err != nil {
+		return err
+	}
+	_, err := w.Write(enc.buf.Bytes())
+	return err
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (enc *encoder) writeStruct(s dynamic.Struct) error {
+	fields, err := s.Fields()
+	if err != nil {
+		return err
+	}
+	enc.buf.WriteByte('(')
+	first := true
+	for _, f := range fields {
+		ok, err := isSet(s, f)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		name, err := f.Name()
+		if err != nil {
+			return err
+		}
+		v, err := s.Get(name)
+		if err != nil {
+			return err
+		}
+		if !first {
+			enc.buf.WriteString(", ")
+		}
+		first = false
+		enc.buf.WriteString(name)
+		enc.buf.WriteString(" = ")
+		if err := enc.writeValue(v); err != nil {
+			return fmt.Errorf("%v (field %s)", err, name)
+		}
+	}
+	enc.buf.WriteByte(')')
+	return nil
+}
+
+func (enc *encoder) writeValue(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		enc.buf.WriteString("void")
+	case bool:
+		enc.buf.WriteString(strconv.FormatBool(v))
+	case int8:
+		enc.buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int16:
+		enc.buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int32:
+		enc.buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		enc.buf.WriteString(strconv.FormatInt(v, 10))
+	case uint8:
+		enc.buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		enc.buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		enc.buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		enc.buf.WriteString(strconv.FormatUint(v, 10))
+	case float32:
+		enc.writeFloat(float64(v), 32)
+	case float64:
+		enc.writeFloat(v, 64)
+	case string:
+		enc.writeString(v)
+	case []byte:
+		enc.writeData(v)
+	case dynamic.Enum:
+		enc.buf.WriteString(v.String())
+	case dynamic.Struct:
+		return enc.writeStruct(v)
+	case dynamic.List:
+		return enc.writeList(v)
+	case capnp.Interface:
+		enc.buf.WriteString("<external capability>")
+	case capnp.Pointer:
+		enc.buf.WriteString("<opaque pointer>")
+	default:
+		return fmt.Errorf("text: can't encode Go type %T", v)
+	}
+	return nil
+}
+
+func (enc *encoder) writeFloat(f float64, bits int) {
+	switch {
+	case math.IsInf(f, 1):
+		enc.buf.WriteString("inf")
+	case math.IsInf(f, -1):
+		enc.buf.WriteString("-inf")
+	case math.IsNaN(f):
+		enc.buf.WriteString("nan")
+	default:
+		enc.buf.WriteString(strconv.FormatFloat(f, 'g', -1, bits))
+	}
+}
+
+// writeString writes s as a double-quoted string, escaping control
+// characters and bytes outside of ASCII.
+func (enc *encoder) writeString(s string) {
+	const hex = "0123456789abcdef"
+	enc.buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\a':
+			enc.buf.WriteString(`\a`)
+		case '\b':
+			enc.buf.WriteString(`\b`)
+		case '\f':
+			enc.buf.WriteString(`\f`)
+		case '\n':
+			enc.buf.WriteString(`\n`)
+		case '\r':
+			enc.buf.WriteString(`\r`)
+		case '\t':
+			enc.buf.WriteString(`\t`)
+		case '\v':
+			enc.buf.WriteString(`\v`)
+		case '"':
+			enc.buf.WriteString(`\"`)
+		case '\\':
+			enc.buf.WriteString(`\\`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				enc.buf.WriteString(`\x`)
+				enc.buf.WriteByte(hex[c>>4])
+				enc.buf.WriteByte(hex[c&0xf])
+			} else {
+				enc.buf.WriteByte(c)
+			}
+		}
+	}
+	enc.buf.WriteByte('"')
+}
+
+func (enc *encoder) writeData(b []byte) {
+	const hex = "0123456789abcdef"
+	enc.buf.WriteString(`0x"`)
+	for i, c := range b {
+		if i > 0 {
+			enc.buf.WriteByte(' ')
+		}
+		enc.buf.WriteByte(hex[c>>4])
+		enc.buf.WriteByte(hex[c&0xf])
+	}
+	enc.buf.WriteByte('"')
+}
+
+func (enc *encoder) writeList(l dynamic.List) error {
+	enc.buf.WriteByte('[')
+	for i := 0; i < l.Len(); i++ {
+		if i > 0 {
+			enc.buf.WriteString(", ")
+		}
+		v, err := l.At(i)
+		if err != nil {
+			return err
+		}
+		if err := enc.writeValue(v); err != nil {
+			return err
+		}
+	}
+	enc.buf.WriteByte(']')
+	return nil
+}
+
+// isSet reports whether f should be written: it must be the active
+// union member, if it is in a union, and either hold a value other than
+// its default or be a union member other than the first.
+func isSet(s dynamic.Struct, f schema.Field) (bool, error) {
+	dv := f.DiscriminantValue()
+	if dv != schema.Field_noDiscriminant {
+		off := capnp.DataOffset(s.Node().StructGroup().DiscriminantOffset() * 2)
+		switch which := s.Struct.Uint16(off); {
+		case which != dv:
+			return false, nil
+		case which != 0:
+			return true, nil
+		}
+	}
+	switch f.Which() {
+	case schema.Field_Which_group:
+		name, err := f.Name()
+		if err != nil {
+			return false, err
+		}
+		g, err := s.Get(name)
+		if err != nil {
+			return false, err
+		}
+		return groupIsSet(g.(dynamic.Struct))
+	case schema.Field_Which_slot:
+		typ, err := f.Slot().Type()
+		if err != nil {
+			return false, err
+		}
+		off := f.Slot().Offset()
+		switch typ.Which() {
+		case schema.Type_Which_void:
+			return false, nil
+		case schema.Type_Which_bool:
+			return s.Struct.Bit(capnp.BitOffset(off)), nil
+		case schema.Type_Which_int8, schema.Type_Which_uint8:
+			return s.Struct.Uint8(capnp.DataOffset(off)) != 0, nil
+		case schema.Type_Which_int16, schema.Type_Which_uint16, schema.Type_Which_enum:
+			return s.Struct.Uint16(capnp.DataOffset(off*2)) != 0, nil
+		case schema.Type_Which_int32, schema.Type_Which_uint32, schema.Type_Which_float32:
+			return s.Struct.Uint32(capnp.DataOffset(off*4)) != 0, nil
+		case schema.Type_Which_int64, schema.Type_Which_uint64, schema.Type_Which_float64:
+			return s.Struct.Uint64(capnp.DataOffset(off*8)) != 0, nil
+		default:
+			p, err := s.Struct.Pointer(uint16(off))
+			return capnp.IsValid(p), err
+		}
+	}
+	return false, nil
+}
+
+// groupIsSet reports whether any of a group's fields should be written.
+func groupIsSet(g dynamic.Struct) (bool, error) {
+	fields, err := g.Fields()
+	if err != nil {
+		return false, err
+	}
+	for _, f := range fields {
+		if ok, err := isSet(g, f); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
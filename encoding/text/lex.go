@@ -0,0 +1,279 @@
+package text
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokData
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokEquals
+	tokComma
+)
+
+var tokenKindNames = [...]string{
+	tokEOF:      "end of input",
+	tokIdent:    "identifier",
+	tokNumber:   "number",
+	tokString:   "string",
+	tokData:     "data",
+	tokLParen:   "(",
+	tokRParen:   ")",
+	tokLBracket: "[",
+	tokRBracket: "]",
+	tokEquals:   "=",
+	tokComma:    ",",
+}
+
+func (k tokenKind) String() string {
+	return tokenKindNames[k]
+}
+
+// A token is a lexical token.  For strings and data, text holds the
+// decoded bytes.
+type token struct {
+	kind tokenKind
+	text string
+	off  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokIdent, tokNumber:
+		return fmt.Sprintf("%v %s", t.kind, t.text)
+	default:
+		return t.kind.String()
+	}
+}
+
+// A position is a line and column in the input, starting at 1.
+type position struct {
+	line, col int
+}
+
+// positionOf returns the position of the byte at offset off in s.
+func positionOf(s string, off int) position {
+	p := position{line: 1, col: 1}
+	for i := 0; i < off && i < len(s); i++ {
+		if s[i] == '\n' {
+			p.line++
+			p.col = 1
+		} else {
+			p.col++
+		}
+	}
+	return p
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d", p.line, p.col)
+}
+
+type lexer struct {
+	s   string
+	off int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	start := l.off
+	if l.off >= len(l.s) {
+		return token{kind: tokEOF, off: start}, nil
+	}
+	c := l.s[l.off]
+	if k, ok := punctuation[c]; ok {
+		l.off++
+		return token{kind: k, off: start}, nil
+	}
+	switch {
+	case c == '"':
+		s, err := l.readString()
+		if err != nil {
+			return token{}, &syntaxError{off: start, msg: err.Error()}
+		}
+		return token{kind: tokString, text: s, off: start}, nil
+	case strings.HasPrefix(l.s[l.off:], `0x"`):
+		l.off += 2
+		b, err := l.readData()
+		if err != nil {
+			return token{}, &syntaxError{off: start, msg: err.Error()}
+		}
+		return token{kind: tokData, text: b, off: start}, nil
+	case isDigit(c) || c == '-' || c == '.':
+		return token{kind: tokNumber, text: l.readNumber(), off: start}, nil
+	case isIdentStart(c):
+		i := l.off
+		for l.off < len(l.s) && isIdentChar(l.s[l.off]) {
+			l.off++
+		}
+		return token{kind: tokIdent, text: l.s[i:l.off], off: start}, nil
+	default:
+		return token{}, &syntaxError{off: start, msg: fmt.Sprintf("unexpected character %q", c)}
+	}
+}
+
+var punctuation = map[byte]tokenKind{
+	'(': tokLParen,
+	')': tokRParen,
+	'[': tokLBracket,
+	']': tokRBracket,
+	'=': tokEquals,
+	',': tokComma,
+}
+
+func (l *lexer) skipSpace() {
+	for l.off < len(l.s) {
+		switch l.s[l.off] {
+		case ' ', '\t', '\n', '\r':
+			l.off++
+		default:
+			return
+		}
+	}
+}
+
+// readNumber reads a number, which may be negative, hexadecimal, or
+// have an exponent.  "-inf" and "-nan" are read as numbers.
+func (l *lexer) readNumber() string {
+	i := l.off
+	if l.s[l.off] == '-' {
+		l.off++
+	}
+	hex := strings.HasPrefix(l.s[l.off:], "0x") || strings.HasPrefix(l.s[l.off:], "0X")
+	for l.off < len(l.s) {
+		c := l.s[l.off]
+		switch {
+		case isIdentChar(c) || c == '.':
+			l.off++
+		case (c == '+' || c == '-') && !hex && (l.s[l.off-1] == 'e' || l.s[l.off-1] == 'E'):
+			l.off++
+		default:
+			return l.s[i:l.off]
+		}
+	}
+	return l.s[i:l.off]
+}
+
+// readString reads a double-quoted string with C escapes.
+func (l *lexer) readString() (string, error) {
+	l.off++ // opening quote
+	var buf []byte
+	for {
+		if l.off >= len(l.s) || l.s[l.off] == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := l.s[l.off]
+		l.off++
+		switch c {
+		case '"':
+			return string(buf), nil
+		case '\\':
+			b, err := l.readEscape()
+			if err != nil {
+				return "", err
+			}
+			buf = append(buf, b)
+		default:
+			buf = append(buf, c)
+		}
+	}
+}
+
+func (l *lexer) readEscape() (byte, error) {
+	if l.off >= len(l.s) {
+		return 0, fmt.Errorf("unterminated string")
+	}
+	c := l.s[l.off]
+	l.off++
+	switch c {
+	case 'a':
+		return '\a', nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'v':
+		return '\v', nil
+	case '\\', '\'', '"', '?':
+		return c, nil
+	case 'x':
+		i := l.off
+		for l.off < len(l.s) && l.off < i+2 && isHexDigit(l.s[l.off]) {
+			l.off++
+		}
+		if i == l.off {
+			return 0, fmt.Errorf("invalid \\x escape")
+		}
+		x, _ := strconv.ParseUint(l.s[i:l.off], 16, 8)
+		return byte(x), nil
+	default:
+		if c < '0' || c > '7' {
+			return 0, fmt.Errorf("unknown escape \\%c", c)
+		}
+		i := l.off - 1
+		for l.off < len(l.s) && l.off < i+3 && l.s[l.off] >= '0' && l.s[l.off] <= '7' {
+			l.off++
+		}
+		x, err := strconv.ParseUint(l.s[i:l.off], 8, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid octal escape \\%s", l.s[i:l.off])
+		}
+		return byte(x), nil
+	}
+}
+
+// readData reads the quoted part of a data literal: pairs of hex digits
+// separated by optional whitespace.
+func (l *lexer) readData() (string, error) {
+	l.off++ // opening quote
+	var buf []byte
+	for {
+		l.skipSpace()
+		if l.off >= len(l.s) {
+			return "", fmt.Errorf("unterminated data literal")
+		}
+		if l.s[l.off] == '"' {
+			l.off++
+			return string(buf), nil
+		}
+		if l.off+1 >= len(l.s) || !isHexDigit(l.s[l.off]) || !isHexDigit(l.s[l.off+1]) {
+			return "", fmt.Errorf("data literal must be pairs of hex digits")
+		}
+		x, _ := strconv.ParseUint(l.s[l.off:l.off+2], 16, 8)
+		buf = append(buf, byte(x))
+		l.off += 2
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,69 @@
+// Package text reads and writes Cap'n Proto structs in the text format
+// that the capnp tool prints, like
+//
+//	(name = "ball", color = blue, tags = ["a", "b"], circle = 1.5)
+//
+// A struct is written as a parenthesized list of its fields.  Fields
+// that are unset or hold their default value are omitted, and of a
+// union only the active member is written.  Enums are written by name,
+// lists in square brackets, and Data as a byte literal like 0x"01 ff".
+// The decoder also accepts Data as a quoted string, as older versions
+// of the capnp tool print it, and ignores whitespace between tokens.
+//
+// The schema nodes for the types are found with the dynamic package.
+package text
+
+import (
+	"bytes"
+	"fmt"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/dynamic"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// Marshal returns the text format of s, whose type is the struct with
+// the given ID in the default schema registry.
+func Marshal(typeID uint64, s capnp.Struct) (string, error) {
+	ds, err := dynamic.New(s, typeID, nil)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, ds); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Unmarshal parses the text format of a struct whose type has the given
+// ID in the default schema registry.  The struct is the root of a new
+// message.
+func Unmarshal(typeID uint64, text string) (capnp.Struct, error) {
+	n, err := dynamic.Registry().Find(typeID)
+	if err != nil {
+		return capnp.Struct{}, err
+	}
+	if n.Which() != schema.Node_Which_structGroup {
+		return capnp.Struct{}, fmt.Errorf("text: node %#x is not a struct", typeID)
+	}
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		return capnp.Struct{}, err
+	}
+	st, err := capnp.NewRootStruct(seg, capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructGroup().DataWordCount()) * 8,
+		PointerCount: n.StructGroup().PointerCount(),
+	})
+	if err != nil {
+		return capnp.Struct{}, err
+	}
+	ds, err := dynamic.FromNode(st, n, nil)
+	if err != nil {
+		return capnp.Struct{}, err
+	}
+	if err := Decode(ds, text); err != nil {
+		return capnp.Struct{}, err
+	}
+	return st, nil
+}
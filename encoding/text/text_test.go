@@ -0,0 +1,159 @@
+package text
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/dynamic"
+	"zombiezen.com/go/capnproto/internal/schematest"
+)
+
+const (
+	colorID      = schematest.ColorID
+	shapeID      = schematest.ShapeID
+	everythingID = schematest.EverythingID
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		typeID uint64
+		text   string
+	}{
+		{shapeID, `()`},
+		{shapeID, `(name = "ball", color = blue, circle = 1.5)`},
+		{shapeID, `(color = red, square = (x = 3, y = 0))`},
+		{shapeID, `(square = ())`},
+		{shapeID, `(position = (x = -0.25, label = "top"))`},
+		{shapeID, `(position = ())`},
+		{shapeID, `(none = void, tags = ["a", "", "say \"hi\"\n"])`},
+		{shapeID, `(points = [(x = 1), (y = 2), ()])`},
+		{shapeID, `(name = "\x00\x7f\xc3\xa9")`},
+		{everythingID, `(b = true, i8 = -128, u16 = 65535, i64 = -9223372036854775808, f32 = inf, f64 = -1e-10)`},
+		{everythingID, `(f32 = -inf, f64 = nan)`},
+		{everythingID, `(data = 0x"00 01 ff", colors = [blue, red, 7])`},
+		{everythingID, `(data = 0x"", matrix = [[1, 2], [], [-3]], blobs = [0x"ab", 0x""])`},
+	}
+	for _, test := range tests {
+		s, err := Unmarshal(test.typeID, test.text)
+		if err != nil {
+			t.Errorf("Unmarshal(%#x, %q): %v", test.typeID, test.text, err)
+			continue
+		}
+		out, err := Marshal(test.typeID, s)
+		if err != nil {
+			t.Errorf("Marshal(Unmarshal(%#x, %q)): %v", test.typeID, test.text, err)
+			continue
+		}
+		if out != test.text {
+			t.Errorf("Marshal(Unmarshal(%#x, %q)) = %q", test.typeID, test.text, out)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := schematest.NewRootShape(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := sh.Struct
+	s, err := dynamic.New(st, shapeID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("color", "green"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("circle", 0.0); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := s.InitList("tags", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tags.Set(0, "x"); err != nil {
+		t.Fatal(err)
+	}
+	const want = `(tags = ["x"])`
+	if out, err := Marshal(shapeID, st); err != nil || out != want {
+		t.Errorf("Marshal = %q, %v; want %q, <nil> (defaults and first union member omitted)", out, err, want)
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, s); err != nil || buf.String() != want {
+		t.Errorf("Encode wrote %q, %v; want %q, <nil>", buf.String(), err, want)
+	}
+}
+
+func TestUnmarshalSyntax(t *testing.T) {
+	tests := []struct {
+		typeID uint64
+		in     string
+		want   string
+	}{
+		{shapeID, " ( name=\"a\" ,\n\tcolor = 2 , ) ", `(name = "a", color = blue)`},
+		{shapeID, `(tags = ["a",], square = (x = 0x10))`, `(square = (x = 16), tags = ["a"])`},
+		{shapeID, `(circle = 2, circle = 3)`, `(circle = 3)`},
+		{shapeID, `(name = "\101\t\?")`, `(name = "A\t?")`},
+		{everythingID, `(data = "ab\x00", f64 = 0x10, f32 = 1.5e+2)`, `(f32 = 150, f64 = 16, data = 0x"61 62 00")`},
+		{everythingID, "(data = 0x\"0A0b \n 0c\")", `(data = 0x"0a 0b 0c")`},
+	}
+	for _, test := range tests {
+		s, err := Unmarshal(test.typeID, test.in)
+		if err != nil {
+			t.Errorf("Unmarshal(%#x, %q): %v", test.typeID, test.in, err)
+			continue
+		}
+		if out, err := Marshal(test.typeID, s); err != nil || out != test.want {
+			t.Errorf("Marshal(Unmarshal(%#x, %q)) = %q, %v; want %q, <nil>", test.typeID, test.in, out, err, test.want)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		typeID uint64
+		in     string
+		errPos string // position in the syntax error, or "" for other errors
+	}{
+		{shapeID, ``, "1:1"},
+		{shapeID, `(`, "1:2"},
+		{shapeID, `(name "a")`, "1:7"},
+		{shapeID, `(name = "a"`, "1:12"},
+		{shapeID, `(name = "a") x`, "1:14"},
+		{shapeID, "(\n  nope = 1)", "2:10"},
+		{shapeID, `(name = 1)`, "1:9"},
+		{shapeID, `(name = "a\q")`, "1:9"},
+		{shapeID, `(name = "a`, "1:9"},
+		{shapeID, `(color = purple)`, ""},
+		{shapeID, `(square = [1])`, "1:11"},
+		{shapeID, `(tags = "a")`, "1:9"},
+		{shapeID, `(tags = [1])`, "1:10"},
+		{shapeID, `(position = 1)`, "1:13"},
+		{shapeID, `(none = 1)`, "1:9"},
+		{shapeID, `(name = @)`, "1:9"},
+		{everythingID, `(i8 = 128)`, "1:7"},
+		{everythingID, `(u16 = -1)`, "1:8"},
+		{everythingID, `(b = 1)`, "1:6"},
+		{everythingID, `(f64 = true)`, "1:8"},
+		{everythingID, `(data = 0x"abc")`, "1:9"},
+		{everythingID, `(matrix = [1])`, "1:12"},
+	}
+	for _, test := range tests {
+		_, err := Unmarshal(test.typeID, test.in)
+		if err == nil {
+			t.Errorf("Unmarshal(%#x, %q) succeeded; want error", test.typeID, test.in)
+			continue
+		}
+		if test.errPos != "" && !strings.HasPrefix(err.Error(), "text: "+test.errPos+": ") {
+			t.Errorf("Unmarshal(%#x, %q) error = %q; want position %s", test.typeID, test.in, err, test.errPos)
+		}
+	}
+	if _, err := Unmarshal(colorID, `()`); err == nil {
+		t.Error("Unmarshal with enum ID succeeded; want error")
+	}
+}
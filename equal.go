@@ -0,0 +1,137 @@
+package capnp
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Equal reports whether p1 and p2 are equal.  The pointers may come
+// from different messages and have different layouts.
+//
+// Equality is defined to be:
+//
+//   - Two structs are equal iff all of their fields are equal.  If one
+//     struct has a larger data or pointer section than the other, the
+//     extra data must be zero and the extra pointers must be null.
+//   - Two lists are equal iff they have the same length and their
+//     corresponding elements are equal.  If one list is a list of
+//     primitives and the other is a list of structs, then the list of
+//     primitives is treated as if it was a list of structs with the
+//     element value as the sole field.
+//   - Two interfaces are equal iff they refer to the same capability
+//     table index in the same message or to the same client.
+//   - Two null pointers are equal.
+//   - All other combinations of things are not equal.
+//
+// Pointers are followed with the depth and traversal limits of their
+// messages, so Equal returns an error if either limit is reached.
+func Equal(p1, p2 Pointer) (bool, error) {
+	if !IsValid(p1) || !IsValid(p2) {
+		return !IsValid(p1) && !IsValid(p2), nil
+	}
+	switch x := p1.underlying().(type) {
+	case Struct:
+		y, ok := p2.underlying().(Struct)
+		if !ok {
+			return false, nil
+		}
+		return equalStruct(x, y)
+	case List:
+		y, ok := p2.underlying().(List)
+		if !ok {
+			return false, nil
+		}
+		return equalList(x, y)
+	case Interface:
+		y, ok := p2.underlying().(Interface)
+		if !ok {
+			return false, nil
+		}
+		return equalInterface(x, y), nil
+	default:
+		panic("unreachable")
+	}
+}
+
+func equalStruct(x, y Struct) (bool, error) {
+	xdata := x.seg.slice(x.off, x.size.DataSize)
+	ydata := y.seg.slice(y.off, y.size.DataSize)
+	if len(xdata) > len(ydata) {
+		xdata, ydata = ydata, xdata
+	}
+	if !bytes.Equal(xdata, ydata[:len(xdata)]) || !isZero(ydata[len(xdata):]) {
+		return false, nil
+	}
+	n := x.size.PointerCount
+	if y.size.PointerCount > n {
+		n = y.size.PointerCount
+	}
+	for i := uint16(0); i < n; i++ {
+		px, err := x.Pointer(i)
+		if err != nil {
+			return false, err
+		}
+		py, err := y.Pointer(i)
+		if err != nil {
+			return false, err
+		}
+		if eq, err := Equal(px, py); err != nil || !eq {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func equalList(x, y List) (bool, error) {
+	if x.length != y.length {
+		return false, nil
+	}
+	if x.length == 0 {
+		return true, nil
+	}
+	xbits, ybits := x.flags&isBitList != 0, y.flags&isBitList != 0
+	if xbits || ybits {
+		// Bit lists can't be upgraded to struct lists.
+		if !xbits || !ybits {
+			return false, nil
+		}
+		for i := 0; i < x.Len(); i++ {
+			if (BitList{x}).At(i) != (BitList{y}).At(i) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	if x.size == y.size && x.size.PointerCount == 0 {
+		// Fast path: same layout of data-only elements.
+		sz := x.size.totalSize().times(x.length)
+		return bytes.Equal(x.seg.slice(x.off, sz), y.seg.slice(y.off, sz)), nil
+	}
+	for i := 0; i < x.Len(); i++ {
+		if eq, err := equalStruct(x.Struct(i), y.Struct(i)); err != nil || !eq {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func equalInterface(x, y Interface) bool {
+	if x.seg.msg == y.seg.msg && x.cap == y.cap {
+		return true
+	}
+	cx, cy := x.Client(), y.Client()
+	if cx == nil || cy == nil {
+		return false
+	}
+	// Comparing clients whose dynamic type isn't comparable would panic.
+	return reflect.TypeOf(cx) == reflect.TypeOf(cy) && reflect.TypeOf(cx).Comparable() && cx == cy
+}
+
+func isZero(b []byte) bool {
+	for _, bb := range b {
+		if bb != 0 {
+			return false
+		}
+	}
+	return true
+}
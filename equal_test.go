@@ -0,0 +1,175 @@
+package capnp
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	type equalTest struct {
+		name   string
+		p1, p2 Pointer
+		equal  bool
+	}
+	var tests []equalTest
+
+	_, seg, _ := NewMessage(SingleSegment(nil))
+	_, farSeg, _ := NewMessage(MultiSegment([][]byte{make([]byte, 0, 8)}))
+	newStruct := func(seg *Segment, sz ObjectSize, text string) Struct {
+		s, err := NewStruct(seg, sz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sz.DataSize > 0 {
+			s.SetUint32(0, 42)
+		}
+		if text != "" {
+			p, err := NewText(seg, text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SetPointer(0, p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return s
+	}
+
+	tests = append(tests,
+		equalTest{name: "null and null", equal: true},
+		equalTest{
+			name: "null and struct",
+			p2:   newStruct(seg, ObjectSize{DataSize: 8}, ""),
+		},
+		equalTest{
+			name:  "same struct in different messages",
+			p1:    newStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1}, "hi"),
+			p2:    newStruct(farSeg, ObjectSize{DataSize: 8, PointerCount: 1}, "hi"),
+			equal: true,
+		},
+		equalTest{
+			name:  "structs of different sizes",
+			p1:    newStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1}, "hi"),
+			p2:    newStruct(seg, ObjectSize{DataSize: 24, PointerCount: 3}, "hi"),
+			equal: true,
+		},
+		equalTest{
+			name: "structs with different text",
+			p1:   newStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1}, "hi"),
+			p2:   newStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1}, "bye"),
+		},
+	)
+	{
+		s := newStruct(seg, ObjectSize{DataSize: 16, PointerCount: 1}, "hi")
+		s.SetUint8(12, 1)
+		tests = append(tests, equalTest{
+			name: "non-zero data past end of other struct",
+			p1:   newStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1}, "hi"),
+			p2:   s,
+		})
+	}
+	{
+		s := newStruct(seg, ObjectSize{DataSize: 8, PointerCount: 2}, "hi")
+		s.SetPointer(1, newStruct(seg, ObjectSize{DataSize: 8}, ""))
+		tests = append(tests, equalTest{
+			name: "non-null pointer past end of other struct",
+			p1:   newStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1}, "hi"),
+			p2:   s,
+		})
+	}
+	{
+		prim, _ := NewUInt32List(seg, 2)
+		prim.Set(0, 7)
+		prim.Set(1, 9)
+		comp, _ := NewCompositeList(farSeg, ObjectSize{DataSize: 8, PointerCount: 1}, 2)
+		comp.Struct(0).SetUint32(0, 7)
+		comp.Struct(1).SetUint32(0, 9)
+		short, _ := NewUInt32List(seg, 1)
+		short.Set(0, 7)
+		tests = append(tests,
+			equalTest{
+				name:  "primitive list and struct list",
+				p1:    prim,
+				p2:    comp,
+				equal: true,
+			},
+			equalTest{
+				name: "lists of different lengths",
+				p1:   prim,
+				p2:   short,
+			},
+		)
+	}
+	{
+		b1, _ := NewBitList(seg, 10)
+		b1.Set(9, true)
+		b2, _ := NewBitList(farSeg, 10)
+		b2.Set(9, true)
+		b3, _ := NewBitList(seg, 10)
+		b3.Set(8, true)
+		bytes, _ := NewUInt8List(seg, 10)
+		tests = append(tests,
+			equalTest{name: "equal bit lists", p1: b1, p2: b2, equal: true},
+			equalTest{name: "different bit lists", p1: b1, p2: b3},
+			equalTest{name: "bit list and byte list", p1: b1, p2: bytes},
+		)
+	}
+	{
+		msg := seg.Message()
+		id := msg.AddCap(ErrorClient(errReadOnlyArena))
+		other := farSeg.Message()
+		otherID := other.AddCap(msg.CapTable[id])
+		tests = append(tests,
+			equalTest{
+				name:  "same capability",
+				p1:    NewInterface(seg, id),
+				p2:    NewInterface(seg, id),
+				equal: true,
+			},
+			equalTest{
+				name:  "same client in different messages",
+				p1:    NewInterface(seg, id),
+				p2:    NewInterface(farSeg, otherID),
+				equal: true,
+			},
+			equalTest{
+				name: "unpopulated capability tables",
+				p1:   NewInterface(seg, id+1),
+				p2:   NewInterface(farSeg, otherID+1),
+			},
+			equalTest{
+				name: "interface and struct",
+				p1:   NewInterface(seg, id),
+				p2:   newStruct(seg, ObjectSize{}, ""),
+			},
+		)
+	}
+
+	for _, test := range tests {
+		for _, swap := range []bool{false, true} {
+			p1, p2 := test.p1, test.p2
+			if swap {
+				p1, p2 = p2, p1
+			}
+			eq, err := Equal(p1, p2)
+			if err != nil {
+				t.Errorf("%s (swap=%t): Equal error: %v", test.name, swap, err)
+				continue
+			}
+			if eq != test.equal {
+				t.Errorf("%s (swap=%t): Equal = %t; want %t", test.name, swap, eq, test.equal)
+			}
+		}
+	}
+}
+
+func TestEqualDepthLimit(t *testing.T) {
+	// The root struct has a single pointer that points to itself.
+	data := rawWords(
+		rawStructPointer(0, ObjectSize{PointerCount: 1}),
+		rawStructPointer(-1, ObjectSize{PointerCount: 1}),
+	)
+	root := MustUnmarshalRoot(append([]byte{0, 0, 0, 0, 2, 0, 0, 0}, data...))
+	if _, err := Equal(root, root); err != ErrDepthLimit {
+		t.Errorf("Equal on cyclic message error = %v; want %v", err, ErrDepthLimit)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	math "math"
 	strconv "strconv"
 	capnp "zombiezen.com/go/capnproto"
+	schemas "zombiezen.com/go/capnproto/schemas"
 	server "zombiezen.com/go/capnproto/server"
 )
 
@@ -4724,6 +4725,134 @@ func (p CallSequence_getNumber_Results_Promise) Struct() (CallSequence_getNumber
 	return CallSequence_getNumber_Results{s}, err
 }
 
+const schema_832bcc6686a26d56 = "x\xda\xdc\\{t\x14U\x9a\xffU\xa7\x1b\x1a\x13B\x82\xf8\x00\x12 O\xc19Hx\f #G\xc9$\xa3\xcc\x01\r\x8f\x88\xb02RI*\xa4\xb1\xd3\x1d\xbb\xab\x81\xe0p\x94\x19\x18\xd1#\xab\xae\xb8\xca(\xbb\xc0" +
+	"\xe2YY\t\v\x8e\xceAW\x19\x99\x85\x15\x10vt\x0f(\xb0\xbcčn\x10v\xc4\xd1\x15\x9f\xd9ө\xefVݺ]\xd5U\xdd\x1d\x81\xb3\xf7\x9f\xe4\xd6\xed\xdf\xef~\xf7\xbb\xf7\xfb\xbe{o\xdd[\x00\xb07\x1b" +
+	"\x00\x00\xc0\x03\x1f\x80\xc7\xf2\xa1\xa7\xfd\x00|\xe8\x81;\x9b7\xfc\xa6q\xff\x8f~\xdd\v\xc9S\x9b\x1fX\x02`\x9b\x1f\xf8\xd8\x03\xb4f\x03\xe3\x92\xfc\xfe\xdeC;6\xb6\r\xb8\xef\xe3>\x00\xbc\x80^\x8f\xf8\xbbm\xd9" +
+	"\xc0>\x00od\x03=a\xa4\xed\xd9@\x16$\xec\xca\x06<\x90\xc0R\xce\xc1\xbf\xdd\xd5\xf7Ŋ\xd5N\xbc\xaf\x12\xefn\x81\xf7M\xe2=&\xf0\xee\xa9=\xed{\xed\xbaǞu\xe2=B\xbc\xed\x02\xef)\xe2=+" +
+	"\xf0\xae\x9d9\xab\xed_\xb6Ԝ\xe8\x03@\x82\xc4\xf1\xf2h\xe0x6\xf0O\x16\xbc\x1fd\x03\xff\x9c\xa8^l\xc8\xfel\xc3W\xcaѷ4^C^I\xe0m\xcd\xd1x\x97\xe5\x98y\x97\xe6\x007'Ңh\xc4" +
+	"\x85\x81߮\x98\xdb\xde\a\x80'\x89\x1e\xd6\xe6\x00\xaf\x01x^\xe0]\x9fc-\xef\x13\xe3\xfeq^\xe8O\xaf\x1f\xd6\xe4\xf5\xea\xbc\x1e\x13\x1a\xf8\"Gӯ\xd4\xdb\xcc\xfb]\x0e0KJ\xe4=U>~އ/" +
+	"\xfdn\xbf\x93\x1e\xe4\\`\x03\x80@\xae\x99Wɵ\xd6ê\xb7\x8f,Z9\xef\xd1#N\xbc+\x88w\x95\xc0\xfb\xb0\r\xaf<ct\xdf\xddg\xf6~\xe0Ļ)\x17\xf8-\x80m\x02o\x9b\r\xef\x03c\x7f\xb1" +
+	"|\xee\xf8O\xb7i\xbcY:o\x96\xc0{ \x17\xf8\x0f\x00G\x04\xdeC\xb9\xd6\xfa\xdd\xf6V\xed\x89-y\xb7\xb7\x8b\xe3W\x93\xd7\v\x96&\xe5\x01\x7f\x0409\xcf\xcc[\x9d\a|\x9cH\x8b#\xeb\xde\xfb\xc9\xd3\x1d" +
+	"\xe5g4^\x8f\xc0;\x14,\xf5\xcf\a\x1e\x01P\x94o\xe6-\xcc\a\xde\xf2'\xf2\xce};\xef\x17\xbd^\xbf\xed!Q^q\x9c=<P\x1b\xbfO\f4\xf3\xae\x1ah=~w\x1c]y~\xe37c\xdfs\xea" +
+	"\xb7\x1b\v\xb4~\x9bT`\xe6\x9dX`\xddo\xcf\xecڔ\xfdQ~\x95\xe3\xf8\x9d[\xa0ɫ\b\xbc\xb2\r\xef\x95\v\xbf\t4Nz\xf7\xb8\xc8+\xea\xe1\xa9\x02`\x03\x80\xb5\x02\xef\x9a\x02`Q\"-:#\xfb" +
+	"\xcfL[\xb3\xf7I\x91\x17\x02\xaf\xafP\x1b\x0f\xbd\v\xc5\x12\xebtbʎ'\x87\xaa\xff𭓟\xec(\xd0\xc6\xef\x17\x82\xbc\xe7m\xf4\xf0]\xe3\xad{\xab\x8f\xf9\xbe\x14Ǚț_\xa8\xf1\x16\n\xf2\xf6/" +
+	"\xb4\xd6ê\"\xff\x98\vK\xff\xb0ک\xdfb\x85\xc0>\x00\xcb\x04ޥ\x85\xd6\xf2\x0e\x9fz\xe3\x1b\x1f\xbe\xf8W{\x9d\xfam-\xf1n\x12x\x9f\xb7\x917\xbaoL\xe7\xd9Ӄ~/\xeaA\xe4\x95\x06\x01\x1e" +
+	"\t\xe8=\xc8\xcc\xdbk\x90\xb5\x1d?Y\xf1\xc2\xd77\x1d\xfc\xbb\x13Nz8>\b\xb8B\x02\xce\t\xbc\x1d\x83\xac\xf5p~\xe9\xd4\xeb+k\xdfyщ\xb7\xff``\x80\x04\f\x1bl\xe6-\x1bl\xcd\xfbڗ'" +
+	"\x0fݳ\xf0\xd8\xdbN\xbc\xb5ī\b\xbc\xb2\r\xef\x85\xd9\xeb~\xfd\xdc\x06\x7f\xbb\x13\xef\x13\x83\x81\xbe\x12\xb0^\xe0]kû\xe7\xc1\x9d\x05\xfb\xce<\xfb''\xde\x03\xc4{\\\xe0=b\xc3{oh^\xbf\x9b" +
+	"\xbf\xdf\xf1\x94\x13o\xaf!\x1ao\xff!fޫ\x86X\xf3\x1e\xfe\xdd\xf7\x15%7\xff\xebCN\xbc\xd5\xc4[+\xf0N\xb3\xe1\x9d\\s\xfa\xc8\xe9-U\xff\xee\x14ߖ\r\x01\xce\x00X%\xf0><\xc4:\xbe\x1d" +
+	"\xeb\xf1\xd5\xdf/\x7f`YB\xbf\x89\xbc\xedE\xc0>\x00\xe7\x8b̼犬\xe3\xc5\xec͞g\x9f^\xf3\xcao\x9d\xf4\xf0J\xb1ƻ\xb3\xd8\xcc\xfbF\xb1\xb5\x1e\xdav\xf4\xcc?\xf4\xee\x06\xc7\xf9C{1\xf0" +
+	"Z\\>\x81\xb7ÆwvӀ?O\xf8d\xf9GN\xbc\xf9%\xc0Q\x00\x85%\x82\x9f,\xb1杰\xf8\x96\xb1/\x1d(Zi\xed\xd7=\xc6\xfc\xa1D\xd3\xc3T\x81wr\x89\xb5?+\xf0ϭ\xec\xd5v" +
+	"\xc7y'y\x9f'y\xb7\t\xbcm6\xf2.\xb86xk\xbf\xaa\xce/\x1d\xfdY\x89\x16/:\x04\xdev\x1b\xde\xfc\x96\x86\xf7C\xbe\xcdۜx\x87\x95\x02\x9f\x01\xf8q\xa9\x99wd\xa95\uf632\xa1\x1d;\x8b" +
+	"g\xfcu\x1f\x00YI\xe6\xebr)\xcd\x7f\x05^\xa5\x14\xb8\x15\xc0*\xe1\xf9\x86\xbf\f|\xff\xe5{\xae~\xa4\x1f\x00\tFJ\x88o\xa5\x80\xc7®Zm\xe4]{\xff\xf1W\x87\xbd?\xeeY'\xde5\xa5Z\xbc" +
+	"\x10\xd3z\x1b\xdeW?\xd9\x1a\xae>\xfaP\x9b\x93~w\x92\x1e\xf6\n\xed\xddm\xc3\xdbx\xdb\xfcϾ\xbf\xf2\x0f/8\xda[)\xf0G\x00\xe7\x04\xde\x0e\x1b\xde\xc7\xf7\f\xfe\xb7I\xf7oy\xcc)\xce\xf7*\x03N" +
+	"\x03\xb8\xaa\xcc̛_fm\x17\xb1\x9fx\x7f\xd39\xaab\xad\xf5|\x9d\xeb\x9f2`\x1f\x80\x15\x02\xef2\x1b\xde\xdd\x17νWq\x7f\xd9\n\xc7u,\xf1v\b\xbc\xede\xd6z\x18\xb2\xe7\x9a\xc5\xf7\x9c\xdc\xf2\xa2" +
+	"\xd3\xf8\xf5\x95kz\xc8/7\xf3\xf6.\xd7\xc6\xef\x8d\xc2\xf3g\xfa\xf7}\xe4\xf3_=\xf4E\x01\xe9\xd7H=M\xb9\xa2r`\x81\x04\xd7\xe9\xe9A\uf3a8\xf9J٨\xf1J\xb6\xbcq\xb9\"\x16\xbc\xfd\xcbm\xd6" +
+	"\x85\x81H}DnTo\xa8\x97[B-\xc0v\xe2g\xfb(\x01\t\x98\xce\xed\x7f\xb0<۷`y\xb6\xdf\x10Ϗ\xe2\xf6\t\x14ʳ\xf5\xbd,\x01\x95ܺ|.\xe1\xd9z:\x9e\xbf\x9e[\aϦ<[" +
+	"\xbf\xd6J@1\xb7\xee\x9c&\x01s\xb8\xf5b<\xffsn\x9d\x17\xcf\xf7\xe5\xd6gS\xa9~\xb6\xae\x9aL|l=TM\xe5l\x1d3\x89\xeag돉\xc4\xcf\xd6\r\x13\xa9~6\xdfgy6O\x9fH\xedc" +
+	"\xf3k\x96g\xf3≒\xb6\xced\xf3\xd9x}\x7f\xc3\xcdC\xab%\xcdo\xb0\xf9\xe3dʳy\xdfTI[\xf7\xb1\xf9\xda4ʳyV-\xe5\xd9\xfch6\xe5ټ&\xae\xef\b7\x1fa\xfd\xc1\xe6\x11," +
+	"\xcf\xe2\xff\\\xd2\x0f\x8b\xdbq\xbe:.\xdeΦ߳8\xc9\xcaY|\x9bM\xfaaqi\xb6\xa4\xed\xef\xb1x\xc2\xfa\x9b\xf9\xd5Z\xca3\x7f\xc8\xfa\x97\xf9\xb1x~\x01\xe7\x7f\xa6Q\xfd\xcco\xb0<\xb3w\xf6\xfb" +
+	"\xfap(\xaaVɪ\x02\x00,?%\x10U\xf9|u(\xd6\f\x00\x00\xe64h\xbf\x8d\xff\x95\x01L\nDZ\xc2\x11\x155A9\xa4T\xcaQ\xc6S9n\xf48\xc4\xcbG\x8f\xaa\x00\x80\x9f\x8d\x1c\v\x00\x98\xae" +
+	"̏(\xd1h \x1c\x02\xa0\xe1\xbb\xec\x0e\x8c\x1f\x00\x00\xfc4\x1c\v\xa9J\x04\x95\xf2|\xedyT\x89,T\"\x98\xb3 \\\a\x00w*\x91\xea\xe6\x16\xb5\x15\x00(\x7fGH\xa9҄\xd2\xf23\x17\x85M\xf9;" +
+	"BJ\x8d\x1a\x01_.\xe6\xe3\xbf7=\xc6m\xe1`C\x94U\xc6\xe9\x85=\xa7J\xf5\x12\xf6\x9c\xc8\x12\x9ekB\xe8\x8f\xf9\xdf\xdb=\x9f\xb9(l\xf7\xbc&\x18\x8b\xf2\xf2\x1b\x8f\xba~7SYL0̊\xc8-" +
+	"\xbc\xc2\xe2\xf9Q\x8bG\xb1\xbf-\x8c\xe8\xcep\xa0\xa16\xa4w\x0fnW\xa2\xaa\x12\x19\xf9S\xb9E{2}\xd6L%\xaa\xeaY\xc4%\x9b\xa1Fb\xf5\xf4\xac\xba\xbe)\xacɩ6\x01Z\x9e\x1b\x16\x98\xa1\xca\xf5" +
+	"\xf7\x06B\xf3\xa7\x87\xc3*\x9f\x9f$\x94W\xb2q \a\x833\x94\xfbbJ\xa8^\x01\x00?\x00\t\x1e\x9c\xfaQkI\xde\x03\x9bv\xe4\x01\xf0@\xc2\x10\x00\x80\x84W\xaar˥\xdfW|P@\xcfo\x02\x00H" +
+	"ȁ9I\x00\xea8\xbf\x1f\f\xd4\xf1Ŗ\xbf\xffU\xdcF\xc3\xcdu\x01e\x89\x12\xba\xa1>\xdc<b~xDW\xbc\x88\x84\xd5\xf0\x88@|\xc0\x86\xe4\xe0\b+Ns|\x99 \x9a\x1d\x00@\x82\x84<\x00\xe0" +
+	"\xe2\x88 \x06\xf2\xb8\xff\r\x1cp\xb2\xa7\x7f\xa0]=\xc6\xe81\xea\xc9M\x12c\xb3\\ȑk!\xc35z\xff\x18\xf2\x9c\xec\xe9/\xb0\x93\x8bs+\xba\\}\x00\x80\x8b\x93b\xbd}`=Y\x10\xf8\x99\x9b\xd2y" +
+	"s\x00d\xc1+Hl\xa4j\x00\xd7\x1bY\xdcBz\x98\x02\xc0\x03\tR\xd7_$\xcc:&\x03\x18edQE\xb8\x1a\xc2y\x00d\x01\x00\xfd\xcf\xd2T\x00\xc5F\x16\xb7\x11n&\xe1Z\x159\x02\x1dk\x9f\xec\xca" +
+	"\x9bá.\x03\xec\x91\x1cn[\xde \xb7&-w\xc2[\xf4\x87\xcc\xf7\x87ס?z\v\xfd\xe1'\xfd\xf4#\xfd0\xbe\xde\x0e\xf2\xf5v'\x1f\vc\xba|\x05B\x8fM\x15䑸q\xc3\xf7#CL\x14\x9e" +
+	"g\x01\x00\x80\x1f\vϽ\x00\x00`\xb8\xf0\xdc\a\x00\x00ʄ\xe7L߅\x82<\xa1pH\x01\x80\x05\x8d\xf7\x02\x00\x82\xf2b\x00@\xb41\xac\xe5c\v\x01\x00\r\x8d\x8b\x00\x00\xaa\x12U-\xf4 \x86q]\x1f\xd7" +
+	":\xf4\xd7\x1aA\x9e\xd5\xd4_\xeb8\xfb\x91,\xecg\xad`?\xcf\x10\xeee\xce~`a?\xdb\x00L0\xb2\xd8L\xb8\xed\x84\xcb\"\x1b\x06\xfd\xcf\xd2+\x02n+\xe1^'\x9c\x97\xb3s\xbe\xb5\xaf\x02\xf8\xb9\x91\xc5" +
+	"v\xc2\xed\"\x9c\x8f\xb3\xf3\xf8\xff,\xed\x14po\x12n?\xe1Br\xb3b\x15o\xc4dW\xde\x14nV\xa2\x16\xfeX\xf4\xe7N~\xd5\x0e\x1f\x91\xd5@h>\xe0\x13\x9e\x8bɮ\xbc^\x0e\xfd,\xd8\n8-\xf1" +
+	"$[|\x8b\\\x1fP[\x9d\xeaq*o\x96\x17\xcfhQ\x94\x06\x00\x00\x80l\a\x9elw~\x83Ms\xbbͯՑ\xed\xe5\x01\x00\xb7>t3\x0f\xb0\xf2k4\xfd\xbe\\\xe5c˂\xcbU>q\xb9ҭ" +
+	"\xfe\x106\xfep\x80\x85?\xdc\xc8\xf9C\xc9\xc2\x1f\xae\x17\xea{\xce\xc2\x1fzR\xf0\x87\xfb9\x7f(Y\xf8ýB\\\xdaE\xb8w8\x7f\xe8\xb1\xf0\x87\a\x04\xdc\x1e\xc2\x1d\xec\xa6\xfe\xab\xab\xc8̾\xeb\x14U\x86" +
+	"\v\x7f\xea\xc4o\x87o\x89\a\xd8(\\Ͽ\xd9~\x8e[\xfe\xd6\xe6XF\xedo\x8d6t\xa7\x7f4-\xef\xd9\xfaF\xb3\x9b\xceN+\xbb\x91\x85q|7\xe9\xa3I\xb7\x9b\xef;\xad\xecF\x11p\xf3\b\x17\xd4\xed" +
+	"\xe6;\x1d\xc7\xdbM@\xc05\x10\xaeE\xb7\x9bo;aa7\xcd\xc28n\"\x9cJ\xb8\x85\xe1@\x032Hu\x14X\xf2\x00\x80\xdb\x17t\xed\xc7\xc8\xf1\xb3r\xb6\x8f\xe8\x16\xdf8r\xac\xa9\x9c\xed;\xa6\xe9G\xe7" +
+	"@\xf0\x9f\xeb\x1c\xc6\xc14\xaf\xb9_\xa6x\x81,H\xb8˛|\x1c\xd4z\xcd\xfe\xb3\x86pw{\x8dq`\xe5?g{\xcd\xfd9\x93p\xf3\xbc\xc68\xb0\xf2\x9fs\x05\xdc]\x84k\xf02\xff\xf9M\xa7\x95\xff\x94" +
+	"\x05\xdc݄k\xf22\xff\xf9u\xa7\x95\xffT\x04\xdc<\xc2\x05\t\xd7\x03_uz\x01\xa0\xeb\x7f#\x05\x04\\\x03\xe1Z\b\xd7\x13\x17:\xfd@\u009b\xa0fA\x9fM\x84S\t\xe7Ǘz\xfb4<\x00\x00\xf7\t" +
+	"\xf5\x05\t\xb7\x98p\xbd\xf0\xbfz\xfb\xf8\xb3\x8f1\x01\xd7B\xb8_\x12\xee\n|\xa1\xb7\xef\n\xdeo\t8\x95p\x0f\x12.\x1b\x9f\xeb\xed\xe3}\xd7R\xa1}\x8b\t\xb7\x9cp9\xf8K'[G\xf0s\xf0e\xc2\xf8" +
+	"\xfc%\xe1V\x12\xae7>\xd3\xc7'\xbf\x06]!\xe0\x1e$ܣ\x84\xcb\xc5y\x1d\xc7\xfb\xf8\x87\x05\xdcr\xc2=N\xb8>\xf8T\xc7\xf5\x81\x91Vy\xcd\xf1}%\xe16\x12.\x0f\x7f\xd6q\xbc-\xaf\x17p\xcf\x11" +
+	"n;\xe1\xf2\xf1?:.\x9f\x9fO\b\xb8\xad\x84\xdbO\xb8\xbe8\xa7\xe3\xfa\xf2\xf3\t\x01\xb7\x8bp\xa7\bw%\xce\xea\xb8+a\xa4\xe3\x02\xee0\xe1>'\\?|\xa2\xe3\xfa\xc1H\xe7\xbd\xe6u\xe7Y\xc2\xf9}" +
+	"\x1a\xee*\x9c\xd1qW\xc1H>\x9f\xb9>\xf84\\\x01\xe1\xaeF\x87\x8e\xbb\x1aF\xea/\xe0\xfa\x11\xae\x82p\xd7\xe0\xbfu\xdc50\xd2p\x017\x94pU\x84\xbb\x16\x1f\xeb\xb8ka\xa4I>s\xfbn\"\xdc]" +
+	"\x84돏t\\\x7f\xde\x7f\xfa\xcc㬆pA\xc2\r@\xbb\x8e\xe3\xed&\xe0\x03*y\xffB\xb8\xc7\t7\x10\xff\xa5\xe3\x06\xf2\xe3S\x90s%\xe1V\x13\xae\x00\x1f\xea\xb8\x02\x18\xe9\t\x01\xf7(\xe1\x9e!\\" +
+	"!N\xeb\xb8B\x18\xe9)\x1f\xc0\xef\xf4\xae&\xdcV\xc2\r\xc2\a:n\x10\x8c\xd4\xe63\xaf\xe37\x13n;\xe1\x06㔎\x1b\xccۃ\xcfx\xa7\x01\x00/\x13\xeeM\x1f۷>\xa9\xe3\x86\xc0Ho\xf8\x80\xe9" +
+	"F\x16\xaf\x13n\x0f\xe1\x8apB\xf7\x83E0\xd2n\xa1\x1f\xde$\xdc~\xc2\x15\xe3\xb8^\x1f\xef/\xf7\n\xfd\xbe\x8bp\xef\x10\xae\x04\xc7t\\\t\x8ct@\xc0\xed!\xdcA\u0095\xe2?u\\)\x8c\xf4\xae\xcf\xec" +
+	"\xaf\xf7\x13\xee0\xe1\xcapTǕ\xc1H\x87\x84~8H\xb8O\tW\x8e#:\xae\x1cF:'\xe0\xce\x12\xce\xdfC\xc3]\x87\xc3:\xee:\u07bc{\x98\xf5\x89\x1ed\xef=\xbag\x9e\xb7d\x89y\x1e\xc5\xde\xd7" +
+	"\xba\x9e\xa7\x8d\x1d\x03d0_o\x1c=\n\x00L\xb1\xd4*ٕ\a\xa8\xfet\xf7e\x02T\xbf79ܶ<@\xf3\xd4t\xf7\xc9\x03\xe3\x01\x00\xf0$\x87ۖǨ\xfdN\xf77\xec\xcac\xd4~\x7fr\xb8m" +
+	"y\x8c\xda\xeft\xc6\u05ee<6^\x1f\xd6I\x93]y]8\x1c\x04\xd2\xdf\xdfS\xe9\x15e\xba\xfb\x9fuA\xed}p\xba\xef\x01\x1aǎY\xa8\xd4\xffp\xeb\xfd\xc6ѣ\\\xf1_\x91&\x7f\xc0\xa5\xfc\xbet\xf9" +
+	"]\xca\xefM\x97\x7f\xe4XW\xfcY\xe9\xf2\x8f\xef\xa2w\xe4\xf7\xa4\xc9\x1fs\xa9\xff^\xe9\xf2\xbbԿ?]~\x97\xfa\xef\x99.\xbfK\xfd\xf7H\x93\x7f\x89F\xefz?\xcd.\xbe&\xe3\x8fW\xe1\xc4\xff\x83\xd5O" +
+	"/\xb2\xd39\x17\xc0\xf02W\xceΣ\xa5\xba\x8fCj\xfe\xc1\xf6-Y=\xa2<v<v\xf2F\x84\r{\xf6;vN\xce-O\xd7>m\x9d\xf1\"4\x83\xf7\t]/\x96\x9d\xcf5\xfc\xff\xdc\a\xec\x1a\xbfl" +
+	"\xf0\xa40~ܞ;1\x8d\xf34걳\x87\xdc$\xf3\x1d7\xfe@Jӟ\t\xfb\xa6\xec\x18\x1cЍ\xe7X\f\xb6\xd4αX\xed\x9bN\x15\xdeoO\x11\xf6룁%\n2X\x1f,\nG\x1a\xa2\x19" +
+	"\xcc\x0f\xe3\xf8 w\xfeɩ\xdfr\xba\xa7\xdfرE]\xd3n\xdek\xf2\xebL\xf1\xbdf=\r\x84<\x00\xe0\xce\xf1\xa6\xea\xc7\xd9~<\x1d\xa7LI\xbe:A\xdfY\x900\x94\xe4[$\a\xe2\a\x05\x16\x84\xeb" +
+	"\xa2)\xd9\x1f;_\x9c\xa6\x9e\xd9qP\xbd\x1d~\x87v\f\x13\xde۔\x92<\x15\x0e\xe7T\x86\vv5\x94pU\xac\x7f\x9a\x1b\x92\x8eC\xa7q*G\xe6G/\xe2\xf84\x1d\x9f\x95 Y\x94\x9b\x8eӦ4N" +
+	"\x8a\x93\x8c\xe3\x85r\x10\xc8`\xbd\x9e(\xa7\xe9\x98\xefE\x19\a\xc5\x16\xe3`L7\xb5\xaf!\x16F&\xfeҲ\x1f\xb9\xf3\xcc\xddՏ-D\x99\a\x00\xdc=\x804\xfd\x91x\x1c;\xa5~\xbc\xbe\x1b\xedy\x8c\xd1" +
+	"\xbe\x91\x99\xb4\xafE\x8d\x8c\xeav\xfd\b\xc7\xd3\xcd\xefӭ\xf5#\v\xfd\x98\xf8>\xddZ?\x8a\x80K|\x9f\xceR\xaa\xefӍ\xfa\xc4\xf7\xe9<.\xe1}\xfa%\xb6\xab\xcbl<\xd8]KHɾ'X\xd8w" +
+	")黹U\x9b5\xb9\x8d\xe3\xec^P\x9a\xf1\xc8\xee:\xc5%k\x8f]\xff\xa4\xd8\x1e\xf1\x1a\xc8%k\x0f\xbb\x97\x95y\xff\x98\xae\xa9\\\xb2\xf6\xb0{e\x99\xf7\xcf\xe5\xd1\x1ev/.\xf3\xf6\x98\xae\v]\xb2\xf6" +
+	"\xb0{}\xdd\xd2\x1e\xe3\x9a\xd3%k\x0f\xbb'\x98f{,\x9b\xe2\xf6\x1c)\x1f\x87S9W_\xecp\x8e\x14)\x9c#}\xc1!~?/\xe0\xd6\x11n\xb3ù\xfaM\x82\x9c\x1b\t\xb7\xd5\xe1\x1ci\x9bP\xdf\v" +
+	"\xc2y\xfc\xcbm\xbe`g\x0fn\xe7\v\xa9\xe2Ո\x92\x91\xfc\xc1\xa8:\x1a\xf8\xe1\xde+Y\xd9;w]\x11Ha\xbf\xab\xd8a\xbfK\xb2\xd9\xef\xe2ql\xbfk\x1eg\x1f\x1e\v\xfb\x98+\xf8\x95\xbb\b\xb7\x9cp" +
+	"*5!\xdd}\x80`TM\xaa7d\xb8\x0f\x10\x8c\xaaA7~\xef\"\xedC\x88\xd7RS\xf2\xef/\tv\xd0u.\x83\xf9\xf7\xc0\xfc&\xf5\xf6\xb0Z\xa9LW\xe4`\xb0\x95U\xe24_\xcds/w\xfc\xfa\xec" +
+	"E\x937U\xfb\xb7\x96\xb7\xc5\x14H/\x86\xdcvq\xd3\xed\xbaW\xb8\x8e\xac\xcb\xedw8?<\f0\x9d/L\xdc\x17\xb0>?<\\\xc0\x89\xfb\x0222Ku\x99\xc1E\xfd\x88׳S\xeaW\xab{4l~" +
+	"\x14U#\x17s?R\xbcV\x9eR;\xe6\b~5\v\x12Ƴ\xfbuJT\x9d*\xab\x91\xc0b\xb8\xf2\xabn\xe7\x83\xec\xbb\x0ei\xb6W\xbc6\x9fR{\x8b\x93\xf4[\xaa\xefi3l\a\xbb\xee/\xc8\xef\x83\xf8" +
+	"\x1d#\xf6ݡ|a܉\xc9\a\xa0'\x00\x85'\xe6\"\xb8E\xfd7\xc4\x7f[Z#G\xe4\xe6\xa8+\xfd\rH\xb2\xaf\x19\b!\xa3\xf8m+\xdft%\x1a\v\xaaQ\x00\x00\x90\xf1\xfek8\xa6v\xa7\x9c\xecs" +
+	"\r\x19\xfb\x0f\xbb{x\xec\xbb%i\xc6\x01\xd3g$\xbaC>6\xbe\xf2\x01\x80\xfb\xee\x8a(G\xbe;\xf9\xc4\xcfZ$\xc4))a,\x1bqjA\x92x\x03\x9b\xf9\xeb\x8dB\x9c\x1aC\xb8[X\x9c\x9a\x15P\x9b" +
+	"\xaa\x94F9\x16Ty=\xb3\xefø\xe9\a\tR\x82\xad\xca\xc2\xefS\xe1K\xa2\xb7I\xf6\xf1ݺ\x7fݼ\xbfI'\xbe\xb3OR\xa4{\x1e\xb5Nh?\xfb\xfeN\x86\xfa\xa9D\x1aq1\x99\xffȴ\x9d\xe2" +
+	"9\x05\xe13-\xb6\xf1\x80}\x17\x8c}\xc7+\x1f\xe6s\xeeb\xea\x05\xa0'\x80\xf9\x8az{\xac\xb9N\x89\x18E\xc9\xe2\x02/\xcf\r:\x96\x05\t\xc0\xe5\xef\r\xa7\xedF\xdf}\x93\xe9\x9b{\x9e,ٕ\xff\xdf\x00" +
+	"\xdb\xca\xd7-"
+
+func init() {
+	schemas.RegisterCompressed(schema_832bcc6686a26d56,
+		0x832bcc6686a26d56,
+		0x85257b30d6edf8c5,
+		0x8748bc095e10cb5d,
+		0x87c33f2330feb3d8,
+		0x8821cdb23640783a,
+		0x8a165fb4d71bf3a2,
+		0x8e5322c1e9282534,
+		0x8fae7b41c61fc890,
+		0x93c99951eacc72ff,
+		0x9430ab12c496d40c,
+		0x94bf7df83408218d,
+		0x95befe3f14606e6b,
+		0x9ab599979b02ac59,
+		0x9b37d729b9dd7b9d,
+		0x9b8f27ba05e255c8,
+		0x9d3032ff86043b75,
+		0xa465f9502fd11e97,
+		0xa8bf13fef2674866,
+		0xabaedf5f7817c820,
+		0xabd055422a4d7df1,
+		0xad87da456fb0ebb9,
+		0xb1ac056ed7647011,
+		0xb1f0385d845e367f,
+		0xb61ee2ecff34ca73,
+		0xc7da65f9a2f20ba2,
+		0xc95babe3bd394d2d,
+		0xcbdc765fd5dff7ba,
+		0xcc4411e60ba9c498,
+		0xccb3b2e3603826e0,
+		0xce44aee2d9e25049,
+		0xcf9beaca1cc180c8,
+		0xd636fba4f188dabe,
+		0xd8bccf6e60a73791,
+		0xd98c608877d9cb8d,
+		0xddd1416669fb7613,
+		0xde50aebbad57549d,
+		0xde9ed43cfaa83093,
+		0xe1a2d1d51107bead,
+		0xe1c9eac512335361,
+		0xe508a29c83a059f8,
+		0xe54e10aede55c7b1,
+		0xe55d85fc1bf82f21,
+		0xe5817f849ff906dc,
+		0xe684eb3aef1a6859,
+		0xe7711aada4bed56b,
+		0xea26e9973bd6a0d9,
+		0xf14fad09425d081c,
+		0xf58782f48a121998,
+		0xf705dc45c94766fd,
+		0xf7ff4414476c186a,
+		0xfca3742893be4cde)
+}
+
 var x_832bcc6686a26d56 = []byte{
 	0, 0, 0, 0, 2, 0, 0, 0,
 	0, 0, 0, 0, 1, 0, 0, 0,
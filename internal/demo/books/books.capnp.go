@@ -4,6 +4,7 @@ package books
 
 import (
 	capnp "zombiezen.com/go/capnproto"
+	schemas "zombiezen.com/go/capnproto/schemas"
 )
 
 type Book struct{ capnp.Struct }
@@ -83,3 +84,15 @@ func (p Book_Promise) Struct() (Book, error) {
 	s, err := p.Pipeline.Struct()
 	return Book{s}, err
 }
+
+const schema_85d3acc39d94e0f8 = "x\xda|\x91\xbbJ3A\x18\x86\x9f/\xa7?E\xf8I\xa7\x85\x9d\"x\xca\x06\x1b\x83\x9d\xa7\v\xd0\xd2.\x89C\b\xc9\xee,qE\\\x04\xb1\b\xa8`g\xeb\x05\b\xb6jgckg\xc06ޅ\xa5 3\xd9" +
+	"\xec\x9a\xec\xd3\xcdwx\xdf\xefe\x00\x0e\xb1d\xc8\x03/D\x14\x81<\x05\xbe\x87w\xf7o\x8f\x1f\xfd\x02\xe9l\x01\r`\x0f\x98\x01\xf6\x81\x8d\x94\xf9\xf3\xc1\xe0\xf3\xea\x9d\xcb\x12 \xc8\xc8G\xf8\x17\x9b\xeb\x037\xc0-" +
+	"\xc4:\xd7\xc0\xe9_Y\x1aZw\x8e+ͺ\xef\xf9\x00䌾\xf5\x13`\x19\xd8ֺcs\n\x19\x86+g\xf3勇\xd72\x90A\x98\x03@x\xda\xfd\xbf(\xcfկYS\xaf\x01 \x94\x12\xbe\x02\xac[" +
+	"\x7f\"&ͅ\x02\xa1v\x1bm\x15*\xaf\xd2Ԯ\xd3\xd2\xce\xef\xc9=\x1dh\xa7\xed\x05\xaa\xe7ջΑr\xb5\x93\x10\x1c˷i3X\x04\xa1\bd\xc9Ū\xe3,\x99;-\v@\x16\xa1j\xf2IL-" +
+	"b\r8\x88\x9e\xac\x9a\xbd\x9a\xd9\v\xdaAWMțdZ߯\xb7Ԏ>\xf1\x02\x18\xfd[\x1a\xd3\xfa?\x03\x00\x17\x1aJ\x89"
+
+func init() {
+	schemas.RegisterCompressed(schema_85d3acc39d94e0f8,
+		0x8100cc88d7d4d47c,
+		0x85d3acc39d94e0f8)
+}
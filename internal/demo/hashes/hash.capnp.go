@@ -5,6 +5,7 @@ package hashes
 import (
 	context "golang.org/x/net/context"
 	capnp "zombiezen.com/go/capnproto"
+	schemas "zombiezen.com/go/capnproto/schemas"
 	server "zombiezen.com/go/capnproto/server"
 )
 
@@ -556,3 +557,27 @@ func (p Hash_sum_Results_Promise) Struct() (Hash_sum_Results, error) {
 	s, err := p.Pipeline.Struct()
 	return Hash_sum_Results{s}, err
 }
+
+const schema_db8274f9144abc7e = "xڬ\x93Kh\x13o\x14\xc5\x7fߤ\xf9\xa7\x7fm5\x81\x8a\x16DA[Ŋ\x89\xedF\xa9hA\xc4禨\v\xe9nڎM!\x93)\x99\t%\x15T\xb2q#\b\xdaڍ\xa0\v]\b\xdaEA" +
+	"\x17R܉(\b\xed\xb6Rku!\x14\\X\x10_(\x92:\x8f\xf4\x9bI&>\xce\xee\u07b9\xf7\xce9\xe7~\x17\xe0+\x0e\x14\xa2\xc0\xe6\b.Z\x80(\xffq~\xeax\xd3\x17\xab\xf82Ju\xe4\x05\xf4\x00" +
+	"\x05\x01ہ1\x01{\xaa\xd4O\xcd~\x1f_{\xe6\xfe\xc4j \x02\xee\x7f\xe4\xbag\x02\x1e\x013\x02bxx!\xe0\b\xf0Q\xca?\xbfu\xf9\xce̪ɫ\xeb\x01A9b+\xa29\x01\x1d\x82\x9a1\x7f\xae" +
+	"\xeb\xec\xa7\xee\x03\x8b\xf2\\!\xcd}\"\xa03`n\x89o\x97?M\xfbh\xcf\xc0\xdc\xf8ͥ0\x1f\xde\v(\x02\x1f$\xbd\xa5\xfc%`\xbf\xb22\xff\xf6U\xe6\xf4\xc4\xe8\x8f\xf9x\b\xdfV\x05\x16\xfd\xb4ء" +
+	"\x04\xf3ծ|{\xbaɺw1\x1e\xe2\xef1\x05\x96j\xb7\x97\x1b\x9f\x1b\x9a\xa7'O\xbc\v\x9b\xbbK\x81\xd9ߘ\x1b[\xb8=\xb6\xef\xfa\xb5\xe90\x1f\x12\n\xbc\U00077cee\x82\x0fi\xd5L'\xfbԡ\xec" +
+	"\x10\x00\xd4\x03\x02\xe1\xbe\xeb\xff\x81\u07b2\xfd\x96\xe26\xe0\xa8j\xa6\x0f\xab}\x96\x91+\x80\x1d{\xfd\n\xafw\x16\xb6\xc6/\xdc}\x1c\a\x14\x04\x1b\x01\x10<8\xb4f\x9bx\xb8{a\x83\x9d\xdfk\xe7\x1b$^\x02" +
+	"\xe8\xb4\xf9i&.\x82\xea\x8a\x02F\f\xbdwP\x1bѲ\xc9>CO\r\x18\xa9eM9\xc32R\x83YK\xcbe\xd5L\xaa_Ӎ\x944\xd0\xd3\xdf)KB \xa8\x03\"D}w\xe9\xdcQ\x028Xe" +
+	"oQ \x06d\xb5\xe1Si\xb5\xbd\x8c\xf5\xaf\xf9\xc1\xffO\xda\xe5-\xddjN\xd5M\b\xab;\xa9\x99\xf9\x8ceb\xf3\xad\x93\x1c\xf2\xd0\b\xb4y!\xf5@\x04A\x93\xbd\x8f\xb4\xbdǄtײ\xaeD\x05\xbd\x12" +
+	"OWg\xbd\xe4\xa3s\xd7\xce\x1d\xb6\x03\x1dU|l\x05bej\x9c;s\xee\xa2\x19\xd8R\xb9\x9dF\xbb\x7f87hi8\x10\x98y\xdd\xe7U\xc0^\x92\xcb}\xde:\xfe\xda\xe7~\xd5R\x9d:Bx\xd7\xe0\xb3" +
+	"\xcd\xcf}\x06\xbe\xeff^/c\x1f\xfc\xdd\xeb\xfew\xef\xe8O\xf5\xfd\x1c\x00M\xab\xfa{"
+
+func init() {
+	schemas.RegisterCompressed(schema_db8274f9144abc7e,
+		0x80ac741ec7fb8f65,
+		0x92b20ad1a58ca0ca,
+		0xaead580f97fddabc,
+		0xd093963b95a4e107,
+		0xdb8274f9144abc7e,
+		0xdffe94ae546cdee3,
+		0xe74bb2d0190cf89c,
+		0xea3e50f7663f7bdf,
+		0xf29f97dd675a9431)
+}
@@ -0,0 +1,77 @@
+// Package nodemap provides a lazily-built index of the schema nodes in
+// a registry.
+package nodemap
+
+import (
+	"fmt"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/schema"
+	"zombiezen.com/go/capnproto/schemas"
+)
+
+// A Map finds nodes in a registry, parsing each schema the first time
+// one of its nodes is needed.  The zero value finds nodes in the
+// default registry.  A Map is not safe to use from multiple goroutines.
+type Map struct {
+	reg   *schemas.Registry
+	nodes map[uint64]schema.Node
+}
+
+// New returns a map that finds nodes in reg.
+func New(reg *schemas.Registry) *Map {
+	return &Map{reg: reg}
+}
+
+// Find returns the node with the given ID.
+func (m *Map) Find(id uint64) (schema.Node, error) {
+	if n := m.nodes[id]; n.Segment() != nil {
+		return n, nil
+	}
+	data, err := m.find(id)
+	if err != nil {
+		return schema.Node{}, err
+	}
+	msg, err := capnp.Unmarshal(data)
+	if err != nil {
+		return schema.Node{}, err
+	}
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
+	if err != nil {
+		return schema.Node{}, err
+	}
+	nodes, err := req.Nodes()
+	if err != nil {
+		return schema.Node{}, err
+	}
+	if m.nodes == nil {
+		m.nodes = make(map[uint64]schema.Node)
+	}
+	for i := 0; i < nodes.Len(); i++ {
+		n := nodes.At(i)
+		m.nodes[n.Id()] = n
+	}
+	if n := m.nodes[id]; n.Segment() != nil {
+		return n, nil
+	}
+	return schema.Node{}, &notFoundError{id}
+}
+
+func (m *Map) find(id uint64) ([]byte, error) {
+	if m.reg != nil {
+		return m.reg.Find(id)
+	}
+	data := schemas.Find(id)
+	if data == nil {
+		return nil, &notFoundError{id}
+	}
+	return data, nil
+}
+
+type notFoundError struct {
+	id uint64
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("node %#x not found in schemas", e.id)
+}
@@ -0,0 +1,37 @@
+package nodemap
+
+import (
+	"testing"
+
+	"zombiezen.com/go/capnproto/internal/schematest"
+	"zombiezen.com/go/capnproto/schemas"
+)
+
+func TestFind(t *testing.T) {
+	data := schemas.Find(schematest.PointID)
+	if data == nil {
+		t.Fatal("schematest nodes not registered")
+	}
+	reg := new(schemas.Registry)
+	err := reg.Register(&schemas.Schema{
+		String: string(data),
+		Nodes:  []uint64{schematest.PointID, schematest.ColorID},
+	})
+	if err != nil {
+		t.Fatal("Register:", err)
+	}
+	m := New(reg)
+	for _, id := range []uint64{schematest.ColorID, schematest.PointID, schematest.ColorID} {
+		n, err := m.Find(id)
+		if err != nil {
+			t.Errorf("Find(%#x): %v", id, err)
+			continue
+		}
+		if n.Id() != id {
+			t.Errorf("Find(%#x).Id() = %#x", id, n.Id())
+		}
+	}
+	if _, err := m.Find(0x1234); err == nil {
+		t.Error("Find(0x1234) succeeded; want error")
+	}
+}
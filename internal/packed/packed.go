@@ -2,7 +2,10 @@
 // compression scheme described at https://capnproto.org/encoding.html#packing.
 package packed
 
-import "io"
+import (
+	"errors"
+	"io"
+)
 
 const wordSize = 8
 
@@ -64,6 +67,120 @@ func Pack(dst, src []byte) []byte {
 	return dst
 }
 
+// A Writer packs the data written to it and writes the packed form to
+// an underlying writer.  Unlike Pack, a Writer only buffers a bounded
+// amount of packed output, so large inputs can be packed without
+// holding the whole packed form in memory.
+//
+// Each call to Write is packed and written through before Write
+// returns, except for a trailing partial word, which is held until
+// the rest of the word is written.  Call Flush after the last Write to
+// check that no partial word is left over.
+type Writer struct {
+	w   io.Writer
+	buf []byte
+
+	// part holds the bytes of an incomplete word.
+	part  [wordSize]byte
+	npart int
+}
+
+// writerChunkSize is the number of bytes that a Writer packs at a time.
+// It must be a multiple of wordSize.
+const writerChunkSize = 4096
+
+// maxPackedSize returns the largest size that packing n bytes can
+// produce.  Each word packs to at most one tag byte, its eight bytes,
+// and one count byte.
+func maxPackedSize(n int) int {
+	return n + n/wordSize*2
+}
+
+// NewWriter returns a writer that writes a packed stream to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write packs p and writes it to the underlying writer.
+func (pw *Writer) Write(p []byte) (n int, err error) {
+	if pw.npart > 0 {
+		nc := copy(pw.part[pw.npart:], p)
+		if pw.npart+nc < wordSize {
+			pw.npart += nc
+			return nc, nil
+		}
+		if err := pw.pack(pw.part[:]); err != nil {
+			// None of p was written.  Keep the bytes held from earlier
+			// writes, which were already counted as written.
+			return 0, err
+		}
+		pw.npart = 0
+		n += nc
+		p = p[nc:]
+	}
+	for len(p) >= wordSize {
+		chunk := p[:min(len(p), writerChunkSize)&^(wordSize-1)]
+		if err := pw.pack(chunk); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	pw.npart = copy(pw.part[:], p)
+	n += pw.npart
+	return n, nil
+}
+
+// Flush returns an error if a partial word is held, since a packed
+// stream can only hold whole words.  Every whole word has already been
+// written to the underlying writer.
+func (pw *Writer) Flush() error {
+	if pw.npart > 0 {
+		return errPartialWord
+	}
+	return nil
+}
+
+// pack packs src, which must be a whole number of words no larger than
+// writerChunkSize, and writes it to the underlying writer.
+func (pw *Writer) pack(src []byte) error {
+	if pw.buf == nil {
+		pw.buf = make([]byte, 0, maxPackedSize(writerChunkSize))
+	}
+	pw.buf = Pack(pw.buf[:0], src)
+	_, err := pw.w.Write(pw.buf)
+	return err
+}
+
+// FirstWord returns the first word of the data packed in prefix.  ok is
+// false if prefix is too short to hold the whole first word.
+func FirstWord(prefix []byte) (word [wordSize]byte, ok bool) {
+	if len(prefix) == 0 {
+		return word, false
+	}
+	tag, prefix := prefix[0], prefix[1:]
+	switch tag {
+	case zeroTag:
+		return word, true
+	case unpackedTag:
+		if len(prefix) < wordSize {
+			return word, false
+		}
+		copy(word[:], prefix)
+		return word, true
+	}
+	for i := uint(0); i < wordSize; i++ {
+		if tag&(1<<i) == 0 {
+			continue
+		}
+		if len(prefix) == 0 {
+			return word, false
+		}
+		word[i], prefix = prefix[0], prefix[1:]
+	}
+	return word, true
+}
+
 // numZeroWords returns the number of leading zero words in b.
 func numZeroWords(b []byte) int {
 	for i, bb := range b {
@@ -76,6 +193,7 @@ func numZeroWords(b []byte) int {
 
 type decompressor struct {
 	r     io.Reader
+	br    io.ByteReader // r as an io.ByteReader, if it is one
 	buf   [wordSize]byte
 	bufsz int
 
@@ -91,7 +209,42 @@ type decompressor struct {
 
 // NewReader returns a reader that decompresses a packed stream from r.
 func NewReader(r io.Reader) io.Reader {
-	return &decompressor{r: r}
+	br, _ := r.(io.ByteReader)
+	return &decompressor{r: r, br: br}
+}
+
+// readByte reads a single tag or count byte from the underlying reader.
+func (c *decompressor) readByte() (byte, error) {
+	if c.br != nil {
+		return c.br.ReadByte()
+	}
+	var b [1]byte
+	_, err := io.ReadFull(c.r, b[:])
+	return b[0], err
+}
+
+// unpackWord reads the non-zero bytes of the word described by tag and
+// expands them into dst, which must be wordSize bytes long.
+func (c *decompressor) unpackWord(dst []byte, tag byte) error {
+	ones := 0
+	for i := uint(0); i < wordSize; i++ {
+		ones += int(tag >> i & 1)
+	}
+	if _, err := io.ReadFull(c.r, dst[:ones]); err != nil {
+		return err
+	}
+	// Spread the bytes out in place, working backward so that no byte
+	// is overwritten before it is moved.
+	j := ones - 1
+	for i := wordSize - 1; i >= 0; i-- {
+		if tag&(1<<uint(i)) != 0 {
+			dst[i] = dst[j]
+			j--
+		} else {
+			dst[i] = 0
+		}
+	}
+	return nil
 }
 
 func min(a, b int) int {
@@ -103,7 +256,6 @@ func min(a, b int) int {
 
 func (c *decompressor) Read(v []byte) (n int, err error) {
 
-	var b [1]byte
 	var bytesRead int
 
 	for {
@@ -166,13 +318,11 @@ func (c *decompressor) Read(v []byte) (n int, err error) {
 			// stay in postFFState
 
 		case readnState:
-			if bytesRead, err = c.r.Read(b[:]); err != nil {
+			var count byte
+			if count, err = c.readByte(); err != nil {
 				return
 			}
-			if bytesRead == 0 {
-				return
-			}
-			c.raw = int(b[0]) * wordSize
+			c.raw = int(count) * wordSize
 			c.state = rawState
 
 		case normalState:
@@ -207,25 +357,34 @@ func (c *decompressor) Read(v []byte) (n int, err error) {
 			// INVAR: c.bufz == 0
 
 			for c.state == normalState && len(v) > 0 {
-
-				if _, err = c.r.Read(b[:]); err != nil {
+				var tag byte
+				if tag, err = c.readByte(); err != nil {
 					return
 				}
 
-				switch b[0] {
+				switch tag {
 				case unpackedTag:
+					if len(v) >= wordSize {
+						// Fast path: read the word straight into v.
+						if _, err = io.ReadFull(c.r, v[:wordSize]); err != nil {
+							return
+						}
+						v = v[wordSize:]
+						n += wordSize
+						c.state = readnState
+						break
+					}
 					c.ffBufLoadCount = 0
 					c.ffBufUsedCount = 0
 					c.state = postFFState
 
-					break
-
 				case zeroTag:
-					if _, err = c.r.Read(b[:]); err != nil {
+					var count byte
+					if count, err = c.readByte(); err != nil {
 						return
 					}
 
-					requestedZeroBytes := (int(b[0]) + 1) * wordSize
+					requestedZeroBytes := (int(count) + 1) * wordSize
 					zeros := min(requestedZeroBytes, len(v))
 
 					for i := 0; i < zeros; i++ {
@@ -237,28 +396,18 @@ func (c *decompressor) Read(v []byte) (n int, err error) {
 					c.zeros = requestedZeroBytes - zeros
 
 				default:
-					ones := 0
-					var buf [wordSize]byte
-					for i := 0; i < wordSize; i++ {
-						if (b[0] & (1 << uint(i))) != 0 {
-							ones++
+					if len(v) >= wordSize {
+						// Fast path: expand the word straight into v.
+						if err = c.unpackWord(v[:wordSize], tag); err != nil {
+							return
 						}
+						v = v[wordSize:]
+						n += wordSize
+						continue
 					}
-
-					_, err = io.ReadFull(c.r, buf[:ones])
-					if err != nil {
+					if err = c.unpackWord(c.buf[:], tag); err != nil {
 						return
 					}
-
-					for i, j := 0, 0; i < wordSize; i++ {
-						if (b[0] & (1 << uint(i))) != 0 {
-							c.buf[i] = buf[j]
-							j++
-						} else {
-							c.buf[i] = 0
-						}
-					}
-
 					use := copy(v, c.buf[:])
 					v = v[use:]
 					n += use
@@ -285,3 +434,5 @@ const (
 	readnState
 	rawState
 )
+
+var errPartialWord = errors.New("packed: data ends in a partial word")
@@ -3,8 +3,11 @@ package packed
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"io"
+	"io/ioutil"
 	"testing"
+	"testing/iotest"
 )
 
 var compressionTests = []struct {
@@ -185,6 +188,181 @@ func TestReader(t *testing.T) {
 	}
 }
 
+func TestFirstWord(t *testing.T) {
+	for i, test := range compressionTests {
+		if len(test.original) < wordSize {
+			if _, ok := FirstWord(test.compressed); ok {
+				t.Errorf("test:%d: FirstWord(% x) ok = true; want false", i, test.compressed)
+			}
+			continue
+		}
+		word, ok := FirstWord(test.compressed)
+		if !ok || !bytes.Equal(word[:], test.original[:wordSize]) {
+			t.Errorf("test:%d: FirstWord(% x) = % x, %t; want % x, true", i, test.compressed, word, ok, test.original[:wordSize])
+		}
+		// A non-zero tag is always followed by at least one byte.
+		if test.compressed[0] != zeroTag {
+			if _, ok := FirstWord(test.compressed[:1]); ok {
+				t.Errorf("test:%d: FirstWord(% x) ok = true; want false", i, test.compressed[:1])
+			}
+		}
+	}
+}
+
+func TestReaderNoByteReader(t *testing.T) {
+	for i, test := range compressionTests {
+		d := NewReader(iotest.OneByteReader(bytes.NewReader(test.compressed)))
+		actual, err := readAll(d)
+		if err != nil {
+			t.Errorf("test:%d: read error: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(test.original, actual) {
+			t.Errorf("test:%d: bytes not equal", i)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	for i, test := range compressionTests {
+		for writeSize := 1; writeSize <= len(test.original); writeSize++ {
+			var buf bytes.Buffer
+			w := NewWriter(&buf)
+			for p := test.original; len(p) > 0; {
+				chunk := p[:min(writeSize, len(p))]
+				n, err := w.Write(chunk)
+				if n != len(chunk) || err != nil {
+					t.Fatalf("test:%d writeSize:%d: Write(%d bytes) = %d, %v; want %d, <nil>", i, writeSize, len(chunk), n, err, len(chunk))
+				}
+				p = p[len(chunk):]
+			}
+			if writeSize == len(test.original) && !bytes.Equal(buf.Bytes(), test.compressed) {
+				t.Errorf("test:%d: Write(\n%s\n) wrote\n%s\n; want\n%s", i, hex.Dump(test.original), hex.Dump(buf.Bytes()), hex.Dump(test.compressed))
+			}
+			actual, err := readAll(NewReader(&buf))
+			if err != nil {
+				t.Errorf("test:%d writeSize:%d: read error: %v", i, writeSize, err)
+				continue
+			}
+			if !bytes.Equal(test.original, actual) {
+				t.Errorf("test:%d writeSize:%d: round trip bytes not equal", i, writeSize)
+			}
+		}
+	}
+}
+
+func TestWriterLarge(t *testing.T) {
+	// Write more than one chunk with a mix of zero and raw runs.
+	src := make([]byte, 3*writerChunkSize+5*wordSize)
+	for i := range src {
+		if i/1000%2 == 1 {
+			src[i] = byte(i)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := NewWriter(&buf).Write(src); err != nil {
+		t.Fatal("Write:", err)
+	}
+	actual, err := readAll(NewReader(&buf))
+	if err != nil {
+		t.Fatal("read:", err)
+	}
+	if !bytes.Equal(src, actual) {
+		t.Error("round trip bytes not equal")
+	}
+}
+
+func TestWriterError(t *testing.T) {
+	src := make([]byte, 2*writerChunkSize+3)
+	for i := range src {
+		src[i] = byte(i)
+	}
+	tests := []struct {
+		name   string
+		writes []int // sizes of the writes, the last of which fails
+		ok     int   // number of writes to the underlying writer that succeed
+		want   int   // bytes of the last write that were written
+		held   bool  // whether a partial word is still held
+	}{
+		{"first word", []int{wordSize}, 0, 0, false},
+		{"partial word", []int{3, wordSize}, 0, 0, true},
+		{"partial word completed exactly", []int{3, 5}, 0, 0, true},
+		{"words after partial word", []int{3, 5 + 2*wordSize}, 1, 5, false},
+		{"second chunk", []int{2*writerChunkSize + 3}, 1, writerChunkSize, false},
+	}
+	for _, test := range tests {
+		w := NewWriter(&failWriter{ok: test.ok})
+		p := src
+		for i, size := range test.writes {
+			n, err := w.Write(p[:size])
+			p = p[size:]
+			if i < len(test.writes)-1 {
+				if n != size || err != nil {
+					t.Fatalf("%s: Write #%d = %d, %v; want %d, <nil>", test.name, i+1, n, err, size)
+				}
+				continue
+			}
+			if n != test.want || err != errWrite {
+				t.Errorf("%s: failed Write(%d bytes) = %d, %v; want %d, %v", test.name, size, n, err, test.want, errWrite)
+			}
+		}
+		if err := w.Flush(); (err != nil) != test.held {
+			t.Errorf("%s: Flush() = %v; want error = %t", test.name, err, test.held)
+		}
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush() before writing = %v; want <nil>", err)
+	}
+	if _, err := w.Write(make([]byte, wordSize+3)); err != nil {
+		t.Fatal("Write:", err)
+	}
+	if err := w.Flush(); err != errPartialWord {
+		t.Errorf("Flush() with 3 bytes held = %v; want %v", err, errPartialWord)
+	}
+	if _, err := w.Write(make([]byte, wordSize-3)); err != nil {
+		t.Fatal("Write:", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush() after completing the word = %v; want <nil>", err)
+	}
+}
+
+var errWrite = errors.New("write failed")
+
+// failWriter discards the first ok writes and fails after that.
+type failWriter struct {
+	ok int
+}
+
+func (fw *failWriter) Write(p []byte) (int, error) {
+	if fw.ok == 0 {
+		return 0, errWrite
+	}
+	fw.ok--
+	return len(p), nil
+}
+
+// readAll reads from r until EOF.
+func readAll(r io.Reader) ([]byte, error) {
+	var out []byte
+	buf := make([]byte, 13)
+	for {
+		n, err := r.Read(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+}
+
 var result []byte
 
 func BenchmarkPack(b *testing.B) {
@@ -210,6 +388,24 @@ func BenchmarkPack(b *testing.B) {
 	result = dst
 }
 
+func BenchmarkWriter(b *testing.B) {
+	src := bytes.Repeat([]byte{
+		8, 0, 100, 6, 0, 1, 1, 2,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 1, 0, 2, 0, 3, 1,
+		'H', 'e', 'l', 'l', 'o', ',', ' ', 'W',
+	}, 1024)
+	w := NewWriter(ioutil.Discard)
+	b.SetBytes(int64(len(src)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkDecompressor(b *testing.B) {
 	const multiplier = 128
 	src := bytes.Repeat([]byte{
@@ -0,0 +1,14 @@
+// Package schematest holds the types that the tests of the reflection
+// packages share, compiled from shapes.capnp.  Importing the package
+// registers their schema nodes in the default registry.
+package schematest
+
+//go:generate capnp compile -ogo shapes.capnp
+
+// Type IDs of the nodes in shapes.capnp.
+const (
+	ColorID      uint64 = 0x937e891dceca484e
+	PointID      uint64 = 0xb4e9692c0c23f037
+	ShapeID      uint64 = 0xd82e3fe12fc29370
+	EverythingID uint64 = 0x9c07ad9958795fdf
+)
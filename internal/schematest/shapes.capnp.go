@@ -0,0 +1,708 @@
+package schematest
+
+// AUTO GENERATED - DO NOT EDIT
+
+import (
+	math "math"
+	strconv "strconv"
+	capnp "zombiezen.com/go/capnproto"
+	schemas "zombiezen.com/go/capnproto/schemas"
+)
+
+type Color uint16
+
+// Values of Color.
+const (
+	Color_red   Color = 0
+	Color_green Color = 1
+	Color_blue  Color = 2
+)
+
+// String returns the enum's constant name.
+func (c Color) String() string {
+	switch c {
+	case Color_red:
+		return "red"
+	case Color_green:
+		return "green"
+	case Color_blue:
+		return "blue"
+
+	default:
+		return ""
+	}
+}
+
+// ColorFromString returns the enum value with a name,
+// or the zero value if there's no such value.
+func ColorFromString(c string) Color {
+	switch c {
+	case "red":
+		return Color_red
+	case "green":
+		return Color_green
+	case "blue":
+		return Color_blue
+
+	default:
+		return 0
+	}
+}
+
+type Color_List struct{ capnp.List }
+
+func NewColor_List(s *capnp.Segment, sz int32) (Color_List, error) {
+	l, err := capnp.NewUInt16List(s, sz)
+	if err != nil {
+		return Color_List{}, err
+	}
+	return Color_List{l.List}, nil
+}
+
+func (l Color_List) At(i int) Color {
+	ul := capnp.UInt16List{List: l.List}
+	return Color(ul.At(i))
+}
+
+func (l Color_List) Set(i int, v Color) {
+	ul := capnp.UInt16List{List: l.List}
+	ul.Set(i, uint16(v))
+}
+
+type Point struct{ capnp.Struct }
+
+func NewPoint(s *capnp.Segment) (Point, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{st}, nil
+}
+
+func NewRootPoint(s *capnp.Segment) (Point, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{st}, nil
+}
+
+func ReadRootPoint(msg *capnp.Message) (Point, error) {
+	root, err := msg.Root()
+	if err != nil {
+		return Point{}, err
+	}
+	st := capnp.ToStruct(root)
+	return Point{st}, nil
+}
+
+func (s Point) X() int32 {
+	return int32(s.Struct.Uint32(0))
+}
+
+func (s Point) SetX(v int32) {
+
+	s.Struct.SetUint32(0, uint32(v))
+}
+
+func (s Point) Y() int32 {
+	return int32(s.Struct.Uint32(4) ^ 4294967291)
+}
+
+func (s Point) SetY(v int32) {
+
+	s.Struct.SetUint32(4, uint32(v)^4294967291)
+}
+
+// Point_List is a list of Point.
+type Point_List struct{ capnp.List }
+
+// NewPoint creates a new list of Point.
+func NewPoint_List(s *capnp.Segment, sz int32) (Point_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0}, sz)
+	if err != nil {
+		return Point_List{}, err
+	}
+	return Point_List{l}, nil
+}
+
+func (s Point_List) At(i int) Point           { return Point{s.List.Struct(i)} }
+func (s Point_List) Set(i int, v Point) error { return s.List.SetStruct(i, v.Struct) }
+
+// Point_Promise is a wrapper for a Point promised by a client call.
+type Point_Promise struct{ *capnp.Pipeline }
+
+func (p Point_Promise) Struct() (Point, error) {
+	s, err := p.Pipeline.Struct()
+	return Point{s}, err
+}
+
+type Shape struct{ capnp.Struct }
+type Shape_position Shape
+type Shape_Which uint16
+
+const (
+	Shape_Which_circle   Shape_Which = 0
+	Shape_Which_square   Shape_Which = 1
+	Shape_Which_position Shape_Which = 2
+	Shape_Which_none     Shape_Which = 3
+)
+
+func (w Shape_Which) String() string {
+	const s = "circlesquarepositionnone"
+	switch w {
+	case Shape_Which_circle:
+		return s[0:6]
+	case Shape_Which_square:
+		return s[6:12]
+	case Shape_Which_position:
+		return s[12:20]
+	case Shape_Which_none:
+		return s[20:24]
+
+	}
+	return "Shape_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
+}
+
+func NewShape(s *capnp.Segment) (Shape, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 5})
+	if err != nil {
+		return Shape{}, err
+	}
+	return Shape{st}, nil
+}
+
+func NewRootShape(s *capnp.Segment) (Shape, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 5})
+	if err != nil {
+		return Shape{}, err
+	}
+	return Shape{st}, nil
+}
+
+func ReadRootShape(msg *capnp.Message) (Shape, error) {
+	root, err := msg.Root()
+	if err != nil {
+		return Shape{}, err
+	}
+	st := capnp.ToStruct(root)
+	return Shape{st}, nil
+}
+
+func (s Shape) Which() Shape_Which {
+	return Shape_Which(s.Struct.Uint16(2))
+}
+
+func (s Shape) Name() (string, error) {
+	p, err := s.Struct.Pointer(0)
+	if err != nil {
+		return "", err
+	}
+
+	return capnp.ToText(p), nil
+
+}
+
+func (s Shape) SetName(v string) error {
+
+	t, err := capnp.NewText(s.Struct.Segment(), v)
+	if err != nil {
+		return err
+	}
+	return s.Struct.SetPointer(0, t)
+}
+
+func (s Shape) Color() Color {
+	return Color(s.Struct.Uint16(0) ^ 1)
+}
+
+func (s Shape) SetColor(v Color) {
+
+	s.Struct.SetUint16(0, uint16(v)^1)
+}
+
+func (s Shape) Circle() float64 {
+	return math.Float64frombits(s.Struct.Uint64(8))
+}
+
+func (s Shape) SetCircle(v float64) {
+	s.Struct.SetUint16(2, 0)
+	s.Struct.SetUint64(8, math.Float64bits(v))
+}
+
+func (s Shape) Square() (Point, error) {
+	p, err := s.Struct.Pointer(1)
+	if err != nil {
+		return Point{}, err
+	}
+
+	ss := capnp.ToStruct(p)
+
+	return Point{Struct: ss}, nil
+}
+
+func (s Shape) SetSquare(v Point) error {
+	s.Struct.SetUint16(2, 1)
+	return s.Struct.SetPointer(1, v.Struct)
+}
+
+// NewSquare sets the square field to a newly
+// allocated Point struct, preferring placement in s's segment.
+func (s Shape) NewSquare() (Point, error) {
+	s.Struct.SetUint16(2, 1)
+	ss, err := NewPoint(s.Struct.Segment())
+	if err != nil {
+		return Point{}, err
+	}
+	err = s.Struct.SetPointer(1, ss)
+	return ss, err
+}
+func (s Shape) Position() Shape_position { return Shape_position(s) }
+
+func (s Shape) SetPosition() { s.Struct.SetUint16(2, 2) }
+
+func (s Shape_position) X() float32 {
+	return math.Float32frombits(s.Struct.Uint32(8))
+}
+
+func (s Shape_position) SetX(v float32) {
+
+	s.Struct.SetUint32(8, math.Float32bits(v))
+}
+
+func (s Shape_position) Label() (string, error) {
+	p, err := s.Struct.Pointer(1)
+	if err != nil {
+		return "", err
+	}
+
+	return capnp.ToText(p), nil
+
+}
+
+func (s Shape_position) SetLabel(v string) error {
+
+	t, err := capnp.NewText(s.Struct.Segment(), v)
+	if err != nil {
+		return err
+	}
+	return s.Struct.SetPointer(1, t)
+}
+
+func (s Shape) SetNone() {
+	s.Struct.SetUint16(2, 3)
+}
+
+func (s Shape) Tags() (capnp.TextList, error) {
+	p, err := s.Struct.Pointer(2)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return capnp.TextList{List: l}, nil
+}
+
+func (s Shape) SetTags(v capnp.TextList) error {
+
+	return s.Struct.SetPointer(2, v.List)
+}
+
+func (s Shape) Points() (Point_List, error) {
+	p, err := s.Struct.Pointer(3)
+	if err != nil {
+		return Point_List{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return Point_List{List: l}, nil
+}
+
+func (s Shape) SetPoints(v Point_List) error {
+
+	return s.Struct.SetPointer(3, v.List)
+}
+
+func (s Shape) Data() ([]byte, error) {
+	p, err := s.Struct.Pointer(4)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(capnp.ToData(p)), nil
+
+}
+
+func (s Shape) SetData(v []byte) error {
+
+	d, err := capnp.NewData(s.Struct.Segment(), []byte(v))
+	if err != nil {
+		return err
+	}
+	return s.Struct.SetPointer(4, d)
+}
+
+func (s Shape) Visible() bool {
+	return s.Struct.Bit(32)
+}
+
+func (s Shape) SetVisible(v bool) {
+
+	s.Struct.SetBit(32, v)
+}
+
+// Shape_List is a list of Shape.
+type Shape_List struct{ capnp.List }
+
+// NewShape creates a new list of Shape.
+func NewShape_List(s *capnp.Segment, sz int32) (Shape_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 5}, sz)
+	if err != nil {
+		return Shape_List{}, err
+	}
+	return Shape_List{l}, nil
+}
+
+func (s Shape_List) At(i int) Shape           { return Shape{s.List.Struct(i)} }
+func (s Shape_List) Set(i int, v Shape) error { return s.List.SetStruct(i, v.Struct) }
+
+// Shape_Promise is a wrapper for a Shape promised by a client call.
+type Shape_Promise struct{ *capnp.Pipeline }
+
+func (p Shape_Promise) Struct() (Shape, error) {
+	s, err := p.Pipeline.Struct()
+	return Shape{s}, err
+}
+
+func (p Shape_Promise) Square() Point_Promise {
+	return Point_Promise{Pipeline: p.Pipeline.GetPipeline(1)}
+}
+func (p Shape_Promise) Position() Shape_position_Promise { return Shape_position_Promise{p.Pipeline} }
+
+// Shape_position_Promise is a wrapper for a Shape_position promised by a client call.
+type Shape_position_Promise struct{ *capnp.Pipeline }
+
+func (p Shape_position_Promise) Struct() (Shape_position, error) {
+	s, err := p.Pipeline.Struct()
+	return Shape_position{s}, err
+}
+
+type Everything struct{ capnp.Struct }
+
+func NewEverything(s *capnp.Segment) (Everything, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 9})
+	if err != nil {
+		return Everything{}, err
+	}
+	return Everything{st}, nil
+}
+
+func NewRootEverything(s *capnp.Segment) (Everything, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 9})
+	if err != nil {
+		return Everything{}, err
+	}
+	return Everything{st}, nil
+}
+
+func ReadRootEverything(msg *capnp.Message) (Everything, error) {
+	root, err := msg.Root()
+	if err != nil {
+		return Everything{}, err
+	}
+	st := capnp.ToStruct(root)
+	return Everything{st}, nil
+}
+
+func (s Everything) B() bool {
+	return s.Struct.Bit(0)
+}
+
+func (s Everything) SetB(v bool) {
+
+	s.Struct.SetBit(0, v)
+}
+
+func (s Everything) I8() int8 {
+	return int8(s.Struct.Uint8(1))
+}
+
+func (s Everything) SetI8(v int8) {
+
+	s.Struct.SetUint8(1, uint8(v))
+}
+
+func (s Everything) U16() uint16 {
+	return s.Struct.Uint16(2)
+}
+
+func (s Everything) SetU16(v uint16) {
+
+	s.Struct.SetUint16(2, v)
+}
+
+func (s Everything) I32() int32 {
+	return int32(s.Struct.Uint32(4) ^ 4294967291)
+}
+
+func (s Everything) SetI32(v int32) {
+
+	s.Struct.SetUint32(4, uint32(v)^4294967291)
+}
+
+func (s Everything) U64() uint64 {
+	return s.Struct.Uint64(8)
+}
+
+func (s Everything) SetU64(v uint64) {
+
+	s.Struct.SetUint64(8, v)
+}
+
+func (s Everything) I64() int64 {
+	return int64(s.Struct.Uint64(16))
+}
+
+func (s Everything) SetI64(v int64) {
+
+	s.Struct.SetUint64(16, uint64(v))
+}
+
+func (s Everything) F32() float32 {
+	return math.Float32frombits(s.Struct.Uint32(24))
+}
+
+func (s Everything) SetF32(v float32) {
+
+	s.Struct.SetUint32(24, math.Float32bits(v))
+}
+
+func (s Everything) F64() float64 {
+	return math.Float64frombits(s.Struct.Uint64(32))
+}
+
+func (s Everything) SetF64(v float64) {
+
+	s.Struct.SetUint64(32, math.Float64bits(v))
+}
+
+func (s Everything) Text() (string, error) {
+	p, err := s.Struct.Pointer(0)
+	if err != nil {
+		return "", err
+	}
+
+	return capnp.ToTextDefault(p, "hi"), nil
+
+}
+
+func (s Everything) SetText(v string) error {
+
+	t, err := capnp.NewText(s.Struct.Segment(), v)
+	if err != nil {
+		return err
+	}
+	return s.Struct.SetPointer(0, t)
+}
+
+func (s Everything) Data() ([]byte, error) {
+	p, err := s.Struct.Pointer(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(capnp.ToData(p)), nil
+
+}
+
+func (s Everything) SetData(v []byte) error {
+
+	d, err := capnp.NewData(s.Struct.Segment(), []byte(v))
+	if err != nil {
+		return err
+	}
+	return s.Struct.SetPointer(1, d)
+}
+
+func (s Everything) Point() (Point, error) {
+	p, err := s.Struct.Pointer(2)
+	if err != nil {
+		return Point{}, err
+	}
+
+	ss := capnp.ToStruct(p)
+
+	return Point{Struct: ss}, nil
+}
+
+func (s Everything) SetPoint(v Point) error {
+
+	return s.Struct.SetPointer(2, v.Struct)
+}
+
+// NewPoint sets the point field to a newly
+// allocated Point struct, preferring placement in s's segment.
+func (s Everything) NewPoint() (Point, error) {
+
+	ss, err := NewPoint(s.Struct.Segment())
+	if err != nil {
+		return Point{}, err
+	}
+	err = s.Struct.SetPointer(2, ss)
+	return ss, err
+}
+
+func (s Everything) Points() (Point_List, error) {
+	p, err := s.Struct.Pointer(3)
+	if err != nil {
+		return Point_List{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return Point_List{List: l}, nil
+}
+
+func (s Everything) SetPoints(v Point_List) error {
+
+	return s.Struct.SetPointer(3, v.List)
+}
+
+func (s Everything) Ints() (capnp.Int16List, error) {
+	p, err := s.Struct.Pointer(4)
+	if err != nil {
+		return capnp.Int16List{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return capnp.Int16List{List: l}, nil
+}
+
+func (s Everything) SetInts(v capnp.Int16List) error {
+
+	return s.Struct.SetPointer(4, v.List)
+}
+
+func (s Everything) Bools() (capnp.BitList, error) {
+	p, err := s.Struct.Pointer(5)
+	if err != nil {
+		return capnp.BitList{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return capnp.BitList{List: l}, nil
+}
+
+func (s Everything) SetBools(v capnp.BitList) error {
+
+	return s.Struct.SetPointer(5, v.List)
+}
+
+func (s Everything) Colors() (Color_List, error) {
+	p, err := s.Struct.Pointer(6)
+	if err != nil {
+		return Color_List{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return Color_List{List: l}, nil
+}
+
+func (s Everything) SetColors(v Color_List) error {
+
+	return s.Struct.SetPointer(6, v.List)
+}
+
+func (s Everything) Matrix() (capnp.PointerList, error) {
+	p, err := s.Struct.Pointer(7)
+	if err != nil {
+		return capnp.PointerList{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return capnp.PointerList{List: l}, nil
+}
+
+func (s Everything) SetMatrix(v capnp.PointerList) error {
+
+	return s.Struct.SetPointer(7, v.List)
+}
+
+func (s Everything) Blobs() (capnp.DataList, error) {
+	p, err := s.Struct.Pointer(8)
+	if err != nil {
+		return capnp.DataList{}, err
+	}
+
+	l := capnp.ToList(p)
+
+	return capnp.DataList{List: l}, nil
+}
+
+func (s Everything) SetBlobs(v capnp.DataList) error {
+
+	return s.Struct.SetPointer(8, v.List)
+}
+
+// Everything_List is a list of Everything.
+type Everything_List struct{ capnp.List }
+
+// NewEverything creates a new list of Everything.
+func NewEverything_List(s *capnp.Segment, sz int32) (Everything_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 40, PointerCount: 9}, sz)
+	if err != nil {
+		return Everything_List{}, err
+	}
+	return Everything_List{l}, nil
+}
+
+func (s Everything_List) At(i int) Everything           { return Everything{s.List.Struct(i)} }
+func (s Everything_List) Set(i int, v Everything) error { return s.List.SetStruct(i, v.Struct) }
+
+// Everything_Promise is a wrapper for a Everything promised by a client call.
+type Everything_Promise struct{ *capnp.Pipeline }
+
+func (p Everything_Promise) Struct() (Everything, error) {
+	s, err := p.Pipeline.Struct()
+	return Everything{s}, err
+}
+
+func (p Everything_Promise) Point() Point_Promise {
+	return Point_Promise{Pipeline: p.Pipeline.GetPipeline(2)}
+}
+
+const schema_8ca2b2347c23363b = "xڴ\x97_\x88Te\x14\xc0\x7f\xe7Ό\xb3\xab;\xebXI*\n\x96\x19Z\xba\xab\xb6\xa9\x99\x10\x94\x91\x04\x95\xd0K\xf4\x123\xebu\xf7\xc6\xec\xbd\xeb\xcc]s\xa5?(\x14\t\xbd\xf9T\xf4\xd4[\x0f=\x14" +
+	"\x15=\x15\xf8\x14QQ`\xa0a冁B\x81\x81B\xa1\xb6\xb1\xee\xb9\xf7~\xf7\x9b{gֱ\x0e\xfb\xf0}{\xbe\xdf9\xe7;\xdfw\xcf\xf9\x06\xe0\x98\x03\x00\xe0P\x02\xee\x8c\xe7\xb0\x02(\xb1\x88\x87\xb7\xaf{" +
+	"y\xe4\xa3\xf7\xde*\xd3Y\xfe\x04^\x04\xae\x00O\x00\x1b\x05vtX\xff\xf4ޯ\xbe]s\xfc\xd5\x13\x15\xc0\x81؏\xbd\xae&\xf0\x0e\xe0\t\x981\xb8\x02ϴ\x9beǥu\x03\x9b\xbc\x8b\x1fW\x00A\f\xbb" +
+	"\xe5Ժi\xb5{Բ\xfb\x8a\xc0K\xedf\x99<qrx摡\xd3\xf3v\x9d\xd8n\x892PD\x00\x803jwƲ\xfb\x93\xc0\x0e\xa7\xdd\xeegW\x7f|\xe3\xe7\xeb\x1f\x1e\xbb]\xedF~\xe6\xec\n\x89" +
+	"\xdcU\x84_\xdbq\xd6\x17\xb3\xe3\xfd\xe5\x85\xe9\xe7\xde\xfe\xa0\xfc\xee|\xbc\xa58\xde~+\x0fG\x8bp\x128^L\xc7\xfbz\x11\xbe(\xb4\xdbm\x8d\xd7&\xdd\xd6\xd0hmҟ\x04\xa8\x02\x82\xc4\xe7\xb9\x12\xd8f" +
+	"\x9c\xc3r\x9dG\xfbZ\xa6s3\xbe\xe7\x81ǂF\xd0\x04\xf6\x05\x9e\x1f\x02\xcf\xce\xf9\x01\x1e?\xe46\xa7\xc3q\xcf\x1f\x03\x80>@p8w\xff\xf4\xba\xeak\xef\x7f^\x05\x1c\x84\xb5\x00\b\x9f\xec\x19\xbcW>\xdd" +
+	"2\xb3Z\xff\xbf\x1b\x00a\xc0ڇ\xa8\xdf\xd6\xe8\xb8;Q\v\xddV\x98Rg\xae?\"p$\x98\xa8{\xee\x11\xd7\x1f\x1a\r&\x86ǂ\xe1\x1b\x99h\x06a0\xec\xf9\xa1\xdb\xf4k\x8d\xe1,\x9bf\xdevE\x9b" +
+	"\x05\x00D\xe3\x13\x1c\"Y\x03\xdcm\xf9\a\x88\xf2\x19IDT\x80\xfbH\xa4\xe9\xee\a`\xac\xe9\xba>PoL\xb9mqD\xc96\xe2\xe8\x03\n\x143<\x03\xc0F\xe0\xb6d\xca=@\x01a\v\xe0 \b \xb1" +
+	"\xb5h\f\x9b-n\x83r#\xca\x1d\x06\x80\x94\xe7,\xc9\xd3O\xdf\x04\x7fuvv\xb6ӽ\xde\x15]>\x00@\x10\x8d7?/\xfd\x92\xce\x7fQ\xa0\x80P\x95$/d\xe4\xa5\"\xe9\xf3\xecS\xee\x0e\xe5\x1cfg" +
+	"\x05\xac\xdb\x01\xcb\x04v\x99\xf7U\xb9\x15\xca\x15\xf8'\xe6\xcc\xcfx\xb9\xc5U\x95[\xad\\\x91\xeb\xb3\x00bէ\x95\x02Ov\xc8k\x89k78\x10\x16\x99\xff\xb7\xf2\x82\xfa\x1bP\x7fsk\x1d\x00\x84\xf2\x02\xf2\xb9" +
+	"V\xb92P\x00\xd0;\x1b\x7f7\xd6\xfeV(7\xa2\\\x1fP\x04@\xe8'\x91\xad\x96\xbfM\xca\xedT\xae\x1f\xe2\x1a\xb3\x98D\x1e\x14x4\x99\xb2E\xb9\xdd\xca\xf9\xb5\t7\xab\x9eؒ\xa7\x1f\xd5R\xb1\xd4\xea\x9b" +
+	"\xf6\xba\xa5\xd6}\x8cy\xaf9\xdapaIg\xf7\xb9\xfa\xd6\xc1\xa9ZӅ\xaa\xd5_\xedu\xd5l\x9cɠ\xe5\x85^\xe0\x03\x00\xf8\x81\xefr\v\x12\xd6\xc6Z\x00\x83\x1d\xd6\x142\xea\xbd-\x83\xb9\xf1z~\xd8\xea" +
+	"n\xbf[>\xf2\xf8\xfd\xb5\xb0\x06P\xe9\x12_\x9e\xfe\x90\xd7\xf2\xea\r7\xe7\xb4\xf3\xaaS\xa7:7\x94:\"\xb3\xfe;\x00\x88\xcen\xbe\xfe\x97Hd\xb3շ\xf2\xea\xff\xe2.\xfb\xca\xd37ju\xb7q\v\xdfY" +
+	"*/֣C\x10\xf6t\xa9\xff\xdfH:/_\n\x14\x10NIv_\x8c\xe4{\x81Uɔ\xaf\x95;\x1d\xd7\xff\x84sH\xe4\aI\xbf\x0f\xbeS\xeel\\\xff\x13.\x19\xc3\x19\x8b;\xa5ܹ\xb8\xfe'\\\xd1" +
+	"z\xbf\x9a\xdci\xe5\xce+W2\xee\x8by\xee3\x16wV\xb9\vF\xfd_\x94\xd17~\xb3\xb8s\xca\xfdn\xd4\xffbF߸hq畻d\xd4\x7f\x8c\xbe!\x00\xc0\x1fV\xfd\xbf\xa0\xdce\xa3\xfeKF\xdf" +
+	"\xb8bq\x97\x94\xbb\xa6\xdcb#/\xe6\xdd\xfd\xdb\xea\xfb\x97\x95Ù\xfb\x13\x96\x18\xfdmn\x1c\xc9u\xab\xbf\xfd\x15\xbd\x17\x94\x1b0\xf2b\xde\xf5eN:\xce\x01g\x9e۠\\\x05(\x01\xe88\x92\xf5N:\xce" +
+	"\xb5\xca\xedVn\xd08\xbf\xb9q$\x0f9\xe98G\x94ۧ\xdcR\xa0\f\xa0\xe3H\x9e\xb2\xb8\xbdʅ\xcaU\x81>\x00\x1dGrЊ\xb3\xa1ܛ\xcaջ\xd4\xc5nu\xd3\xdb\t\x00Ng<W?\xb5u" +
+	";`\xff\nm\x97<\xbd\xf7\xc06\xb8\x85\xf7\xed\xd4\xf6\x11\x80\xd4\xdd͒<\xbd\xa7|\xa93\x9e\xab?\xa0\xf1\xf7Z\xdf\x0f\xa8\xff^\xdf1\xa1{8\xa4\x87\xfe \xc0*`\xdc\xfbo\xfa\xf7\xa4\xfe\xe4\xea\xfd=" +
+	"\xf5\xff\xbeO\xe6\xadw\xb7_\xe8\xbc\xfd\\\xbe\x1e\x04\x8d\xd6\x02\xecK\x8f\xf6o\xbc\x97\x17\x90\x9fn\xef\xe9<~\xa2\x166\xbd\xc3\xdd\xedw\xd3/\xe95\x7f\x8d\xa0\xbe\x90\xfcUz\xb4\xff\xef\x00g+:f"
+
+func init() {
+	schemas.RegisterCompressed(schema_8ca2b2347c23363b,
+		0x82b1fdde86dafbb8,
+		0x8ca2b2347c23363b,
+		0x937e891dceca484e,
+		0x9c07ad9958795fdf,
+		0xb4e9692c0c23f037,
+		0xd82e3fe12fc29370)
+}
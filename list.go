@@ -7,11 +7,12 @@ import (
 
 // A List is a reference to an array of values.
 type List struct {
-	seg    *Segment
-	off    Address
-	length int32
-	size   ObjectSize
-	flags  listFlags
+	seg        *Segment
+	off        Address
+	length     int32
+	size       ObjectSize
+	flags      listFlags
+	depthLimit uint
 }
 
 // newPrimitiveList allocates a new list of primitive values, preferring placement in s.
@@ -21,10 +22,11 @@ func newPrimitiveList(s *Segment, sz Size, n int32) (List, error) {
 		return List{}, err
 	}
 	return List{
-		seg:    s,
-		off:    addr,
-		length: n,
-		size:   ObjectSize{DataSize: sz},
+		seg:        s,
+		off:        addr,
+		length:     n,
+		size:       ObjectSize{DataSize: sz},
+		depthLimit: maxDepth,
 	}, nil
 }
 
@@ -42,11 +44,12 @@ func NewCompositeList(s *Segment, sz ObjectSize, n int32) (List, error) {
 	// Add tag word
 	s.writeRawPointer(addr, rawStructPointer(pointerOffset(n), sz))
 	return List{
-		seg:    s,
-		off:    addr.addSize(wordSize),
-		length: n,
-		size:   sz,
-		flags:  isCompositeList,
+		seg:        s,
+		off:        addr.addSize(wordSize),
+		length:     n,
+		size:       sz,
+		flags:      isCompositeList,
+		depthLimit: maxDepth,
 	}, nil
 }
 
@@ -151,22 +154,43 @@ func (p List) elem(i int) (addr Address, sz Size) {
 	return p.off.element(int32(i), p.size.totalSize()), p.size.totalSize()
 }
 
-func (p List) slice(i int) []byte {
-	addr, sz := p.elem(i)
-	return p.seg.slice(addr, sz)
+// primitiveElem returns the address of the i'th element's value, which
+// the caller will access as an object of size expectedSize.  Struct
+// lists are accepted as long as their elements are large enough, since
+// a list of primitives may be upgraded to a list of structs whose first
+// field is the element.  It returns errOutOfBounds if i is out of range
+// and errListSize for bit lists and lists whose elements are too small.
+// Readers should use the zero value on error.
+func (p List) primitiveElem(i int, expectedSize ObjectSize) (Address, error) {
+	if p.seg == nil || i < 0 || i >= int(p.length) {
+		return 0, errOutOfBounds
+	}
+	if p.flags&isBitList != 0 || p.size.DataSize < expectedSize.DataSize || p.size.PointerCount < expectedSize.PointerCount {
+		return 0, errListSize
+	}
+	addr := p.off.element(int32(i), p.size.totalSize())
+	if expectedSize.PointerCount > 0 {
+		// Pointers come after the data section.
+		addr = addr.addSize(p.size.DataSize)
+	}
+	return addr, nil
 }
 
-// Struct returns the i'th element as a struct.
+// Struct returns the i'th element as a struct.  Elements of primitive
+// and pointer lists are returned as structs whose first field is the
+// element.  Bit lists can't be read as structs, so Struct returns an
+// invalid Struct for them.
 func (p List) Struct(i int) Struct {
-	if p.flags&isBitList != 0 {
+	if p.flags&isBitList != 0 || p.seg == nil || i < 0 || i >= int(p.length) {
 		return Struct{}
 	}
 	addr, _ := p.elem(i)
 	return Struct{
-		seg:   p.seg,
-		off:   addr,
-		size:  p.size,
-		flags: isListMember,
+		seg:        p.seg,
+		off:        addr,
+		size:       p.size,
+		flags:      isListMember,
+		depthLimit: p.depthLimit,
 	}
 }
 
@@ -175,6 +199,9 @@ func (p List) SetStruct(i int, s Struct) error {
 	if p.flags&isBitList != 0 {
 		return errBitListStruct
 	}
+	if p.seg == nil || i < 0 || i >= int(p.length) {
+		return errOutOfBounds
+	}
 	return copyStruct(copyContext{}, p.Struct(i), s)
 }
 
@@ -188,35 +215,62 @@ func NewBitList(s *Segment, n int32) (BitList, error) {
 		return BitList{}, err
 	}
 	return BitList{List{
-		seg:    s,
-		off:    addr,
-		length: n,
-		flags:  isBitList,
+		seg:        s,
+		off:        addr,
+		length:     n,
+		flags:      isBitList,
+		depthLimit: maxDepth,
 	}}, nil
 }
 
-// At returns the i'th bit.
+// At returns the i'th bit.  If i is out of bounds or the list is not a
+// list of bits, then At returns false.
 func (p BitList) At(i int) bool {
-	b := p.slice(i)
-	if b == nil {
+	addr, err := p.bitAddress(i)
+	if err != nil {
 		return false
 	}
-	bit := BitOffset(i)
-	return b[0]&bit.mask() != 0
+	return p.seg.readUint8(addr)&BitOffset(i).mask() != 0
 }
 
-// Set sets the i'th bit to v.
+// Set sets the i'th bit to v.  It panics if i is out of bounds or the
+// list is not a list of bits.
 func (p BitList) Set(i int, v bool) {
-	b := p.slice(i)
-	if b == nil {
-		panic(errOutOfBounds)
+	if err := p.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th bit to v, returning an error if i is out of
+// bounds or the list is not a list of bits.
+func (p BitList) SetChecked(i int, v bool) error {
+	addr, err := p.bitAddress(i)
+	if err != nil {
+		return err
+	}
+	if p.seg.readOnly {
+		return errReadOnly
 	}
 	bit := BitOffset(i)
+	b := p.seg.readUint8(addr)
 	if v {
-		b[0] |= bit.mask()
+		b |= bit.mask()
 	} else {
-		b[0] &^= bit.mask()
+		b &^= bit.mask()
 	}
+	p.seg.writeUint8(addr, b)
+	return nil
+}
+
+// bitAddress returns the address of the byte that holds the i'th bit.
+func (p BitList) bitAddress(i int) (Address, error) {
+	if p.seg == nil || i < 0 || i >= int(p.length) {
+		return 0, errOutOfBounds
+	}
+	if p.flags&isBitList == 0 {
+		return 0, errListSize
+	}
+	return p.off.addOffset(BitOffset(i).offset()), nil
 }
 
 // A PointerList is a reference to an array of pointers.
@@ -229,25 +283,52 @@ func NewPointerList(s *Segment, n int32) (PointerList, error) {
 		return PointerList{}, err
 	}
 	return PointerList{List{
-		seg:    s,
-		off:    addr,
-		length: n,
-		size:   ObjectSize{PointerCount: 1},
+		seg:        s,
+		off:        addr,
+		length:     n,
+		size:       ObjectSize{PointerCount: 1},
+		depthLimit: maxDepth,
 	}}, nil
 }
 
 // At returns the i'th pointer in the list.
 func (p PointerList) At(i int) (Pointer, error) {
-	addr, _ := p.elem(i)
-	return p.seg.readPtr(addr)
+	addr, err := p.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return nil, nil
+	}
+	return p.seg.readPtr(addr, p.depthLimit)
 }
 
 // Set sets the i'th pointer in the list to v.
 func (p PointerList) Set(i int, v Pointer) error {
-	addr, _ := p.elem(i)
+	addr, err := p.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return err
+	}
 	return p.seg.writePtr(copyContext{}, addr, v)
 }
 
+// Disown sets the i'th pointer in the list to null and returns the
+// object it referred to as an orphan.
+func (p PointerList) Disown(i int) (Orphan, error) {
+	addr, err := p.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return Orphan{}, nil
+	}
+	return disown(p.seg, addr, p.depthLimit)
+}
+
+// Adopt sets the i'th pointer in the list to o's object without
+// copying it.  o must be in the same message as the list.
+func (p PointerList) Adopt(i int, o Orphan) error {
+	addr, err := p.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return err
+	}
+	return adopt(p.seg, addr, o)
+}
+
 // TextList is an array of pointers to strings.
 type TextList struct{ List }
 
@@ -262,8 +343,11 @@ func NewTextList(s *Segment, n int32) (TextList, error) {
 
 // At returns the i'th string in the list.
 func (l TextList) At(i int) (string, error) {
-	addr, _ := l.elem(i)
-	p, err := l.seg.readPtr(addr)
+	addr, err := l.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return "", nil
+	}
+	p, err := l.seg.readPtr(addr, l.depthLimit)
 	if err != nil {
 		return "", err
 	}
@@ -272,7 +356,10 @@ func (l TextList) At(i int) (string, error) {
 
 // Set sets the i'th string in the list to v.
 func (l TextList) Set(i int, v string) error {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return err
+	}
 	p, err := NewText(l.seg, v)
 	if err != nil {
 		return err
@@ -294,8 +381,11 @@ func NewDataList(s *Segment, n int32) (DataList, error) {
 
 // At returns the i'th data in the list.
 func (l DataList) At(i int) ([]byte, error) {
-	addr, _ := l.elem(i)
-	p, err := l.seg.readPtr(addr)
+	addr, err := l.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return nil, nil
+	}
+	p, err := l.seg.readPtr(addr, l.depthLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -304,7 +394,10 @@ func (l DataList) At(i int) ([]byte, error) {
 
 // Set sets the i'th data in the list to v.
 func (l DataList) Set(i int, v []byte) error {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{PointerCount: 1})
+	if err != nil {
+		return err
+	}
 	p, err := NewData(l.seg, v)
 	if err != nil {
 		return err
@@ -319,8 +412,9 @@ type VoidList struct{ List }
 // s is only used for Segment()'s return value.
 func NewVoidList(s *Segment, n int32) VoidList {
 	return VoidList{List{
-		seg:    s,
-		length: n,
+		seg:        s,
+		length:     n,
+		depthLimit: maxDepth,
 	}}
 }
 
@@ -409,20 +503,33 @@ func toOneByteList(p Pointer) (l List, ok bool) {
 
 // At returns the i'th element.
 func (l UInt8List) At(i int) uint8 {
-	b := l.slice(i)
-	if b == nil {
-		panic(errOutOfBounds)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 1})
+	if err != nil {
+		return 0
 	}
-	return b[0]
+	return l.seg.readUint8(addr)
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l UInt8List) Set(i int, v uint8) {
-	b := l.slice(i)
-	if b == nil {
-		panic(errOutOfBounds)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
 	}
-	b[0] = v
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l UInt8List) SetChecked(i int, v uint8) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 1})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
+	l.seg.writeUint8(addr, v)
+	return nil
 }
 
 // Int8List is an array of Int8 values.
@@ -439,20 +546,33 @@ func NewInt8List(s *Segment, n int32) (Int8List, error) {
 
 // At returns the i'th element.
 func (l Int8List) At(i int) int8 {
-	b := l.slice(i)
-	if b == nil {
-		panic(errOutOfBounds)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 1})
+	if err != nil {
+		return 0
 	}
-	return int8(b[0])
+	return int8(l.seg.readUint8(addr))
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l Int8List) Set(i int, v int8) {
-	b := l.slice(i)
-	if b == nil {
-		panic(errOutOfBounds)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
 	}
-	b[0] = uint8(v)
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l Int8List) SetChecked(i int, v int8) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 1})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
+	l.seg.writeUint8(addr, uint8(v))
+	return nil
 }
 
 // A UInt16List is an array of UInt16 values.
@@ -469,14 +589,33 @@ func NewUInt16List(s *Segment, n int32) (UInt16List, error) {
 
 // At returns the i'th element.
 func (l UInt16List) At(i int) uint16 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 2})
+	if err != nil {
+		return 0
+	}
 	return l.seg.readUint16(addr)
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l UInt16List) Set(i int, v uint16) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l UInt16List) SetChecked(i int, v uint16) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 2})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint16(addr, v)
+	return nil
 }
 
 // Int16List is an array of Int16 values.
@@ -493,14 +632,33 @@ func NewInt16List(s *Segment, n int32) (Int16List, error) {
 
 // At returns the i'th element.
 func (l Int16List) At(i int) int16 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 2})
+	if err != nil {
+		return 0
+	}
 	return int16(l.seg.readUint16(addr))
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l Int16List) Set(i int, v int16) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l Int16List) SetChecked(i int, v int16) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 2})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint16(addr, uint16(v))
+	return nil
 }
 
 // UInt32List is an array of UInt32 values.
@@ -517,14 +675,33 @@ func NewUInt32List(s *Segment, n int32) (UInt32List, error) {
 
 // At returns the i'th element.
 func (l UInt32List) At(i int) uint32 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 4})
+	if err != nil {
+		return 0
+	}
 	return l.seg.readUint32(addr)
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l UInt32List) Set(i int, v uint32) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l UInt32List) SetChecked(i int, v uint32) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 4})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint32(addr, v)
+	return nil
 }
 
 // Int32List is an array of Int32 values.
@@ -541,14 +718,33 @@ func NewInt32List(s *Segment, n int32) (Int32List, error) {
 
 // At returns the i'th element.
 func (l Int32List) At(i int) int32 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 4})
+	if err != nil {
+		return 0
+	}
 	return int32(l.seg.readUint32(addr))
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l Int32List) Set(i int, v int32) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l Int32List) SetChecked(i int, v int32) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 4})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint32(addr, uint32(v))
+	return nil
 }
 
 // UInt64List is an array of UInt64 values.
@@ -565,14 +761,33 @@ func NewUInt64List(s *Segment, n int32) (UInt64List, error) {
 
 // At returns the i'th element.
 func (l UInt64List) At(i int) uint64 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 8})
+	if err != nil {
+		return 0
+	}
 	return l.seg.readUint64(addr)
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l UInt64List) Set(i int, v uint64) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l UInt64List) SetChecked(i int, v uint64) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 8})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint64(addr, v)
+	return nil
 }
 
 // Int64List is an array of Int64 values.
@@ -589,14 +804,33 @@ func NewInt64List(s *Segment, n int32) (Int64List, error) {
 
 // At returns the i'th element.
 func (l Int64List) At(i int) int64 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 8})
+	if err != nil {
+		return 0
+	}
 	return int64(l.seg.readUint64(addr))
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l Int64List) Set(i int, v int64) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l Int64List) SetChecked(i int, v int64) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 8})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint64(addr, uint64(v))
+	return nil
 }
 
 // Float32List is an array of Float32 values.
@@ -604,7 +838,7 @@ type Float32List struct{ List }
 
 // NewFloat32List creates a new list of Float32, preferring placement in s.
 func NewFloat32List(s *Segment, n int32) (Float32List, error) {
-	l, err := newPrimitiveList(s, 8, n)
+	l, err := newPrimitiveList(s, 4, n)
 	if err != nil {
 		return Float32List{}, err
 	}
@@ -613,14 +847,33 @@ func NewFloat32List(s *Segment, n int32) (Float32List, error) {
 
 // At returns the i'th element.
 func (l Float32List) At(i int) float32 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 4})
+	if err != nil {
+		return 0
+	}
 	return math.Float32frombits(l.seg.readUint32(addr))
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l Float32List) Set(i int, v float32) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l Float32List) SetChecked(i int, v float32) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 4})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint32(addr, math.Float32bits(v))
+	return nil
 }
 
 // Float64List is an array of Float64 values.
@@ -637,14 +890,33 @@ func NewFloat64List(s *Segment, n int32) (Float64List, error) {
 
 // At returns the i'th element.
 func (l Float64List) At(i int) float64 {
-	addr, _ := l.elem(i)
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 8})
+	if err != nil {
+		return 0
+	}
 	return math.Float64frombits(l.seg.readUint64(addr))
 }
 
-// Set sets the i'th element to v.
+// Set sets the i'th element to v.  It panics if i is out of bounds or
+// the list's elements are too small to hold v.
 func (l Float64List) Set(i int, v float64) {
-	addr, _ := l.elem(i)
+	if err := l.SetChecked(i, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetChecked sets the i'th element to v, returning an error if i is
+// out of bounds or the list's elements are too small to hold v.
+func (l Float64List) SetChecked(i int, v float64) error {
+	addr, err := l.primitiveElem(i, ObjectSize{DataSize: 8})
+	if err != nil {
+		return err
+	}
+	if l.seg.readOnly {
+		return errReadOnly
+	}
 	l.seg.writeUint64(addr, math.Float64bits(v))
+	return nil
 }
 
 type listFlags uint8
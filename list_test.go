@@ -0,0 +1,236 @@
+package capnp
+
+import (
+	"testing"
+)
+
+func TestPrimitiveListAsStructList(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nums, err := NewUInt32List(seg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nums.Set(0, 7)
+	nums.Set(1, 9)
+	if x := nums.Struct(1).Uint32(0); x != 9 {
+		t.Errorf("UInt32List.Struct(1).Uint32(0) = %d; want 9", x)
+	}
+	if x := nums.Struct(1).Uint32(4); x != 0 {
+		t.Errorf("UInt32List.Struct(1).Uint32(4) = %d; want 0", x)
+	}
+
+	texts, err := NewTextList(seg, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := texts.Set(0, "hi"); err != nil {
+		t.Fatal(err)
+	}
+	p, err := texts.Struct(0).Pointer(0)
+	if err != nil {
+		t.Fatal("TextList.Struct(0).Pointer(0):", err)
+	}
+	if s := ToText(p); s != "hi" {
+		t.Errorf("TextList.Struct(0).Pointer(0) = %q; want \"hi\"", s)
+	}
+
+	bits, err := NewBitList(seg, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := bits.Struct(0); IsValid(s) {
+		t.Errorf("BitList.Struct(0) = %v; want invalid struct", s)
+	}
+}
+
+func TestStructListAsPrimitiveList(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewCompositeList(seg, ObjectSize{DataSize: 16, PointerCount: 1}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < l.Len(); i++ {
+		s := l.Struct(i)
+		s.SetUint64(0, uint64(i+1))
+		s.SetUint64(8, 0xffffffffffffffff)
+		text, err := NewText(seg, "elem")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetPointer(0, text); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if x := (UInt8List{l}).At(1); x != 2 {
+		t.Errorf("UInt8List.At(1) = %d; want 2", x)
+	}
+	if x := (Int16List{l}).At(1); x != 2 {
+		t.Errorf("Int16List.At(1) = %d; want 2", x)
+	}
+	if x := (UInt32List{l}).At(1); x != 2 {
+		t.Errorf("UInt32List.At(1) = %d; want 2", x)
+	}
+	if x := (UInt64List{l}).At(1); x != 2 {
+		t.Errorf("UInt64List.At(1) = %d; want 2", x)
+	}
+	if s, err := (TextList{l}).At(1); err != nil || s != "elem" {
+		t.Errorf("TextList.At(1) = %q, %v; want \"elem\", <nil>", s, err)
+	}
+	if b, err := (DataList{l}).At(0); err != nil || string(b) != "elem\x00" {
+		t.Errorf("DataList.At(0) = %q, %v; want \"elem\\x00\", <nil>", b, err)
+	}
+	if (BitList{l}).At(0) {
+		t.Error("BitList.At(0) on struct list = true; want false")
+	}
+
+	(UInt16List{l}).Set(0, 42)
+	if x := l.Struct(0).Uint64(0); x != 42 {
+		t.Errorf("after UInt16List.Set(0, 42), Struct(0).Uint64(0) = %d; want 42", x)
+	}
+	if x := l.Struct(0).Uint64(8); x != 0xffffffffffffffff {
+		t.Errorf("after UInt16List.Set(0, 42), Struct(0).Uint64(8) = %#x; want 0xffffffffffffffff", x)
+	}
+	if err := (TextList{l}).Set(0, "new"); err != nil {
+		t.Fatal("TextList.Set:", err)
+	}
+	if p, _ := l.Struct(0).Pointer(0); ToText(p) != "new" {
+		t.Errorf("after TextList.Set(0, \"new\"), Struct(0).Pointer(0) = %q; want \"new\"", ToText(p))
+	}
+}
+
+func TestIncompatibleListElements(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptrs, err := NewCompositeList(seg, ObjectSize{PointerCount: 1}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := NewCompositeList(seg, ObjectSize{DataSize: 8}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Struct(0).SetUint64(0, 0xffffffffffffffff)
+	bits, err := NewBitList(seg, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bits.Set(0, true)
+	bytes, err := NewUInt8List(seg, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytes.Set(0, 0xff)
+
+	if x := (UInt32List{ptrs}).At(0); x != 0 {
+		t.Errorf("UInt32List.At(0) on pointer-only structs = %d; want 0", x)
+	}
+	if x := (UInt64List{bytes.List}).At(0); x != 0 {
+		t.Errorf("UInt64List.At(0) on byte list = %d; want 0", x)
+	}
+	if x := (UInt8List{bits.List}).At(0); x != 0 {
+		t.Errorf("UInt8List.At(0) on bit list = %d; want 0", x)
+	}
+	if (BitList{bytes.List}).At(0) {
+		t.Error("BitList.At(0) on byte list = true; want false")
+	}
+	if p, err := (PointerList{data}).At(0); err != nil || IsValid(p) {
+		t.Errorf("PointerList.At(0) on data-only structs = %v, %v; want <nil>, <nil>", p, err)
+	}
+	if s, err := (TextList{data}).At(0); err != nil || s != "" {
+		t.Errorf("TextList.At(0) on data-only structs = %q, %v; want \"\", <nil>", s, err)
+	}
+
+	if err := catchPanic(func() { (UInt32List{ptrs}).Set(0, 1) }); err != errListSize {
+		t.Errorf("UInt32List.Set(0, 1) on pointer-only structs panic = %v; want %v", err, errListSize)
+	}
+	if err := catchPanic(func() { (BitList{bytes.List}).Set(0, true) }); err != errListSize {
+		t.Errorf("BitList.Set(0, true) on byte list panic = %v; want %v", err, errListSize)
+	}
+	if err := (TextList{data}).Set(0, "x"); err != errListSize {
+		t.Errorf("TextList.Set(0, \"x\") on data-only structs error = %v; want %v", err, errListSize)
+	}
+}
+
+func TestNewFloat32ListSize(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewFloat32List(seg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Set(1, 1.5)
+	if want := (ObjectSize{DataSize: 4}); l.size != want {
+		t.Errorf("NewFloat32List element size = %v; want %v", l.size, want)
+	}
+	if x := l.At(1); x != 1.5 {
+		t.Errorf("At(1) = %v; want 1.5", x)
+	}
+}
+
+func TestListOutOfBounds(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nums, err := NewUInt64List(seg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nums.Set(1, 42)
+	texts, err := NewTextList(seg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bits, err := NewBitList(seg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comp, err := NewCompositeList(seg, ObjectSize{DataSize: 8}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []int{-1, 2} {
+		if x := nums.At(i); x != 0 {
+			t.Errorf("UInt64List.At(%d) = %d; want 0", i, x)
+		}
+		if err := nums.SetChecked(i, 1); err != errOutOfBounds {
+			t.Errorf("UInt64List.SetChecked(%d, 1) error = %v; want %v", i, err, errOutOfBounds)
+		}
+		if err := catchPanic(func() { nums.Set(i, 1) }); err != errOutOfBounds {
+			t.Errorf("UInt64List.Set(%d, 1) panic = %v; want %v", i, err, errOutOfBounds)
+		}
+		if bits.At(i) {
+			t.Errorf("BitList.At(%d) = true; want false", i)
+		}
+		if err := bits.SetChecked(i, true); err != errOutOfBounds {
+			t.Errorf("BitList.SetChecked(%d, true) error = %v; want %v", i, err, errOutOfBounds)
+		}
+		if s, err := texts.At(i); s != "" || err != nil {
+			t.Errorf("TextList.At(%d) = %q, %v; want \"\", <nil>", i, s, err)
+		}
+		if err := texts.Set(i, "x"); err != errOutOfBounds {
+			t.Errorf("TextList.Set(%d, \"x\") error = %v; want %v", i, err, errOutOfBounds)
+		}
+		if s := comp.Struct(i); IsValid(s) {
+			t.Errorf("List.Struct(%d) = %v; want invalid struct", i, s)
+		}
+		if err := comp.SetStruct(i, comp.Struct(0)); err != errOutOfBounds {
+			t.Errorf("List.SetStruct(%d, ...) error = %v; want %v", i, err, errOutOfBounds)
+		}
+	}
+	if err := nums.SetChecked(0, 7); err != nil || nums.At(0) != 7 {
+		t.Errorf("UInt64List.SetChecked(0, 7) = %v, At(0) = %d; want <nil>, 7", err, nums.At(0))
+	}
+}
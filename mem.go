@@ -1,17 +1,28 @@
 package capnp
 
 import (
+	"bufio"
 	"encoding/binary"
 	"errors"
 	"io"
 	"math"
+	"net"
+	"sync"
 
 	"zombiezen.com/go/capnproto/internal/packed"
 )
 
 // A Message is a tree of Cap'n Proto objects, split into one or more
 // segments of contiguous memory.  The only required field is Arena.
+//
+// Reading a Message is safe to do from multiple goroutines at once, as
+// long as no goroutine is modifying the message or its arena.
 type Message struct {
+	// rlimit must be first so that it is 64-bit aligned.
+	// See sync/atomic docs.
+	rlimit     ReadLimiter
+	rlimitInit sync.Once
+
 	Arena Arena
 
 	// CapTable is the indexed list of the clients referenced in the
@@ -23,35 +34,93 @@ type Message struct {
 	// more details on the capability table.
 	CapTable []Client
 
-	segs map[SegmentID]*Segment
+	// TraverseLimit limits how many total bytes of data are allowed to be
+	// traversed while reading.  Traversal is counted each time a pointer
+	// is followed, so reading the same sub-object multiple times will
+	// count it multiple times.  Once the limit is reached, pointer
+	// accessors will return ErrTraverseLimit.  See
+	// https://capnproto.org/encoding.html#amplification-attack for more
+	// details on this security measure.
+	//
+	// If not set, this defaults to 64 MiB.
+	TraverseLimit uint64
+
+	// DepthLimit limits how deeply nested a message structure can be.
+	// Once the limit is reached, pointer accessors will return
+	// ErrDepthLimit.
+	//
+	// If not set, this defaults to 64.
+	DepthLimit uint
+
+	// mu guards segs and firstSeg, which are populated lazily by reads.
+	mu sync.Mutex
+
+	// firstSeg is the storage for segment 0, which avoids allocating
+	// a Segment for single-segment messages.
+	firstSeg Segment
+	segs     map[SegmentID]*Segment
 }
 
 // NewMessage creates a message with a new root and returns the first
 // segment.  It is an error to call NewMessage on an arena with data in it.
 func NewMessage(arena Arena) (msg *Message, first *Segment, err error) {
-	msg = &Message{Arena: arena}
+	msg = new(Message)
+	first, err = msg.Reset(arena)
+	if err != nil {
+		return nil, nil, err
+	}
+	return msg, first, nil
+}
+
+// Reset empties the message so that it can be reused with arena, then
+// allocates a new root and returns the first segment, just like
+// NewMessage.  It is an error to call Reset with an arena that has data
+// in it.  The message's segment map and capability table are cleared
+// but keep their storage, and the read limiter is reset.  TraverseLimit
+// and DepthLimit are left untouched.  Objects read from or allocated in
+// the message before Reset must not be used afterward.
+func (m *Message) Reset(arena Arena) (first *Segment, err error) {
+	m.reset(arena)
 	switch arena.NumSegments() {
 	case 0:
-		first, err = msg.allocSegment(defaultBufferSize)
+		// Ask for just the root pointer, so that the arena picks the size
+		// of the first segment.  MultiSegment and PooledArena round it up
+		// to defaultBufferSize, and NewArena uses FirstSegmentSize.
+		first, err = m.allocSegment(wordSize)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 	case 1:
-		first, err = msg.Segment(0)
+		first, err = m.Segment(0)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		if len(first.data) > 0 {
-			return nil, nil, errHasData
+			return nil, errHasData
 		}
 		if !hasCapacity(first.data, wordSize) {
-			return nil, nil, errSegmentTooSmall
+			return nil, errSegmentTooSmall
 		}
 	default:
-		return nil, nil, errHasData
+		return nil, errHasData
 	}
 	alloc(first, wordSize) // allocate root
-	return msg, first, nil
+	return first, nil
+}
+
+// reset clears the message's state and sets its arena without
+// allocating a root.
+func (m *Message) reset(arena Arena) {
+	for i := range m.CapTable {
+		m.CapTable[i] = nil
+	}
+	m.CapTable = m.CapTable[:0]
+	for id := range m.segs {
+		delete(m.segs, id)
+	}
+	m.firstSeg = Segment{}
+	m.rlimitInit = sync.Once{}
+	m.Arena = arena
 }
 
 // Root returns the pointer to the message's root object.
@@ -72,6 +141,57 @@ func (m *Message) SetRoot(p Pointer) error {
 	return s.root().Set(0, p)
 }
 
+// Compact returns a new message holding a copy of the objects reachable
+// from m's root in a single segment of exactly the needed size, without
+// far pointers or unreachable data.  The copy is laid out in canonical
+// form, as described in Canonicalize.  m's capability table is copied
+// to the new message.  An object referenced by multiple pointers in m
+// is copied once for each pointer.
+func (m *Message) Compact() (*Message, error) {
+	root, err := m.Root()
+	if err != nil {
+		return nil, err
+	}
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+	if err := canonicalPtr(seg, 0, root); err != nil {
+		return nil, err
+	}
+	data := make([]byte, len(seg.data))
+	copy(data, seg.data)
+	return &Message{
+		Arena:         SingleSegment(data),
+		CapTable:      append([]Client(nil), m.CapTable...),
+		TraverseLimit: m.TraverseLimit,
+		DepthLimit:    m.DepthLimit,
+	}, nil
+}
+
+// ReadLimiter returns the message's read limiter.  Useful if you want
+// to reset the traversal limit while reading.
+func (m *Message) ReadLimiter() *ReadLimiter {
+	m.rlimitInit.Do(func() {
+		m.rlimit.Reset(m.traverseLimit())
+	})
+	return &m.rlimit
+}
+
+func (m *Message) traverseLimit() uint64 {
+	if m.TraverseLimit == 0 {
+		return defaultTraverseLimit
+	}
+	return m.TraverseLimit
+}
+
+func (m *Message) depthLimit() uint {
+	if m.DepthLimit == 0 {
+		return defaultDepthLimit
+	}
+	return m.DepthLimit
+}
+
 // AddCap appends a capability to the message's capability table and
 // returns its ID.
 func (m *Message) AddCap(c Client) CapabilityID {
@@ -90,6 +210,8 @@ func (m *Message) Segment(id SegmentID) (*Segment, error) {
 	if isInt32Bit() && id > maxInt32 {
 		return nil, errSegment32Bit
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if seg := m.segment(id); seg != nil {
 		return seg, nil
 	}
@@ -103,6 +225,8 @@ func (m *Message) Segment(id SegmentID) (*Segment, error) {
 	return m.setSegment(id, data), nil
 }
 
+// segment returns the already loaded segment with the given ID or nil.
+// The caller must hold m.mu.
 func (m *Message) segment(id SegmentID) *Segment {
 	if m.segs == nil {
 		return nil
@@ -110,6 +234,8 @@ func (m *Message) segment(id SegmentID) *Segment {
 	return m.segs[id]
 }
 
+// setSegment records data as the segment with the given ID.  The
+// caller must hold m.mu.
 func (m *Message) setSegment(id SegmentID, data []byte) *Segment {
 	if m.segs == nil {
 		m.segs = make(map[SegmentID]*Segment)
@@ -117,10 +243,15 @@ func (m *Message) setSegment(id SegmentID, data []byte) *Segment {
 		seg.data = data
 		return seg
 	}
-	seg := &Segment{
-		id:   id,
-		msg:  m,
-		data: data,
+	seg := &m.firstSeg
+	if id != 0 {
+		seg = new(Segment)
+	}
+	*seg = Segment{
+		id:       id,
+		msg:      m,
+		data:     data,
+		readOnly: isReadOnly(m.Arena),
 	}
 	m.segs[id] = seg
 	return seg
@@ -129,6 +260,8 @@ func (m *Message) setSegment(id SegmentID, data []byte) *Segment {
 // allocSegment creates or resizes an existing segment such that
 // cap(seg.Data) - len(seg.Data) >= sz.
 func (m *Message) allocSegment(sz Size) (*Segment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	id, data, err := m.Arena.Allocate(sz, m.segs)
 	if err != nil {
 		return nil, err
@@ -183,6 +316,15 @@ type Arena interface {
 	Allocate(minsz Size, segs map[SegmentID]*Segment) (SegmentID, []byte, error)
 }
 
+// isReadOnly reports whether the data of arena's segments can't be
+// written to, as for a MappedArena.
+func isReadOnly(arena Arena) bool {
+	ro, ok := arena.(interface {
+		readOnly() bool
+	})
+	return ok && ro.readOnly()
+}
+
 // Arena parameters.  Must be a multiple of wordSize.
 const (
 	defaultBufferSize      = 4096
@@ -288,12 +430,248 @@ func (msa *multiSegmentArena) Allocate(sz Size, segs map[SegmentID]*Segment) (Se
 	return id, buf, nil
 }
 
+// ArenaOptions configures an arena created by NewArena.
+type ArenaOptions struct {
+	// FirstSegmentSize is the capacity in bytes of the first segment.
+	// If zero, a default of 4 KiB is used.
+	FirstSegmentSize int
+
+	// GrowthFactor is the ratio of each new segment's capacity to the
+	// capacity of the segment before it.  If less than 1, every segment
+	// has a capacity of FirstSegmentSize.  A segment is always large
+	// enough for the allocation that created it.
+	GrowthFactor float64
+
+	// MaxSegmentSize limits the capacity of new segments, unless a
+	// single allocation needs more.  If zero, segments grow without
+	// limit.
+	MaxSegmentSize int
+
+	// Quota limits the total capacity in bytes of the arena's segments.
+	// Allocations beyond the quota fail with ErrArenaQuota.  If zero,
+	// there is no limit.
+	Quota int
+}
+
+// ErrArenaQuota is returned when an allocation would exceed an arena's
+// quota.
+var ErrArenaQuota = errors.New("capnp: arena quota exceeded")
+
+type optionsArena struct {
+	opts  ArenaOptions
+	segs  [][]byte
+	total int
+}
+
+// NewArena returns a new multi-segment arena that allocates segments
+// according to opts.
+func NewArena(opts ArenaOptions) Arena {
+	if opts.FirstSegmentSize <= 0 {
+		opts.FirstSegmentSize = defaultBufferSize
+	}
+	return &optionsArena{opts: opts}
+}
+
+func (oa *optionsArena) NumSegments() int64 {
+	return int64(len(oa.segs))
+}
+
+func (oa *optionsArena) Data(id SegmentID) ([]byte, error) {
+	if int64(id) >= int64(len(oa.segs)) {
+		return nil, errSegmentOutOfBounds
+	}
+	return oa.segs[id], nil
+}
+
+func (oa *optionsArena) Allocate(sz Size, segs map[SegmentID]*Segment) (SegmentID, []byte, error) {
+	for i, data := range oa.segs {
+		id := SegmentID(i)
+		if s := segs[id]; s != nil {
+			data = s.data
+		}
+		if hasCapacity(data, sz) {
+			return id, data, nil
+		}
+	}
+	need := int64(sz.padToWord())
+	n := oa.nextSegmentSize()
+	if n < need {
+		n = need
+	}
+	if oa.opts.Quota > 0 {
+		left := int64(oa.opts.Quota-oa.total) &^ int64(wordSize-1)
+		if left < need {
+			return 0, nil, ErrArenaQuota
+		}
+		if n > left {
+			n = left
+		}
+	}
+	if n > int64(maxSize) {
+		return 0, nil, errOverlarge
+	}
+	buf := make([]byte, 0, int(n))
+	id := SegmentID(len(oa.segs))
+	oa.segs = append(oa.segs, buf)
+	oa.total += int(n)
+	return id, buf, nil
+}
+
+// nextSegmentSize returns the preferred capacity of the next segment.
+func (oa *optionsArena) nextSegmentSize() int64 {
+	n := float64(oa.opts.FirstSegmentSize)
+	if len(oa.segs) > 0 && oa.opts.GrowthFactor > 1 {
+		n = float64(cap(oa.segs[len(oa.segs)-1])) * oa.opts.GrowthFactor
+	}
+	clamped := false
+	if max := float64(oa.opts.MaxSegmentSize); max > 0 && n > max {
+		n, clamped = max, true
+	}
+	if limit := float64(maxSize &^ Size(wordSize-1)); n > limit {
+		n, clamped = limit, true
+	}
+	if clamped {
+		// Rounding up would go past the limit.
+		return int64(n) &^ int64(wordSize-1)
+	}
+	return (int64(n) + int64(wordSize-1)) &^ int64(wordSize-1)
+}
+
+// A PooledArena is an Arena that allocates its segments from a pool of
+// buffers shared by all PooledArenas.  Calling Release once a message is
+// no longer needed returns the buffers to the pool and empties the
+// arena, so the arena can be passed to Message.Reset again.  In steady
+// state, building and encoding messages this way does not allocate.
+//
+// The zero value is an empty arena ready to use.  A PooledArena must not
+// be used by multiple messages at once.
+type PooledArena struct {
+	segs [][]byte
+}
+
+// NumSegments returns the number of segments in the arena.
+func (pa *PooledArena) NumSegments() int64 {
+	return int64(len(pa.segs))
+}
+
+// Data returns the data for the segment with the given ID.
+func (pa *PooledArena) Data(id SegmentID) ([]byte, error) {
+	if int64(id) >= int64(len(pa.segs)) {
+		return nil, errSegmentOutOfBounds
+	}
+	return pa.segs[id], nil
+}
+
+// Allocate returns an existing segment with enough capacity or adds a
+// new segment from the pool.
+func (pa *PooledArena) Allocate(sz Size, segs map[SegmentID]*Segment) (SegmentID, []byte, error) {
+	for i, data := range pa.segs {
+		id := SegmentID(i)
+		if s := segs[id]; s != nil {
+			data = s.data
+		}
+		if hasCapacity(data, sz) {
+			return id, data, nil
+		}
+	}
+	if sz < defaultBufferSize {
+		sz = defaultBufferSize
+	} else {
+		sz = sz.padToWord()
+	}
+	buf := getPooledBuffer(sz)
+	id := SegmentID(len(pa.segs))
+	pa.segs = append(pa.segs, buf)
+	return id, buf, nil
+}
+
+// Release returns the arena's buffers to the pool and empties the arena.
+// Any message using the arena must not be used after calling Release.
+func (pa *PooledArena) Release() {
+	for i, buf := range pa.segs {
+		putPooledBuffer(buf)
+		pa.segs[i] = nil
+	}
+	pa.segs = pa.segs[:0]
+}
+
+// maxPooledBuffers is the most buffers that the pool will retain.
+const maxPooledBuffers = 64
+
+// bufferPool holds the buffers released by PooledArenas.  A plain
+// free list is used instead of a sync.Pool because putting a slice
+// into a sync.Pool allocates.
+var bufferPool struct {
+	mu   sync.Mutex
+	bufs [][]byte
+}
+
+// getPooledBuffer returns an empty buffer with a capacity of at least sz.
+func getPooledBuffer(sz Size) []byte {
+	bufferPool.mu.Lock()
+	for i := len(bufferPool.bufs) - 1; i >= 0; i-- {
+		buf := bufferPool.bufs[i]
+		if Size(cap(buf)) < sz {
+			continue
+		}
+		last := len(bufferPool.bufs) - 1
+		bufferPool.bufs[i] = bufferPool.bufs[last]
+		bufferPool.bufs[last] = nil
+		bufferPool.bufs = bufferPool.bufs[:last]
+		bufferPool.mu.Unlock()
+		return buf[:0]
+	}
+	bufferPool.mu.Unlock()
+	return make([]byte, 0, int(sz))
+}
+
+// putPooledBuffer adds buf to the pool, unless the pool is full.
+func putPooledBuffer(buf []byte) {
+	bufferPool.mu.Lock()
+	if len(bufferPool.bufs) < maxPooledBuffers {
+		bufferPool.bufs = append(bufferPool.bufs, buf[:0])
+	}
+	bufferPool.mu.Unlock()
+}
+
 // A Decoder represents a framer that deserializes a particular Cap'n
 // Proto input stream.
 type Decoder struct {
 	r io.Reader
+
+	// MaxMessageSize is the largest message size in bytes, not counting
+	// the stream header, that Decode will accept.  Larger messages are
+	// rejected with ErrMessageTooLarge before any of their data is read.
+	// If zero, a default of 64 MiB is used.
+	MaxMessageSize uint64
+
+	// MaxSegments is the largest number of segments that Decode will
+	// accept in a single message.  Messages with more segments are
+	// rejected with ErrTooManySegments before the rest of the stream
+	// header is read.  If zero, a default of 512 is used.
+	MaxSegments int
+
+	hdrbuf []byte
+	sizes  []Size
+
+	// auto is set until the framing of a stream read by a decoder from
+	// NewAutoDecoder has been detected.  r is then a *bufio.Reader.
+	auto bool
+
+	// Used only when reuse is set.  See ReuseBuffer.
+	reuse bool
+	buf   []byte
+	segs  [][]byte
+	arena multiSegmentArena
+	msg   Message
 }
 
+// Default decoding limits.
+const (
+	defaultMaxMessageSize = 64 << 20 // 64 MiB
+	defaultMaxSegments    = 512
+)
+
 // NewDecoder creates a new Cap'n Proto framer that reads from r.
 func NewDecoder(r io.Reader) *Decoder {
 	return &Decoder{r: r}
@@ -305,49 +683,211 @@ func NewPackedDecoder(r io.Reader) *Decoder {
 	return NewDecoder(packed.NewReader(r))
 }
 
+// ReuseBuffer causes the decoder to reuse its buffer and Message on
+// subsequent calls to Decode, so that decoding a stream of messages
+// doesn't allocate once the buffer has grown to fit the largest one.
+// After calling ReuseBuffer, the message returned by Decode, along with
+// any objects read from it, is only valid until the next call to Decode.
+// Allocating in a decoded message is allowed, but the new objects are
+// also invalidated by the next call to Decode.
+func (d *Decoder) ReuseBuffer() {
+	d.reuse = true
+}
+
+// NewAutoDecoder creates a new Cap'n Proto framer that reads from r,
+// which may be either packed or unpacked.  The first call to Decode
+// reads the start of the stream to decide: a stream is taken to be
+// packed or unpacked if its first word is a plausible stream header in
+// only one of the two encodings, given the decoder's MaxSegments.  If
+// the first word is plausible in both or neither, Decode returns
+// ErrUnknownFraming.
+//
+// The decoder reads from r through a buffer, so it may read past the
+// end of the last message it decodes.
+func NewAutoDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), auto: true}
+}
+
+// detectFraming decides whether the stream of a decoder created by
+// NewAutoDecoder is packed and, if it is, unpacks d.r from then on.
+func (d *Decoder) detectFraming() error {
+	br := d.r.(*bufio.Reader)
+	// A packed word is at most a tag followed by the whole word.
+	prefix, err := br.Peek(1 + int(wordSize))
+	if len(prefix) == 0 {
+		return err
+	}
+	unpackedOK := len(prefix) >= int(wordSize) && d.isStreamStart(prefix[:wordSize])
+	word, ok := packed.FirstWord(prefix)
+	packedOK := ok && d.isStreamStart(word[:])
+	switch {
+	case unpackedOK && !packedOK:
+	case packedOK && !unpackedOK:
+		d.r = packed.NewReader(br)
+	case err != nil && err != io.EOF:
+		return err
+	default:
+		return ErrUnknownFraming
+	}
+	d.auto = false
+	return nil
+}
+
+// isStreamStart reports whether word could be the first word of a
+// stream header: the number of segments is within the decoder's limit
+// and the first segment isn't empty, since it holds the root.
+func (d *Decoder) isStreamStart(word []byte) bool {
+	maxSeg := binary.LittleEndian.Uint32(word)
+	firstSize := binary.LittleEndian.Uint32(word[msgHeaderSize:])
+	return uint64(maxSeg) < uint64(d.maxSegments()) && firstSize > 0
+}
+
 // Decode reads a message from the decoder stream.
 func (d *Decoder) Decode() (*Message, error) {
-	var maxSegBuf [msgHeaderSize]byte
-	if _, err := io.ReadFull(d.r, maxSegBuf[:]); err != nil {
+	if d.auto {
+		if err := d.detectFraming(); err != nil {
+			return nil, err
+		}
+	}
+	if cap(d.hdrbuf) < streamHeaderSize(0) {
+		d.hdrbuf = make([]byte, streamHeaderSize(0))
+	}
+	maxSegBuf := d.hdrbuf[:msgHeaderSize]
+	if _, err := io.ReadFull(d.r, maxSegBuf); err != nil {
 		return nil, err
 	}
-	maxSeg := binary.LittleEndian.Uint32(maxSegBuf[:])
+	maxSeg := binary.LittleEndian.Uint32(maxSegBuf)
+	if uint64(maxSeg) >= uint64(d.maxSegments()) {
+		return nil, ErrTooManySegments
+	}
 	hdrSize := streamHeaderSize(maxSeg)
-	hdr := make([]byte, hdrSize)
-	copy(hdr, maxSegBuf[:])
+	if cap(d.hdrbuf) < hdrSize {
+		hdr := make([]byte, hdrSize)
+		copy(hdr, maxSegBuf)
+		d.hdrbuf = hdr
+	}
+	hdr := d.hdrbuf[:hdrSize]
 	if _, err := io.ReadFull(d.r, hdr[msgHeaderSize:]); err != nil {
 		return nil, err
 	}
-	sizes, _, err := unmarshalStreamHeader(hdr)
-	if err != nil {
+	sizes, _, err := unmarshalStreamHeader(hdr, d.sizes)
+	if err == errOverlarge {
+		return nil, ErrMessageTooLarge
+	} else if err != nil {
 		return nil, err
 	}
+	d.sizes = sizes
 	total := totalSize(sizes)
-	// TODO(light): size check
-	buf := make([]byte, int(total))
+	if total > d.maxMessageSize() {
+		return nil, ErrMessageTooLarge
+	}
+	if !d.reuse {
+		buf := make([]byte, int(total))
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return nil, err
+		}
+		return &Message{Arena: demuxArena(sizes, buf)}, nil
+	}
+
+	if uint64(cap(d.buf)) < total {
+		d.buf = make([]byte, int(total))
+	}
+	buf := d.buf[:total]
 	if _, err := io.ReadFull(d.r, buf); err != nil {
 		return nil, err
 	}
-	return &Message{Arena: demuxArena(sizes, buf)}, nil
+	d.segs = d.segs[:0]
+	for _, sz := range sizes {
+		d.segs, buf = append(d.segs, buf[:sz:sz]), buf[sz:]
+	}
+	d.arena = multiSegmentArena(d.segs)
+	d.msg.reset(&d.arena)
+	return &d.msg, nil
+}
+
+func (d *Decoder) maxMessageSize() uint64 {
+	if d.MaxMessageSize == 0 {
+		return defaultMaxMessageSize
+	}
+	return d.MaxMessageSize
+}
+
+func (d *Decoder) maxSegments() int {
+	if d.MaxSegments <= 0 {
+		return defaultMaxSegments
+	}
+	return d.MaxSegments
 }
 
 // Unmarshal reads an unpacked serialized stream into a message.  No
 // copying is performed, so the objects in the returned message read
 // directly from data.
+//
+// Unmarshal applies the same default limits as a Decoder: messages
+// larger than 64 MiB are rejected with ErrMessageTooLarge and messages
+// with 512 or more segments with ErrTooManySegments.  Use
+// UnmarshalOptions to read messages past these limits.
 func Unmarshal(data []byte) (*Message, error) {
+	return UnmarshalOptions{}.Unmarshal(data)
+}
+
+// UnmarshalOptions holds the limits that Unmarshal checks a message
+// against.  The fields have the same meaning as the Decoder fields of
+// the same name.
+type UnmarshalOptions struct {
+	// MaxMessageSize is the largest message size in bytes, not counting
+	// the stream header.  If zero, a default of 64 MiB is used.
+	MaxMessageSize uint64
+
+	// MaxSegments is the largest number of segments in a message.  If
+	// zero, a default of 512 is used.
+	MaxSegments int
+}
+
+// Unmarshal reads an unpacked serialized stream into a message, like
+// the Unmarshal function, using the limits in o.
+func (o UnmarshalOptions) Unmarshal(data []byte) (*Message, error) {
 	if len(data) == 0 {
 		return nil, io.EOF
 	}
-	sizes, data, err := unmarshalStreamHeader(data)
-	if err != nil {
+	d := Decoder{MaxMessageSize: o.MaxMessageSize, MaxSegments: o.MaxSegments}
+	if len(data) >= msgHeaderSize {
+		maxSeg := binary.LittleEndian.Uint32(data)
+		if uint64(maxSeg) >= uint64(d.maxSegments()) {
+			return nil, ErrTooManySegments
+		}
+	}
+	sizes, data, err := unmarshalStreamHeader(data, nil)
+	if err == errOverlarge {
+		return nil, ErrMessageTooLarge
+	} else if err != nil {
 		return nil, err
 	}
-	if tot := totalSize(sizes); tot > uint64(len(data)) {
+	tot := totalSize(sizes)
+	if tot > d.maxMessageSize() {
+		return nil, ErrMessageTooLarge
+	}
+	if tot > uint64(len(data)) {
 		return nil, io.ErrUnexpectedEOF
 	}
 	return &Message{Arena: demuxArena(sizes, data)}, nil
 }
 
+// NextMessageSize returns the number of bytes taken up by the message
+// at the start of prefix, including its stream header.  prefix needs to
+// hold the whole stream header, but none of the message's segments.  If
+// prefix is too short to hold the header, NextMessageSize returns
+// io.ErrUnexpectedEOF.  The stream header of a message with n segments
+// is 4*(n+1) bytes, rounded up to a multiple of 8.
+func NextMessageSize(prefix []byte) (uint64, error) {
+	sizes, tail, err := unmarshalStreamHeader(prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	hdrSize := len(prefix) - len(tail)
+	return uint64(hdrSize) + totalSize(sizes), nil
+}
+
 // MustUnmarshalRoot reads an unpacked serialized stream and returns its
 // root pointer.  If there is any error, it panics.
 func MustUnmarshalRoot(data []byte) Pointer {
@@ -367,9 +907,12 @@ func MustUnmarshalRoot(data []byte) Pointer {
 type Encoder struct {
 	w      io.Writer
 	hdrbuf []byte
+	sizes  []Size
 
-	packed  bool
-	packbuf []byte
+	// bufs holds the header and segment data for a single vectored
+	// write.  vec is consumed by the write, so bufs keeps the storage.
+	bufs [][]byte
+	vec  net.Buffers
 }
 
 // NewEncoder creates a new Cap'n Proto framer that writes to w.
@@ -378,9 +921,10 @@ func NewEncoder(w io.Writer) *Encoder {
 }
 
 // NewPackedEncoder creates a new Cap'n Proto framer that writes to a
-// packed stream w.
+// packed stream w.  Segments are packed as they are written to w, so
+// the encoder doesn't buffer the packed form of the whole message.
 func NewPackedEncoder(w io.Writer) *Encoder {
-	return &Encoder{w: w, packed: true}
+	return &Encoder{w: packed.NewWriter(w)}
 }
 
 // Encode writes a message to the encoder stream.
@@ -390,7 +934,8 @@ func (e *Encoder) Encode(m *Message) error {
 	if nsegs == 0 {
 		return errMessageEmpty
 	}
-	sizes, err := m.segmentSizes()
+	sizes, err := m.segmentSizes(e.sizes)
+	e.sizes = sizes
 	if err != nil {
 		return err
 	}
@@ -402,33 +947,43 @@ func (e *Encoder) Encode(m *Message) error {
 		e.hdrbuf = e.hdrbuf[:hdrSize]
 	}
 	marshalStreamHeader(e.hdrbuf, sizes)
-	if err := e.write(e.hdrbuf); err != nil {
-		return err
-	}
+	e.bufs = append(e.bufs[:0], e.hdrbuf)
 	for i := int64(0); i < nsegs; i++ {
 		s, err := m.Segment(SegmentID(i))
 		if err != nil {
+			e.clearBufs()
 			return err
 		}
-		if err := e.write(s.data); err != nil {
-			return err
-		}
+		e.bufs = append(e.bufs, s.data)
 	}
-	return nil
+	// Writers that support it, like *net.TCPConn, get the header and
+	// all the segments in a single writev call without copying.
+	e.vec = e.bufs
+	_, err = e.vec.WriteTo(e.w)
+	e.clearBufs()
+	return err
 }
 
-func (e *Encoder) write(b []byte) error {
-	if e.packed {
-		e.packbuf = packed.Pack(e.packbuf[:0], b)
-		b = e.packbuf
+// clearBufs drops the encoder's references to segment data so that it
+// doesn't keep the last message alive.
+func (e *Encoder) clearBufs() {
+	for i := range e.bufs {
+		e.bufs[i] = nil
 	}
-	_, err := e.w.Write(b)
-	return err
+	e.bufs = e.bufs[:0]
+	e.vec = nil
 }
 
-func (m *Message) segmentSizes() ([]Size, error) {
+// segmentSizes returns the sizes of the message's segments, reusing buf
+// if it has enough capacity.
+func (m *Message) segmentSizes(buf []Size) ([]Size, error) {
 	nsegs := m.NumSegments()
-	sizes := make([]Size, nsegs)
+	var sizes []Size
+	if int64(cap(buf)) >= nsegs {
+		sizes = buf[:nsegs]
+	} else {
+		sizes = make([]Size, nsegs)
+	}
 	for i := int64(0); i < nsegs; i++ {
 		s, err := m.Segment(SegmentID(i))
 		if err != nil {
@@ -446,6 +1001,17 @@ func (m *Message) segmentSizes() ([]Size, error) {
 // Marshal concatenates the segments in the message into a single byte
 // slice including framing.
 func (m *Message) Marshal() ([]byte, error) {
+	return m.marshal(false)
+}
+
+// MarshalPacked marshals the message in packed form.  Each segment is
+// packed directly into the result, without first marshaling the
+// message unpacked.
+func (m *Message) MarshalPacked() ([]byte, error) {
+	return m.marshal(true)
+}
+
+func (m *Message) marshal(pack bool) ([]byte, error) {
 	// Compute buffer size.
 	// TODO(light): error out if too many segments
 	nsegs := m.NumSegments()
@@ -454,34 +1020,42 @@ func (m *Message) Marshal() ([]byte, error) {
 	}
 	maxSeg := uint32(nsegs - 1)
 	hdrSize := streamHeaderSize(maxSeg)
-	sizes, err := m.segmentSizes()
+	// Most messages have few segments, so avoid allocating their sizes.
+	var sizesBuf [8]Size
+	sizes, err := m.segmentSizes(sizesBuf[:])
 	if err != nil {
 		return nil, err
 	}
 	// TODO(light): error out if too large
 	total := uint64(hdrSize) + totalSize(sizes)
 
-	// Fill in buffer.
-	buf := make([]byte, hdrSize, total)
-	marshalStreamHeader(buf, sizes)
+	// Fill in buffer.  Packing rarely grows the data, so the unpacked
+	// size is a good guess for both forms.
+	var hdrBuf [64]byte
+	var hdr []byte
+	if hdrSize <= len(hdrBuf) {
+		hdr = hdrBuf[:hdrSize]
+	} else {
+		hdr = make([]byte, hdrSize)
+	}
+	marshalStreamHeader(hdr, sizes)
+	buf := make([]byte, 0, total)
+	if pack {
+		buf = packed.Pack(buf, hdr)
+	} else {
+		buf = append(buf, hdr...)
+	}
 	for i := int64(0); i < nsegs; i++ {
 		s, err := m.Segment(SegmentID(i))
 		if err != nil {
 			return nil, err
 		}
-		buf = append(buf, s.data...)
-	}
-	return buf, nil
-}
-
-// MarshalPacked marshals the message in packed form.
-func (m *Message) MarshalPacked() ([]byte, error) {
-	data, err := m.Marshal()
-	if err != nil {
-		return nil, err
+		if pack {
+			buf = packed.Pack(buf, s.data)
+		} else {
+			buf = append(buf, s.data...)
+		}
 	}
-	buf := make([]byte, 0, len(data))
-	buf = packed.Pack(buf, data)
 	return buf, nil
 }
 
@@ -508,21 +1082,36 @@ func marshalStreamHeader(b []byte, sizes []Size) {
 }
 
 // unmarshalStreamHeader parses the header of the stream framing format.
-func unmarshalStreamHeader(data []byte) (sizes []Size, tail []byte, err error) {
+// The sizes are stored in buf if it has enough capacity.
+func unmarshalStreamHeader(data []byte, buf []Size) (sizes []Size, tail []byte, err error) {
 	if len(data) < streamHeaderSize(0) {
 		return nil, nil, io.ErrUnexpectedEOF
 	}
 	maxSeg := binary.LittleEndian.Uint32(data)
-	// TODO(light): check int
+	if uint64(maxSeg) >= uint64(len(data))/segHeaderSize {
+		// Each segment needs at least a size entry, so the header can't
+		// fit.  Checking this first keeps streamHeaderSize from
+		// overflowing.
+		return nil, nil, io.ErrUnexpectedEOF
+	}
 	hdrSize := streamHeaderSize(maxSeg)
 	if len(data) < hdrSize {
 		return nil, nil, io.ErrUnexpectedEOF
 	}
 	n := int(maxSeg + 1)
-	sizes = make([]Size, n)
+	if cap(buf) >= n {
+		sizes = buf[:n]
+	} else {
+		sizes = make([]Size, n)
+	}
 	for i := 0; i < n; i++ {
-		s := binary.LittleEndian.Uint32(data[msgHeaderSize+i*segHeaderSize:])
-		sizes[i] = wordSize.times(int32(s))
+		words := binary.LittleEndian.Uint32(data[msgHeaderSize+i*segHeaderSize:])
+		if uint64(words)*uint64(wordSize) > uint64(maxSize) {
+			// The segment can't be addressed with a Size, so no limit
+			// that a message could be read under is large enough.
+			return nil, nil, errOverlarge
+		}
+		sizes[i] = Size(words) * wordSize
 	}
 	return sizes, data[hdrSize:], nil
 }
@@ -557,3 +1146,13 @@ var (
 	errSegmentTooSmall    = errors.New("capnp: segment too small")
 	errStreamHeader       = errors.New("capnp: invalid stream header")
 )
+
+// Errors returned by Decoder when a message exceeds its limits.
+var (
+	ErrMessageTooLarge = errors.New("capnp: message larger than decoder limit")
+	ErrTooManySegments = errors.New("capnp: message has more segments than decoder limit")
+)
+
+// ErrUnknownFraming is returned by the Decode method of a decoder from
+// NewAutoDecoder when it can't tell whether a stream is packed.
+var ErrUnknownFraming = errors.New("capnp: can't tell whether stream is packed")
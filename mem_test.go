@@ -5,7 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"testing"
+
+	"zombiezen.com/go/capnproto/internal/packed"
 )
 
 func TestNewMessage(t *testing.T) {
@@ -425,6 +429,14 @@ var serializeTests = []serializeTest{
 		},
 		decodeFails: true,
 	},
+	{
+		name: "overlarge segment count",
+		out: []byte{
+			0xff, 0xff, 0xff, 0xff,
+			0x00, 0x00, 0x00, 0x00,
+		},
+		decodeFails: true,
+	},
 	{
 		name: "empty single segment",
 		segs: [][]byte{
@@ -486,6 +498,14 @@ var serializeTests = []serializeTest{
 			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
 		},
 	},
+	{
+		name: "overlarge segment size",
+		out: []byte{
+			0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x20,
+		},
+		decodeFails: true,
+	},
 	{
 		name: "two segments, missing size padding",
 		out: []byte{
@@ -522,6 +542,37 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
+func TestMarshalPacked(t *testing.T) {
+	for i, test := range serializeTests {
+		if test.decodeFails || test.encodeFails {
+			continue
+		}
+		msg := &Message{Arena: test.arena()}
+		out, err := msg.MarshalPacked()
+		if err != nil {
+			t.Errorf("serializeTests[%d] - %s: MarshalPacked error: %v", i, test.name, err)
+			continue
+		}
+		unpacked, err := ioutil.ReadAll(packed.NewReader(bytes.NewReader(out)))
+		if err != nil {
+			t.Errorf("serializeTests[%d] - %s: unpacking MarshalPacked output: %v", i, test.name, err)
+			continue
+		}
+		if !bytes.Equal(unpacked, test.out) {
+			t.Errorf("serializeTests[%d] - %s: MarshalPacked unpacks to % 02x; want % 02x", i, test.name, unpacked, test.out)
+		}
+
+		var buf bytes.Buffer
+		if err := NewPackedEncoder(&buf).Encode(msg); err != nil {
+			t.Errorf("serializeTests[%d] - %s: packed Encode error: %v", i, test.name, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), out) {
+			t.Errorf("serializeTests[%d] - %s: packed Encode = % 02x; want % 02x", i, test.name, buf.Bytes(), out)
+		}
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	for i, test := range serializeTests {
 		if test.encodeFails {
@@ -558,6 +609,28 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestNextMessageSize(t *testing.T) {
+	for i, test := range serializeTests {
+		if test.encodeFails || test.decodeFails {
+			continue
+		}
+		n, err := NextMessageSize(test.out)
+		if err != nil || n != uint64(len(test.out)) {
+			t.Errorf("serializeTests[%d] - %s: NextMessageSize = %d, %v; want %d, <nil>", i, test.name, n, err, len(test.out))
+		}
+	}
+	bad := [][]byte{
+		{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20},
+		{0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff},
+	}
+	for _, prefix := range bad {
+		if n, err := NextMessageSize(prefix); err == nil {
+			t.Errorf("NextMessageSize(% 02x) = %d, <nil>; want error", prefix, n)
+		}
+	}
+}
+
 func TestEncoder(t *testing.T) {
 	for i, test := range serializeTests {
 		if test.decodeFails {
@@ -620,6 +693,859 @@ func TestDecoder(t *testing.T) {
 	}
 }
 
+func TestAutoDecoder(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := buildTestMessage(seg); err != nil {
+		t.Fatal(err)
+	}
+	single, err := seg.Message().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	multiMsg, seg, err := NewMessage(MultiSegment([][]byte{make([]byte, 0, 8)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := buildTestMessage(seg); err != nil {
+		t.Fatal(err)
+	}
+	if multiMsg.NumSegments() < 2 {
+		t.Fatal("test message has only one segment")
+	}
+	multi, err := multiMsg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"single segment", single},
+		{"multiple segments", multi},
+		{"stream", append(append([]byte(nil), single...), multi...)},
+	} {
+		for _, pack := range []bool{false, true} {
+			in := test.data
+			if pack {
+				in = packed.Pack(nil, in)
+			}
+			dec := NewAutoDecoder(bytes.NewReader(in))
+			for {
+				msg, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Errorf("%s (packed=%t): Decode: %v", test.name, pack, err)
+					break
+				}
+				root, err := msg.Root()
+				if err != nil {
+					t.Errorf("%s (packed=%t): Root: %v", test.name, pack, err)
+					break
+				}
+				if x := ToStruct(root).Uint64(0); x != 42 {
+					t.Errorf("%s (packed=%t): root.Uint64(0) = %d; want 42", test.name, pack, x)
+				}
+			}
+		}
+	}
+
+	errTests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, io.EOF},
+		{"zeros", make([]byte, 16), ErrUnknownFraming},
+		{"too many segments", []byte{0xff, 0xff, 0xff, 0xff, 1, 0, 0, 0}, ErrUnknownFraming},
+		// Two packed messages, each a one-word segment with a null root,
+		// also read as an unpacked header for 273 segments.
+		{"ambiguous", []byte{0x10, 0x01, 0x00, 0x00, 0x10, 0x01, 0x00, 0x00}, ErrUnknownFraming},
+	}
+	for _, test := range errTests {
+		if _, err := NewAutoDecoder(bytes.NewReader(test.data)).Decode(); err != test.err {
+			t.Errorf("%s: Decode error = %v; want %v", test.name, err, test.err)
+		}
+	}
+
+	// With a lower segment limit, the ambiguous stream can only be
+	// packed.
+	dec := NewAutoDecoder(bytes.NewReader(errTests[3].data))
+	dec.MaxSegments = 16
+	for i := 0; i < 2; i++ {
+		msg, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("ambiguous with MaxSegments = 16: Decode #%d: %v", i, err)
+		}
+		if n := msg.NumSegments(); n != 1 {
+			t.Errorf("ambiguous with MaxSegments = 16: message #%d has %d segments; want 1", i, n)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("ambiguous with MaxSegments = 16: Decode after last message = %v; want %v", err, io.EOF)
+	}
+}
+
+func TestDecoderReuseBuffer(t *testing.T) {
+	texts := []string{"short", "a much longer string that needs a bigger buffer", "mid-size"}
+	var stream bytes.Buffer
+	enc := NewEncoder(&stream)
+	for _, s := range texts {
+		msg, seg, err := NewMessage(SingleSegment(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := NewText(seg, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := msg.SetRoot(text); err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Encode(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := stream.Bytes()
+
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.ReuseBuffer()
+	var first *Message
+	for i, want := range texts {
+		msg, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode #%d: %v", i, err)
+		}
+		if first == nil {
+			first = msg
+		} else if msg != first {
+			t.Errorf("Decode #%d returned a different *Message", i)
+		}
+		p, err := msg.Root()
+		if err != nil {
+			t.Fatalf("Decode #%d: Root: %v", i, err)
+		}
+		if s := ToText(p); s != want {
+			t.Errorf("Decode #%d root = %q; want %q", i, s, want)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode at end of stream error = %v; want %v", err, io.EOF)
+	}
+
+	r := bytes.NewReader(data)
+	dec = NewDecoder(r)
+	dec.ReuseBuffer()
+	n := testing.AllocsPerRun(100, func() {
+		r.Seek(0, io.SeekStart)
+		for range texts {
+			if _, err := dec.Decode(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	if n != 0 {
+		t.Errorf("Decode with ReuseBuffer allocs = %v; want 0", n)
+	}
+}
+
+func TestDecoderLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          []byte
+		maxSize     uint64
+		maxSegments int
+		err         error
+	}{
+		{
+			name: "segment count over default limit",
+			in: []byte{
+				0xff, 0xff, 0xff, 0xff,
+				0x00, 0x00, 0x00, 0x00,
+			},
+			err: ErrTooManySegments,
+		},
+		{
+			name: "segment count over custom limit",
+			in: []byte{
+				0x01, 0x00, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+			},
+			maxSegments: 1,
+			err:         ErrTooManySegments,
+		},
+		{
+			name: "segment size over default limit",
+			in: []byte{
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x10,
+			},
+			err: ErrMessageTooLarge,
+		},
+		{
+			name: "segment size not addressable",
+			in: []byte{
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x20,
+			},
+			maxSize: 1 << 40,
+			err:     ErrMessageTooLarge,
+		},
+		{
+			name: "total size over custom limit",
+			in: []byte{
+				0x01, 0x00, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+			},
+			maxSize: 8,
+			err:     ErrMessageTooLarge,
+		},
+		{
+			name: "at custom limits",
+			in: []byte{
+				0x01, 0x00, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+			},
+			maxSize:     16,
+			maxSegments: 2,
+		},
+	}
+	for _, test := range tests {
+		for _, pack := range []bool{false, true} {
+			var dec *Decoder
+			if pack {
+				dec = NewPackedDecoder(bytes.NewReader(packed.Pack(nil, test.in)))
+			} else {
+				dec = NewDecoder(bytes.NewReader(test.in))
+			}
+			dec.MaxMessageSize = test.maxSize
+			dec.MaxSegments = test.maxSegments
+			_, err := dec.Decode()
+			if err != test.err {
+				t.Errorf("%s (packed=%t): Decode error = %v; want %v", test.name, pack, err, test.err)
+			}
+		}
+		opts := UnmarshalOptions{MaxMessageSize: test.maxSize, MaxSegments: test.maxSegments}
+		if _, err := opts.Unmarshal(test.in); err != test.err {
+			t.Errorf("%s: Unmarshal error = %v; want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestUnmarshalDefaultLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		err  error
+	}{
+		{
+			name: "segment count over default limit",
+			in: []byte{
+				0x00, 0x02, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+			},
+			err: ErrTooManySegments,
+		},
+		{
+			name: "segment size over default limit",
+			in: []byte{
+				0x00, 0x00, 0x00, 0x00,
+				0x01, 0x00, 0x80, 0x00,
+			},
+			err: ErrMessageTooLarge,
+		},
+	}
+	for _, test := range tests {
+		if _, err := Unmarshal(test.in); err != test.err {
+			t.Errorf("%s: Unmarshal error = %v; want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	// Build a message whose root and list live in different segments so
+	// that reads must resolve far pointers.
+	msg, seg, err := NewMessage(MultiSegment([][]byte{make([]byte, 0, 16)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewRootStruct(seg, ObjectSize{PointerCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := NewTextList(seg, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range []string{"a", "b", "c"} {
+		if err := list.Set(i, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := root.SetPointer(0, list); err != nil {
+		t.Fatal(err)
+	}
+	data, err := msg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err = Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.NumSegments() < 2 {
+		t.Fatalf("NumSegments() = %d; want >= 2", msg.NumSegments())
+	}
+
+	const n = 8
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			p, err := msg.Root()
+			if err != nil {
+				errs <- fmt.Errorf("Root: %v", err)
+				return
+			}
+			p, err = ToStruct(p).Pointer(0)
+			if err != nil {
+				errs <- fmt.Errorf("root.Pointer(0): %v", err)
+				return
+			}
+			l := TextList{ToList(p)}
+			for j, want := range []string{"a", "b", "c"} {
+				s, err := l.At(j)
+				if err != nil {
+					errs <- fmt.Errorf("At(%d): %v", j, err)
+					return
+				}
+				if s != want {
+					errs <- fmt.Errorf("At(%d) = %q; want %q", j, s, want)
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	msg, seg, err := NewMessage(MultiSegment([][]byte{make([]byte, 0, 16)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetUint64(0, 42)
+	old, err := NewText(seg, "overwritten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := root.SetPointer(0, old); err != nil {
+		t.Fatal(err)
+	}
+	text, err := NewText(seg, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := root.SetPointer(0, text); err != nil {
+		t.Fatal(err)
+	}
+	capID := msg.AddCap(ErrorClient(errReadOnlyArena))
+	if err := root.SetPointer(1, NewInterface(seg, capID)); err != nil {
+		t.Fatal(err)
+	}
+	if msg.NumSegments() < 2 {
+		t.Fatalf("test message has %d segments; want at least 2", msg.NumSegments())
+	}
+
+	c, err := msg.Compact()
+	if err != nil {
+		t.Fatal("Compact:", err)
+	}
+	if n := c.NumSegments(); n != 1 {
+		t.Fatalf("Compact().NumSegments() = %d; want 1", n)
+	}
+	cseg, err := c.Segment(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Root pointer, struct data, two pointers (the null third pointer is
+	// truncated), and text.
+	if n, want := len(cseg.Data()), 5*8; n != want || cap(cseg.Data()) != want {
+		t.Errorf("compacted segment len = %d, cap = %d; want %d", n, cap(cseg.Data()), want)
+	}
+	if len(c.CapTable) != 1 || c.CapTable[0] != msg.CapTable[0] {
+		t.Errorf("compacted CapTable = %v; want %v", c.CapTable, msg.CapTable)
+	}
+	p, err := c.Root()
+	if err != nil {
+		t.Fatal("Root:", err)
+	}
+	if eq, err := Equal(p, root); err != nil || !eq {
+		t.Errorf("Equal(compacted root, root) = %t, %v; want true, <nil>", eq, err)
+	}
+	if err := Validate(c); err != nil {
+		t.Errorf("Validate(compacted): %v", err)
+	}
+}
+
+func TestNewArenaAllocate(t *testing.T) {
+	tests := []arenaAllocTest{
+		{
+			name: "empty arena",
+			init: func() (Arena, map[SegmentID]*Segment) {
+				return NewArena(ArenaOptions{FirstSegmentSize: 64}), nil
+			},
+			size: 8,
+			id:   0,
+			data: []byte{},
+		},
+		{
+			name: "allocation larger than first segment",
+			init: func() (Arena, map[SegmentID]*Segment) {
+				return NewArena(ArenaOptions{FirstSegmentSize: 64, MaxSegmentSize: 64}), nil
+			},
+			size: 100,
+			id:   0,
+			data: []byte{},
+		},
+		{
+			name: "space in loaded segment",
+			init: func() (Arena, map[SegmentID]*Segment) {
+				arena := NewArena(ArenaOptions{FirstSegmentSize: 24})
+				_, buf, _ := arena.Allocate(8, nil)
+				segs := map[SegmentID]*Segment{
+					0: &Segment{id: 0, data: append(buf, incrementingData(16)...)},
+				}
+				return arena, segs
+			},
+			size: 8,
+			id:   0,
+			data: incrementingData(16),
+		},
+	}
+	for i := range tests {
+		tests[i].run(t, i)
+	}
+}
+
+func TestNewArenaGrowth(t *testing.T) {
+	arena := NewArena(ArenaOptions{
+		FirstSegmentSize: 64,
+		GrowthFactor:     2,
+		MaxSegmentSize:   300,
+	})
+	segs := make(map[SegmentID]*Segment)
+	var caps []int
+	for i := 0; i < 5; i++ {
+		id, data, err := arena.Allocate(8, segs)
+		if err != nil {
+			t.Fatalf("Allocate #%d: %v", i+1, err)
+		}
+		if int(id) != i {
+			t.Fatalf("Allocate #%d id = %d; want %d", i+1, id, i)
+		}
+		caps = append(caps, cap(data))
+		// Fill the segment so the next allocation needs a new one.
+		segs[id] = &Segment{id: id, data: data[:cap(data)]}
+	}
+	want := []int{64, 128, 256, 296, 296}
+	for i := range want {
+		if caps[i] != want[i] {
+			t.Errorf("segment capacities = %v; want %v", caps, want)
+			break
+		}
+	}
+}
+
+func TestNewMessageFirstSegmentSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		arena Arena
+		cap   int
+	}{
+		{"MultiSegment", MultiSegment(nil), defaultBufferSize},
+		{"PooledArena", new(PooledArena), defaultBufferSize},
+		{"NewArena", NewArena(ArenaOptions{FirstSegmentSize: 64}), 64},
+	}
+	for _, test := range tests {
+		_, seg, err := NewMessage(test.arena)
+		if err != nil {
+			t.Errorf("%s: NewMessage: %v", test.name, err)
+			continue
+		}
+		if n := cap(seg.Data()); n != test.cap {
+			t.Errorf("%s: first segment capacity = %d; want %d", test.name, n, test.cap)
+		}
+	}
+}
+
+func TestNewArenaQuota(t *testing.T) {
+	_, seg, err := NewMessage(NewArena(ArenaOptions{
+		FirstSegmentSize: 64,
+		Quota:            128,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The root pointer takes 8 bytes of the first segment, leaving room
+	// for 7 words there and 8 words in a second segment.
+	if _, err := NewData(seg, make([]byte, 56)); err != nil {
+		t.Fatal("NewData(56 bytes):", err)
+	}
+	if _, err := NewData(seg, make([]byte, 64)); err != nil {
+		t.Fatal("NewData(64 bytes):", err)
+	}
+	if _, err := NewData(seg, make([]byte, 8)); err != ErrArenaQuota {
+		t.Errorf("NewData past quota error = %v; want %v", err, ErrArenaQuota)
+	}
+}
+
+func TestMessageReset(t *testing.T) {
+	msg, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := buildTestMessage(seg); err != nil {
+		t.Fatal(err)
+	}
+	msg.AddCap(ErrorClient(errReadOnlyArena))
+
+	seg, err = msg.Reset(SingleSegment(nil))
+	if err != nil {
+		t.Fatal("Reset:", err)
+	}
+	if n := msg.NumSegments(); n != 1 {
+		t.Errorf("NumSegments() after Reset = %d; want 1", n)
+	}
+	if len(msg.CapTable) != 0 {
+		t.Errorf("len(CapTable) after Reset = %d; want 0", len(msg.CapTable))
+	}
+	if want := make([]byte, 8); !bytes.Equal(seg.Data(), want) {
+		t.Errorf("first segment after Reset = % 02x; want % 02x", seg.Data(), want)
+	}
+	if err := buildTestMessage(seg); err != nil {
+		t.Fatal(err)
+	}
+	out, err := msg.Marshal()
+	if err != nil {
+		t.Fatal("Marshal after Reset:", err)
+	}
+	_, freshSeg, _ := NewMessage(SingleSegment(nil))
+	if err := buildTestMessage(freshSeg); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := freshSeg.Message().Marshal()
+	if !bytes.Equal(out, want) {
+		t.Errorf("Marshal after Reset = % 02x; want % 02x", out, want)
+	}
+
+	if _, err := msg.Reset(SingleSegment(incrementingData(8))); err == nil {
+		t.Error("Reset on arena with data succeeded; want error")
+	}
+}
+
+func TestPooledArenaAllocate(t *testing.T) {
+	tests := []arenaAllocTest{
+		{
+			name: "empty arena",
+			init: func() (Arena, map[SegmentID]*Segment) {
+				return new(PooledArena), nil
+			},
+			size: 8,
+			id:   0,
+			data: []byte{},
+		},
+		{
+			name: "space in loaded segment",
+			init: func() (Arena, map[SegmentID]*Segment) {
+				pa := new(PooledArena)
+				pa.segs = [][]byte{make([]byte, 0, 24)}
+				segs := map[SegmentID]*Segment{
+					0: &Segment{id: 0, data: incrementingData(24)[:16]},
+				}
+				return pa, segs
+			},
+			size: 8,
+			id:   0,
+			data: incrementingData(16),
+		},
+		{
+			name: "full segment",
+			init: func() (Arena, map[SegmentID]*Segment) {
+				pa := new(PooledArena)
+				pa.segs = [][]byte{make([]byte, 0, 24)}
+				segs := map[SegmentID]*Segment{
+					0: &Segment{id: 0, data: incrementingData(24)},
+				}
+				return pa, segs
+			},
+			size: 8,
+			id:   1,
+			data: []byte{},
+		},
+	}
+	for i := range tests {
+		tests[i].run(t, i)
+	}
+}
+
+func TestPooledArenaRelease(t *testing.T) {
+	var arena PooledArena
+	var msg Message
+	for i := 0; i < 3; i++ {
+		seg, err := msg.Reset(&arena)
+		if err != nil {
+			t.Fatalf("Reset #%d: %v", i+1, err)
+		}
+		if err := buildTestMessage(seg); err != nil {
+			t.Fatalf("build #%d: %v", i+1, err)
+		}
+		p, err := msg.Root()
+		if err != nil {
+			t.Fatalf("Root #%d: %v", i+1, err)
+		}
+		if x := ToStruct(p).Uint64(0); x != 42 {
+			t.Errorf("root.Uint64(0) #%d = %d; want 42", i+1, x)
+		}
+		arena.Release()
+		if n := arena.NumSegments(); n != 0 {
+			t.Errorf("NumSegments() after Release #%d = %d; want 0", i+1, n)
+		}
+	}
+}
+
+func TestEncodeAllocs(t *testing.T) {
+	var arena PooledArena
+	var msg Message
+	enc := NewEncoder(ioutil.Discard)
+	var err error
+	n := testing.AllocsPerRun(100, func() {
+		arena.Release()
+		var seg *Segment
+		if seg, err = msg.Reset(&arena); err != nil {
+			return
+		}
+		// Storing a pointer boxes it in an interface, which allocates,
+		// so only fill in data here.
+		var s Struct
+		if s, err = NewStruct(seg, ObjectSize{DataSize: 16}); err != nil {
+			return
+		}
+		s.SetUint64(0, 42)
+		err = enc.Encode(&msg)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("Reset and Encode with PooledArena: %.1f allocations; want 0", n)
+	}
+
+	n = testing.AllocsPerRun(100, func() {
+		_, err = msg.Marshal()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("Marshal: %.1f allocations; want 1", n)
+	}
+}
+
+func BenchmarkEncode_SingleSegment(b *testing.B) {
+	benchmarkEncode(b, func() (*Message, *Segment, error) {
+		return NewMessage(SingleSegment(nil))
+	})
+}
+
+func BenchmarkEncode_MultiSegment(b *testing.B) {
+	benchmarkEncode(b, func() (*Message, *Segment, error) {
+		return NewMessage(MultiSegment(nil))
+	})
+}
+
+func BenchmarkEncode_PooledArena(b *testing.B) {
+	var arena PooledArena
+	var msg Message
+	benchmarkEncode(b, func() (*Message, *Segment, error) {
+		arena.Release()
+		seg, err := msg.Reset(&arena)
+		return &msg, seg, err
+	})
+}
+
+func benchmarkEncode(b *testing.B, newMessage func() (*Message, *Segment, error)) {
+	enc := NewEncoder(ioutil.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg, seg, err := newMessage()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := buildTestMessage(seg); err != nil {
+			b.Fatal(err)
+		}
+		if err := enc.Encode(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Large messages with many segments, written to a TCP connection, are
+// where a vectored write pays off.  BenchmarkEncode_TCPSequential
+// writes the header and each segment separately for comparison.
+
+func BenchmarkEncode_TCP(b *testing.B) {
+	var enc *Encoder
+	benchmarkEncodeTCP(b, func(w io.Writer, msg *Message) error {
+		if enc == nil {
+			enc = NewEncoder(w)
+		}
+		return enc.Encode(msg)
+	})
+}
+
+func BenchmarkEncode_TCPSequential(b *testing.B) {
+	benchmarkEncodeTCP(b, func(w io.Writer, msg *Message) error {
+		sizes, err := msg.segmentSizes(nil)
+		if err != nil {
+			return err
+		}
+		hdr := make([]byte, streamHeaderSize(uint32(len(sizes)-1)))
+		marshalStreamHeader(hdr, sizes)
+		if _, err := w.Write(hdr); err != nil {
+			return err
+		}
+		for i := range sizes {
+			s, err := msg.Segment(SegmentID(i))
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(s.data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func benchmarkEncodeTCP(b *testing.B, encode func(io.Writer, *Message) error) {
+	const (
+		nsegs   = 64
+		segSize = 64 << 10
+	)
+	segs := make([][]byte, nsegs)
+	for i := range segs {
+		segs[i] = make([]byte, segSize)
+	}
+	msg := &Message{Arena: MultiSegment(segs)}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Skip("listen:", err)
+	}
+	defer l.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		io.Copy(ioutil.Discard, c)
+		c.Close()
+	}()
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(nsegs * segSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := encode(c, msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	c.Close()
+	<-done
+}
+
+func BenchmarkMarshal_SingleSegment(b *testing.B) {
+	benchmarkMarshal(b, func() (*Message, *Segment, error) {
+		return NewMessage(SingleSegment(nil))
+	})
+}
+
+func BenchmarkMarshal_MultiSegment(b *testing.B) {
+	benchmarkMarshal(b, func() (*Message, *Segment, error) {
+		return NewMessage(MultiSegment(nil))
+	})
+}
+
+func BenchmarkMarshal_PooledArena(b *testing.B) {
+	var arena PooledArena
+	var msg Message
+	benchmarkMarshal(b, func() (*Message, *Segment, error) {
+		arena.Release()
+		seg, err := msg.Reset(&arena)
+		return &msg, seg, err
+	})
+}
+
+func benchmarkMarshal(b *testing.B, newMessage func() (*Message, *Segment, error)) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg, seg, err := newMessage()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := buildTestMessage(seg); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := msg.Marshal(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// buildTestMessage sets the root of seg's message to a small struct
+// with a number and a text field.
+func buildTestMessage(seg *Segment) error {
+	root, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1})
+	if err != nil {
+		return err
+	}
+	root.SetUint64(0, 42)
+	text, err := NewText(seg, "Hello, World!")
+	if err != nil {
+		return err
+	}
+	return root.SetPointer(0, text)
+}
+
 type arenaAllocTest struct {
 	name string
 
@@ -0,0 +1,93 @@
+//go:build linux
+// +build linux
+
+package capnp
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// A MappedArena is a read-only Arena whose segments are slices of a
+// memory-mapped file.  No message data is copied onto the heap, so it
+// is suitable for reading very large messages.  Messages using a
+// MappedArena are read-only: setters that return an error, like
+// SetPointer and SetUint32Checked, fail, and setters that don't return an
+// error panic.
+type MappedArena struct {
+	data []byte
+	segs [][]byte
+}
+
+// MapFile maps f into memory and parses the stream header of the first
+// message in it.  The file must not be modified while it is mapped.
+// The caller is responsible for calling Close on the arena once all
+// messages using it are no longer needed.
+func MapFile(f *os.File) (*MappedArena, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if int64(int(size)) != size {
+		return nil, errOverlarge
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	sizes, tail, err := unmarshalStreamHeader(data, nil)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	if totalSize(sizes) > uint64(len(tail)) {
+		syscall.Munmap(data)
+		return nil, io.ErrUnexpectedEOF
+	}
+	segs := make([][]byte, len(sizes))
+	for i, sz := range sizes {
+		segs[i], tail = tail[:sz:sz], tail[sz:]
+	}
+	return &MappedArena{data: data, segs: segs}, nil
+}
+
+// NumSegments returns the number of segments in the message.
+func (ma *MappedArena) NumSegments() int64 {
+	return int64(len(ma.segs))
+}
+
+// Data returns the segment with the given ID as a slice of the mapping.
+func (ma *MappedArena) Data(id SegmentID) ([]byte, error) {
+	if int64(id) >= int64(len(ma.segs)) {
+		return nil, errSegmentOutOfBounds
+	}
+	return ma.segs[id], nil
+}
+
+// Allocate returns an error, since the mapping is read-only.
+func (ma *MappedArena) Allocate(sz Size, segs map[SegmentID]*Segment) (SegmentID, []byte, error) {
+	return 0, nil, errMappedArenaReadOnly
+}
+
+func (ma *MappedArena) readOnly() bool {
+	return true
+}
+
+// Close unmaps the file.  Messages using the arena and any data read
+// from them must not be used after calling Close.
+func (ma *MappedArena) Close() error {
+	if ma.data == nil {
+		return nil
+	}
+	err := syscall.Munmap(ma.data)
+	ma.data, ma.segs = nil, nil
+	return err
+}
+
+var errMappedArenaReadOnly = errors.New("capnp: can't allocate in a memory-mapped arena")
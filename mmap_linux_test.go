@@ -0,0 +1,144 @@
+package capnp
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMapFile(t *testing.T) {
+	msg, seg, err := NewMessage(MultiSegment([][]byte{make([]byte, 0, 16)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := buildTestMessage(seg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := msg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := writeTempFile(t, data)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	arena, err := MapFile(f)
+	if err != nil {
+		t.Fatal("MapFile:", err)
+	}
+	defer arena.Close()
+	if n := arena.NumSegments(); n != msg.NumSegments() {
+		t.Errorf("NumSegments() = %d; want %d", n, msg.NumSegments())
+	}
+	mapped := &Message{Arena: arena}
+	p, err := mapped.Root()
+	if err != nil {
+		t.Fatal("Root:", err)
+	}
+	root := ToStruct(p)
+	if x := root.Uint64(0); x != 42 {
+		t.Errorf("root.Uint64(0) = %d; want 42", x)
+	}
+	text, err := root.Pointer(0)
+	if err != nil {
+		t.Fatal("root.Pointer(0):", err)
+	}
+	if s := ToText(text); s != "Hello, World!" {
+		t.Errorf("root.Pointer(0) = %q; want \"Hello, World!\"", s)
+	}
+	if _, _, err := arena.Allocate(8, nil); err == nil {
+		t.Error("Allocate succeeded; want error")
+	}
+	if err := arena.Close(); err != nil {
+		t.Error("Close:", err)
+	}
+	if _, err := arena.Data(0); err == nil {
+		t.Error("Data(0) after Close succeeded; want error")
+	}
+}
+
+func TestMapFileReadOnly(t *testing.T) {
+	msg, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := buildTestMessage(seg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := msg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := writeTempFile(t, data)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	arena, err := MapFile(f)
+	if err != nil {
+		t.Fatal("MapFile:", err)
+	}
+	defer arena.Close()
+	p, err := (&Message{Arena: arena}).Root()
+	if err != nil {
+		t.Fatal("Root:", err)
+	}
+	root := ToStruct(p)
+	if err := root.SetUint64Checked(0, 7); err != errReadOnly {
+		t.Errorf("SetUint64Checked(0, 7) = %v; want %v", err, errReadOnly)
+	}
+	if err := root.SetPointer(0, nil); err != errReadOnly {
+		t.Errorf("SetPointer(0, nil) = %v; want %v", err, errReadOnly)
+	}
+	func() {
+		defer func() {
+			if e := recover(); e != errReadOnly {
+				t.Errorf("SetUint64(0, 7) panicked with %v; want %v", e, errReadOnly)
+			}
+		}()
+		root.SetUint64(0, 7)
+	}()
+	if x := root.Uint64(0); x != 42 {
+		t.Errorf("root.Uint64(0) = %d; want 42", x)
+	}
+	if text, err := root.Pointer(0); err != nil || ToText(text) != "Hello, World!" {
+		t.Errorf("root.Pointer(0) = %q, %v; want \"Hello, World!\", <nil>", ToText(text), err)
+	}
+}
+
+func TestMapFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty file", nil, io.ErrUnexpectedEOF},
+		{"short header", []byte{0, 0, 0, 0}, io.ErrUnexpectedEOF},
+		{"truncated segment", []byte{0, 0, 0, 0, 2, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8}, io.ErrUnexpectedEOF},
+	}
+	for _, test := range tests {
+		f := writeTempFile(t, test.data)
+		arena, err := MapFile(f)
+		if err != test.err {
+			t.Errorf("%s: MapFile error = %v; want %v", test.name, err, test.err)
+		}
+		if err == nil {
+			arena.Close()
+		}
+		f.Close()
+		os.Remove(f.Name())
+	}
+}
+
+func writeTempFile(t *testing.T, data []byte) *os.File {
+	f, err := ioutil.TempFile("", "capnp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		t.Fatal(err)
+	}
+	return f
+}
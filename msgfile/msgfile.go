@@ -0,0 +1,313 @@
+// Package msgfile provides random access to files of concatenated
+// Cap'n Proto messages, like those written by capnp.Encoder.
+//
+// An Index records where each message in a file starts.  Building an
+// index only reads the messages' stream headers, and once it is built,
+// any message can be read with a single ReadAt call.  An index can be
+// saved next to its file so that it doesn't need to be rebuilt when the
+// file is opened again.
+package msgfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"zombiezen.com/go/capnproto"
+)
+
+// An Index holds the offsets of the messages in a stream.
+type Index struct {
+	// offsets[i] is the offset of message i.  The last element is the
+	// end of the last message, so there is always at least one element.
+	offsets []int64
+}
+
+// BuildIndex scans the size bytes of r for messages and returns their
+// index.  If the last message is truncated, BuildIndex returns the index
+// of the complete messages along with io.ErrUnexpectedEOF.  A stream
+// header claiming more than 512 segments or 4 GiB of data is reported
+// as an error, again with the messages before it in the index.
+func BuildIndex(r io.ReaderAt, size int64) (*Index, error) {
+	idx := &Index{offsets: []int64{0}}
+	err := idx.Update(r, size)
+	return idx, err
+}
+
+// Len returns the number of messages in the index.
+func (idx *Index) Len() int {
+	return len(idx.offsets) - 1
+}
+
+// Offset returns the offset of message i.
+func (idx *Index) Offset(i int) int64 {
+	return idx.offsets[i]
+}
+
+// Size returns the size of message i, including its stream header.
+func (idx *Index) Size(i int) int64 {
+	return idx.offsets[i+1] - idx.offsets[i]
+}
+
+// End returns the offset just past the last message in the index.
+func (idx *Index) End() int64 {
+	return idx.offsets[len(idx.offsets)-1]
+}
+
+// Update adds the messages between the end of the index and size to
+// the index.  This is useful for files that are appended to.  Like
+// BuildIndex, Update returns io.ErrUnexpectedEOF if the last message is
+// truncated, but the messages before it are still added.
+func (idx *Index) Update(r io.ReaderAt, size int64) error {
+	var buf []byte
+	for off := idx.End(); off < size; {
+		var n int64
+		var err error
+		n, buf, err = messageSize(r, off, size, buf)
+		if err != nil {
+			return err
+		}
+		if n > size-off {
+			return io.ErrUnexpectedEOF
+		}
+		off += n
+		idx.offsets = append(idx.offsets, off)
+	}
+	return nil
+}
+
+// messageSize reads the stream header at off and returns the size of
+// the message.  buf is used to read the header and the possibly grown
+// buffer is returned.
+func messageSize(r io.ReaderAt, off, size int64, buf []byte) (int64, []byte, error) {
+	if cap(buf) < minHeaderRead {
+		buf = make([]byte, minHeaderRead)
+	}
+	buf = buf[:cap(buf)]
+	for {
+		p := buf
+		if int64(len(p)) > size-off {
+			p = p[:size-off]
+		}
+		if err := readFullAt(r, p, off); err != nil {
+			return 0, buf, err
+		}
+		if len(p) >= 4 && binary.LittleEndian.Uint32(p) >= maxSegments {
+			// Don't grow the buffer for a header that is likely corrupt.
+			return 0, buf, errTooManySegments
+		}
+		n, err := capnp.NextMessageSize(p)
+		if err == io.ErrUnexpectedEOF && len(p) == len(buf) {
+			// The header is larger than the buffer.
+			buf = make([]byte, 2*len(buf))
+			continue
+		}
+		if err != nil {
+			return 0, buf, err
+		}
+		if n > maxMessageSize {
+			return 0, buf, errMessageSize
+		}
+		return int64(n), buf, nil
+	}
+}
+
+// minHeaderRead is the number of bytes read at first when looking for a
+// stream header.  It's enough for messages with up to 127 segments.
+const minHeaderRead = 512
+
+// Limits on the messages in a file.  A stream header that exceeds them
+// is treated as corrupt rather than trusted.
+const (
+	maxSegments    = 512     // same as capnp.Decoder's default
+	maxMessageSize = 1 << 32 // including the stream header
+)
+
+// readFullAt reads exactly len(p) bytes at off.
+func readFullAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// A Reader reads the messages in an indexed stream.
+type Reader struct {
+	r   io.ReaderAt
+	idx *Index
+}
+
+// NewReader returns a reader that reads the messages in idx from r.
+func NewReader(r io.ReaderAt, idx *Index) *Reader {
+	return &Reader{r: r, idx: idx}
+}
+
+// Len returns the number of messages in the stream.
+func (r *Reader) Len() int {
+	return r.idx.Len()
+}
+
+// Index returns the reader's index.
+func (r *Reader) Index() *Index {
+	return r.idx
+}
+
+// Message reads message i.
+func (r *Reader) Message(i int) (*capnp.Message, error) {
+	if i < 0 || i >= r.idx.Len() {
+		return nil, errMessageIndex
+	}
+	buf := make([]byte, r.idx.Size(i))
+	if err := readFullAt(r.r, buf, r.idx.Offset(i)); err != nil {
+		return nil, err
+	}
+	return capnp.Unmarshal(buf)
+}
+
+// Index file format: the magic string, the number of messages as a
+// little-endian uint64, and then each of the offsets, including the end
+// of the last message, as little-endian uint64s.
+const indexMagic = "capnpidx"
+
+// WriteTo writes the index to w in a form that can be read by ReadIndex.
+func (idx *Index) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, len(indexMagic)+8+8*len(idx.offsets))
+	copy(buf, indexMagic)
+	b := buf[len(indexMagic):]
+	binary.LittleEndian.PutUint64(b, uint64(idx.Len()))
+	b = b[8:]
+	for i, off := range idx.offsets {
+		binary.LittleEndian.PutUint64(b[8*i:], uint64(off))
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadIndex reads an index written by Index.WriteTo.
+func ReadIndex(r io.Reader) (*Index, error) {
+	var hdr [len(indexMagic) + 8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	if string(hdr[:len(indexMagic)]) != indexMagic {
+		return nil, errBadIndex
+	}
+	n := binary.LittleEndian.Uint64(hdr[len(indexMagic):])
+	idx := new(Index)
+	var b [8]byte
+	var prev int64
+	for i := uint64(0); i <= n; i++ {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		off := int64(binary.LittleEndian.Uint64(b[:]))
+		if off < prev || (i == 0 && off != 0) {
+			return nil, errBadIndex
+		}
+		idx.offsets = append(idx.offsets, off)
+		prev = off
+	}
+	return idx, nil
+}
+
+// IndexSuffix is appended to a file's name to get the name of its
+// saved index.
+const IndexSuffix = ".idx"
+
+// A File is an open file of messages.
+type File struct {
+	*Reader
+	f    *os.File
+	name string
+}
+
+// Open opens the named file for reading.  If there is a saved index for
+// the file, Open uses it, scanning only the messages added since it was
+// saved.  Otherwise, Open scans the whole file.  A saved index that
+// doesn't match the file is ignored.  If the last message in the file
+// is truncated, Open returns io.ErrUnexpectedEOF.
+func Open(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	size := info.Size()
+	idx := loadIndex(name+IndexSuffix, f, size)
+	if idx == nil {
+		idx = &Index{offsets: []int64{0}}
+	}
+	if err := idx.Update(f, size); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &File{Reader: NewReader(f, idx), f: f, name: name}, nil
+}
+
+// loadIndex reads the saved index for the data in r.  It returns nil if
+// the index can't be read or doesn't match the data.
+func loadIndex(name string, r io.ReaderAt, size int64) *Index {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	idx, err := ReadIndex(f)
+	if err != nil || idx.End() > size {
+		return nil
+	}
+	// Spot check that the last message is where the index says it is.
+	if n := idx.Len(); n > 0 {
+		sz, _, err := messageSize(r, idx.Offset(n-1), size, nil)
+		if err != nil || sz != idx.Size(n-1) {
+			return nil
+		}
+	}
+	return idx
+}
+
+// SaveIndex writes the file's index next to the file, so that later
+// calls to Open don't need to scan the whole file.
+func (f *File) SaveIndex() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(f.name), filepath.Base(f.name)+IndexSuffix)
+	if err != nil {
+		return err
+	}
+	_, err = f.idx.WriteTo(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.name+IndexSuffix)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	return f.f.Close()
+}
+
+var (
+	errBadIndex        = errors.New("msgfile: malformed index")
+	errMessageIndex    = errors.New("msgfile: message index out of range")
+	errMessageSize     = errors.New("msgfile: message too large")
+	errTooManySegments = errors.New("msgfile: message has too many segments")
+)
@@ -0,0 +1,196 @@
+package msgfile
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"zombiezen.com/go/capnproto"
+)
+
+// encodeMessages returns a stream of messages with the given texts as
+// their roots.  Every other message is split across segments.
+func encodeMessages(t *testing.T, texts ...string) []byte {
+	var buf bytes.Buffer
+	enc := capnp.NewEncoder(&buf)
+	for i, s := range texts {
+		arena := capnp.SingleSegment(nil)
+		if i%2 == 1 {
+			arena = capnp.MultiSegment([][]byte{make([]byte, 0, 8)})
+		}
+		msg, seg, err := capnp.NewMessage(arena)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := capnp.NewText(seg, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := msg.SetRoot(text); err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Encode(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func checkMessages(t *testing.T, r *Reader, texts ...string) {
+	if r.Len() != len(texts) {
+		t.Fatalf("Len() = %d; want %d", r.Len(), len(texts))
+	}
+	// Read backward to show that reads don't depend on each other.
+	for i := len(texts) - 1; i >= 0; i-- {
+		msg, err := r.Message(i)
+		if err != nil {
+			t.Errorf("Message(%d): %v", i, err)
+			continue
+		}
+		p, err := msg.Root()
+		if err != nil {
+			t.Errorf("Message(%d).Root(): %v", i, err)
+			continue
+		}
+		if s := capnp.ToText(p); s != texts[i] {
+			t.Errorf("Message(%d) root = %q; want %q", i, s, texts[i])
+		}
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	texts := []string{"foo", "bar", "a longer message", "baz"}
+	data := encodeMessages(t, texts...)
+	idx, err := BuildIndex(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("BuildIndex:", err)
+	}
+	if idx.End() != int64(len(data)) {
+		t.Errorf("End() = %d; want %d", idx.End(), len(data))
+	}
+	r := NewReader(bytes.NewReader(data), idx)
+	checkMessages(t, r, texts...)
+	if _, err := r.Message(len(texts)); err == nil {
+		t.Errorf("Message(%d) succeeded; want error", len(texts))
+	}
+}
+
+func TestBuildIndexTruncated(t *testing.T) {
+	data := encodeMessages(t, "foo", "bar")
+	full, err := BuildIndex(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("BuildIndex:", err)
+	}
+	for _, size := range []int64{full.Offset(1) + 4, full.Offset(1) + 12, int64(len(data)) - 8} {
+		idx, err := BuildIndex(bytes.NewReader(data[:size]), size)
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("BuildIndex(%d bytes) error = %v; want %v", size, err, io.ErrUnexpectedEOF)
+		}
+		if idx.Len() != 1 || idx.End() != full.Offset(1) {
+			t.Errorf("BuildIndex(%d bytes) = %d messages ending at %d; want 1 message ending at %d", size, idx.Len(), idx.End(), full.Offset(1))
+		}
+	}
+}
+
+func TestBuildIndexCorrupt(t *testing.T) {
+	good := encodeMessages(t, "foo")
+	tests := []struct {
+		name string
+		hdr  []byte
+	}{
+		{"too many segments", []byte{0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00}},
+		{"segment count just over limit", []byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"unaddressable segment", []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20}},
+		{"total too large", []byte{0x01, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x1f, 0xff, 0xff, 0xff, 0x1f, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, test := range tests {
+		data := append(append([]byte(nil), good...), test.hdr...)
+		data = append(data, make([]byte, 64)...)
+		idx, err := BuildIndex(bytes.NewReader(data), int64(len(data)))
+		if err == nil || err == io.ErrUnexpectedEOF {
+			t.Errorf("%s: BuildIndex error = %v; want corrupt header error", test.name, err)
+		}
+		if idx.Len() != 1 || idx.End() != int64(len(good)) {
+			t.Errorf("%s: BuildIndex = %d messages ending at %d; want 1 message ending at %d", test.name, idx.Len(), idx.End(), len(good))
+		}
+	}
+}
+
+func TestIndexRoundTrip(t *testing.T) {
+	data := encodeMessages(t, "foo", "bar", "baz")
+	idx, err := BuildIndex(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("BuildIndex:", err)
+	}
+	var buf bytes.Buffer
+	if _, err := idx.WriteTo(&buf); err != nil {
+		t.Fatal("WriteTo:", err)
+	}
+	idx2, err := ReadIndex(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal("ReadIndex:", err)
+	}
+	if idx2.Len() != idx.Len() {
+		t.Fatalf("ReadIndex Len() = %d; want %d", idx2.Len(), idx.Len())
+	}
+	for i := 0; i < idx.Len(); i++ {
+		if idx2.Offset(i) != idx.Offset(i) || idx2.Size(i) != idx.Size(i) {
+			t.Errorf("ReadIndex message %d at %d (size %d); want %d (size %d)", i, idx2.Offset(i), idx2.Size(i), idx.Offset(i), idx.Size(i))
+		}
+	}
+
+	if _, err := ReadIndex(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadIndex(truncated) error = %v; want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := ReadIndex(bytes.NewReader([]byte("notindex\x00\x00\x00\x00\x00\x00\x00\x00"))); err != errBadIndex {
+		t.Errorf("ReadIndex(bad magic) error = %v; want %v", err, errBadIndex)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "msgfile_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "messages")
+	if err := ioutil.WriteFile(name, encodeMessages(t, "foo", "bar"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Open(name)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	checkMessages(t, f.Reader, "foo", "bar")
+	if err := f.SaveIndex(); err != nil {
+		t.Error("SaveIndex:", err)
+	}
+	f.Close()
+
+	// Append a message: Open should pick up the saved index and scan the rest.
+	all := encodeMessages(t, "foo", "bar", "baz")
+	if err := ioutil.WriteFile(name, all, 0666); err != nil {
+		t.Fatal(err)
+	}
+	f, err = Open(name)
+	if err != nil {
+		t.Fatal("Open after append:", err)
+	}
+	checkMessages(t, f.Reader, "foo", "bar", "baz")
+	f.Close()
+
+	// Replace the file: the saved index no longer matches and is ignored.
+	if err := ioutil.WriteFile(name, encodeMessages(t, "a much longer message than before", "x", "y"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	f, err = Open(name)
+	if err != nil {
+		t.Fatal("Open after replace:", err)
+	}
+	checkMessages(t, f.Reader, "a much longer message than before", "x", "y")
+	f.Close()
+}
@@ -0,0 +1,316 @@
+// Package msglog provides an append-only log of Cap'n Proto messages
+// that survives crashes.
+//
+// Each record in a log file holds one message in the stream format
+// written by capnp.Encoder, preceded by an 8-byte header: the length of
+// the message in bytes and the CRC-32C checksum of the message, both as
+// little-endian uint32s.  If a crash interrupts an append, the last
+// record may be left incomplete or, on some file systems, filled with
+// zeros.  Open detects such a torn record by a length that runs past the
+// end of the file or by the zeros, and truncates the log to the end of
+// the last complete record.  A complete record that fails its checksum,
+// or whose length disagrees with the stream header of its message,
+// can't be torn, so Open reports it as corruption instead.
+package msglog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"zombiezen.com/go/capnproto"
+)
+
+// recordHeaderSize is the size of a record's length and checksum.
+const recordHeaderSize = 8
+
+// minRecordSize is the size of the smallest message a record can hold:
+// a stream header and an empty segment.
+const minRecordSize = 8
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// A Log is an open log file.  It is safe to call a Log's methods from
+// multiple goroutines.
+type Log struct {
+	mu   sync.Mutex
+	f    *os.File
+	size int64 // end of the last complete record
+
+	buf bytes.Buffer
+	enc *capnp.Encoder
+}
+
+// Open opens the named log file for appending, creating it if it doesn't
+// exist.  If the last record in the file is incomplete or the file ends
+// in zeros, Open truncates the file to remove the torn record.  If any
+// record is damaged in another way, including a complete last record
+// that fails its checksum, Open returns ErrCorrupt and leaves the file
+// as it is.
+func Open(name string) (*Log, error) {
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		f, err = createLog(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l, err := openLog(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// createLog creates the named log file and syncs its directory, so that
+// the file's directory entry survives a crash along with the records
+// that are later synced to it.
+func createLog(name string) (*os.File, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncDir(filepath.Dir(name)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func syncDir(name string) error {
+	d, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// openLog scans the records in f and truncates a torn final record.
+func openLog(f *os.File) (*Log, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	r := newReader(f, size)
+	for {
+		_, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err == ErrCorrupt {
+			if zero, zerr := isZero(f, r.off, size); zerr != nil {
+				return nil, zerr
+			} else if zero {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+		if err == io.ErrUnexpectedEOF {
+			if err := f.Truncate(r.off); err != nil {
+				return nil, err
+			}
+			if err := f.Sync(); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if err == errBadLength {
+			return nil, ErrCorrupt
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	l := &Log{f: f, size: r.off}
+	l.enc = capnp.NewEncoder(&l.buf)
+	return l, nil
+}
+
+// isZero reports whether the bytes of r from off to size are all zero.
+func isZero(r io.ReaderAt, off, size int64) (bool, error) {
+	var buf [4096]byte
+	for off < size {
+		p := buf[:]
+		if int64(len(p)) > size-off {
+			p = p[:size-off]
+		}
+		if n, err := r.ReadAt(p, off); n < len(p) {
+			return false, err
+		}
+		for _, b := range p {
+			if b != 0 {
+				return false, nil
+			}
+		}
+		off += int64(len(p))
+	}
+	return true, nil
+}
+
+// Append adds msg to the end of the log.  The record isn't guaranteed
+// to be on stable storage until Sync is called.
+func (l *Log) Append(msg *capnp.Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf.Reset()
+	var hdr [recordHeaderSize]byte
+	l.buf.Write(hdr[:])
+	if err := l.enc.Encode(msg); err != nil {
+		return err
+	}
+	rec := l.buf.Bytes()
+	data := rec[recordHeaderSize:]
+	if int64(len(data)) > math.MaxUint32 {
+		return errRecordSize
+	}
+	binary.LittleEndian.PutUint32(rec, uint32(len(data)))
+	binary.LittleEndian.PutUint32(rec[4:], crc32.Checksum(data, castagnoli))
+	// If the write fails partway, the next append overwrites the partial
+	// record, since size isn't advanced.
+	if _, err := l.f.WriteAt(rec, l.size); err != nil {
+		return err
+	}
+	l.size += int64(len(rec))
+	return nil
+}
+
+// Sync commits the appended records to stable storage.
+func (l *Log) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Sync()
+}
+
+// Size returns the size of the log in bytes.
+func (l *Log) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// NewReader returns a reader for the records that are in the log when
+// NewReader is called.  Records appended afterward are not read.
+func (l *Log) NewReader() *Reader {
+	return newReader(l.f, l.Size())
+}
+
+// Close closes the log file.  It does not call Sync.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// A Reader reads the messages in a log in order.
+type Reader struct {
+	r    *bufio.Reader
+	off  int64 // offset of the next record
+	size int64
+}
+
+func newReader(r io.ReaderAt, size int64) *Reader {
+	return &Reader{
+		r:    bufio.NewReader(io.NewSectionReader(r, 0, size)),
+		size: size,
+	}
+}
+
+// Next reads the next message in the log.  It returns io.EOF once all
+// the messages have been read and ErrCorrupt if a record is damaged.
+func (r *Reader) Next() (*capnp.Message, error) {
+	data, err := r.next()
+	if err == errBadLength {
+		return nil, ErrCorrupt
+	}
+	if err != nil {
+		return nil, err
+	}
+	return capnp.Unmarshal(data)
+}
+
+// next reads the next record's data.  A record that runs past the end
+// of the file is reported as io.ErrUnexpectedEOF if it may have been
+// torn and errBadLength if it can't have been.
+func (r *Reader) next() ([]byte, error) {
+	var hdr [recordHeaderSize]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := int64(binary.LittleEndian.Uint32(hdr[:]))
+	sum := binary.LittleEndian.Uint32(hdr[4:])
+	end := r.off + recordHeaderSize + n
+	if n < minRecordSize {
+		// Append never writes an empty message, so this is most likely
+		// zeros left by a torn write.
+		return nil, ErrCorrupt
+	}
+	if end > r.size {
+		// Don't allocate a buffer for a length that may be garbage.
+		prefix, _ := r.r.Peek(int(min64(r.size-r.off-recordHeaderSize, maxPeek)))
+		if !lengthMatches(prefix, n) {
+			return nil, errBadLength
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if crc32.Checksum(data, castagnoli) != sum {
+		return nil, ErrCorrupt
+	}
+	r.off = end
+	return data, nil
+}
+
+// maxPeek is the most of a record's data that is read to check its
+// length when the record runs past the end of the file.
+const maxPeek = 4096
+
+// lengthMatches reports whether n, the length in a record's header,
+// agrees with the stream header at the start of prefix, the record's
+// data.  If prefix is too short to hold the stream header or starts
+// with zeros, as a torn write may leave it, the length can't be checked
+// and lengthMatches returns true.
+func lengthMatches(prefix []byte, n int64) bool {
+	if len(prefix) >= 8 && binary.LittleEndian.Uint64(prefix) == 0 {
+		return true
+	}
+	size, err := capnp.NextMessageSize(prefix)
+	if err == io.ErrUnexpectedEOF {
+		return true
+	}
+	return err == nil && size == uint64(n)
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// ErrCorrupt is returned when a record in a log fails its checksum or
+// its length doesn't match its message.
+var ErrCorrupt = errors.New("msglog: corrupt record")
+
+// errBadLength is returned by Reader.next for a record whose length
+// doesn't match its message.
+var errBadLength = errors.New("msglog: record length doesn't match message")
+
+var errRecordSize = errors.New("msglog: message too large for record")
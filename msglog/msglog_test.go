@@ -0,0 +1,207 @@
+package msglog
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"zombiezen.com/go/capnproto"
+)
+
+func newTextMessage(t *testing.T, s string) *capnp.Message {
+	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := capnp.NewText(seg, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.SetRoot(text); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// writeLog creates a log with the given texts and returns its name and
+// the offsets of the ends of each record.
+func writeLog(t *testing.T, dir string, texts ...string) (name string, ends []int64) {
+	name = filepath.Join(dir, "log")
+	l, err := Open(name)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	defer l.Close()
+	for _, s := range texts {
+		if err := l.Append(newTextMessage(t, s)); err != nil {
+			t.Fatal("Append:", err)
+		}
+		ends = append(ends, l.Size())
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatal("Sync:", err)
+	}
+	return name, ends
+}
+
+func readTexts(t *testing.T, l *Log) []string {
+	var texts []string
+	r := l.NewReader()
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			return texts
+		}
+		if err != nil {
+			t.Fatal("Next:", err)
+		}
+		p, err := msg.Root()
+		if err != nil {
+			t.Fatal("Root:", err)
+		}
+		texts = append(texts, capnp.ToText(p))
+	}
+}
+
+func checkTexts(t *testing.T, name string, want ...string) *Log {
+	l, err := Open(name)
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	got := readTexts(t, l)
+	if len(got) != len(want) {
+		t.Errorf("log holds %q; want %q", got, want)
+		return l
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("log holds %q; want %q", got, want)
+			break
+		}
+	}
+	return l
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "msglog_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestAppendReopen(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	name, _ := writeLog(t, dir, "foo", "bar")
+	l := checkTexts(t, name, "foo", "bar")
+	if err := l.Append(newTextMessage(t, "baz")); err != nil {
+		t.Fatal("Append:", err)
+	}
+	l.Close()
+	checkTexts(t, name, "foo", "bar", "baz").Close()
+}
+
+func TestTornRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(f *os.File, ends []int64) error
+	}{
+		{"truncated header", func(f *os.File, ends []int64) error {
+			return f.Truncate(ends[1] + 3)
+		}},
+		{"truncated data", func(f *os.File, ends []int64) error {
+			return f.Truncate(ends[2] - 1)
+		}},
+		{"zero filled", func(f *os.File, ends []int64) error {
+			_, err := f.WriteAt(make([]byte, ends[2]-ends[1]+16), ends[1])
+			return err
+		}},
+		{"zero data", func(f *os.File, ends []int64) error {
+			if _, err := f.WriteAt(make([]byte, ends[2]-ends[1]-recordHeaderSize), ends[1]+recordHeaderSize); err != nil {
+				return err
+			}
+			return f.Truncate(ends[2] - 1)
+		}},
+	}
+	for _, test := range tests {
+		dir := tempDir(t)
+		name, ends := writeLog(t, dir, "foo", "bar", "baz")
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := test.damage(f, ends); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		l := checkTexts(t, name, "foo", "bar")
+		if size := l.Size(); size != ends[1] {
+			t.Errorf("%s: Size() after recovery = %d; want %d", test.name, size, ends[1])
+		}
+		if err := l.Append(newTextMessage(t, "qux")); err != nil {
+			t.Fatalf("%s: Append: %v", test.name, err)
+		}
+		l.Close()
+		checkTexts(t, name, "foo", "bar", "qux").Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCorruptRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(f *os.File, ends []int64) error
+	}{
+		{"bad checksum", func(f *os.File, ends []int64) error {
+			_, err := f.WriteAt([]byte{0xff}, ends[1]-1)
+			return err
+		}},
+		{"bad checksum in last record", func(f *os.File, ends []int64) error {
+			_, err := f.WriteAt([]byte{0xff}, ends[2]-1)
+			return err
+		}},
+		{"length past end of file", func(f *os.File, ends []int64) error {
+			_, err := f.WriteAt([]byte{0xff, 0xff, 0xff, 0x7f}, ends[0])
+			return err
+		}},
+		{"length past end of torn file", func(f *os.File, ends []int64) error {
+			if _, err := f.WriteAt([]byte{0xff, 0xff, 0xff, 0x7f}, ends[0]); err != nil {
+				return err
+			}
+			return f.Truncate(ends[2] - 1)
+		}},
+	}
+	for _, test := range tests {
+		dir := tempDir(t)
+		name, ends := writeLog(t, dir, "foo", "bar", "baz")
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := test.damage(f, ends); err != nil {
+			t.Fatal(err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := info.Size()
+		f.Close()
+
+		if _, err := Open(name); err != ErrCorrupt {
+			t.Errorf("%s: Open error = %v; want %v", test.name, err, ErrCorrupt)
+		}
+		info, err = os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != size {
+			t.Errorf("%s: file size after failed Open = %d; want %d", test.name, info.Size(), size)
+		}
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,63 @@
+package capnp
+
+import "errors"
+
+// An Orphan is an object in a message that no pointer refers to.  An
+// orphan can be obtained by disowning a pointer field or by wrapping a
+// newly allocated object with NewOrphan.  Adopting an orphan links it
+// into a pointer field in the same message without copying its data.
+//
+// An orphan should be adopted at most once.  If an orphan is never
+// adopted, its data remains in the message as unreachable garbage.
+type Orphan struct {
+	p Pointer
+}
+
+// NewOrphan returns an orphan for p.  Nothing in p's message may refer
+// to p, which is typically an object that was just allocated with a
+// function like NewStruct or NewText.
+func NewOrphan(p Pointer) Orphan {
+	return Orphan{p}
+}
+
+// Pointer returns the orphaned object, or nil if the orphan is null.
+// The object can be read and modified before it is adopted.
+func (o Orphan) Pointer() Pointer {
+	return o.p
+}
+
+// IsNull reports whether the orphan holds a null pointer.
+func (o Orphan) IsNull() bool {
+	return !IsValid(o.p)
+}
+
+// disown reads the pointer at addr into an orphan and nulls it out.
+func disown(s *Segment, addr Address, depthLimit uint) (Orphan, error) {
+	if s.readOnly {
+		return Orphan{}, errReadOnly
+	}
+	p, err := s.readPtr(addr, depthLimit)
+	if err != nil {
+		return Orphan{}, err
+	}
+	s.writeRawPointer(addr, 0)
+	return Orphan{p}, nil
+}
+
+// adopt points the pointer at addr to o's object without copying.
+func adopt(s *Segment, addr Address, o Orphan) error {
+	if IsValid(o.p) {
+		if o.p.Segment().msg != s.msg {
+			return errOrphanMessage
+		}
+		if st := ToStruct(o.p); IsValid(st) && st.flags&isListMember != 0 {
+			return errOrphanListMember
+		}
+	}
+	return s.writePtr(copyContext{}, addr, o.p)
+}
+
+var (
+	errOrphanMessage    = errors.New("capnp: can't adopt orphan from a different message")
+	errOrphanListMember = errors.New("capnp: can't adopt struct list element")
+)
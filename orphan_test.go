@@ -0,0 +1,163 @@
+package capnp
+
+import (
+	"testing"
+)
+
+func TestStructDisownAdopt(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewRootStruct(seg, ObjectSize{PointerCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := NewText(seg, "hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPointer(0, text); err != nil {
+		t.Fatal(err)
+	}
+	size := len(seg.Data())
+
+	o, err := s.Disown(0)
+	if err != nil {
+		t.Fatal("Disown(0):", err)
+	}
+	if o.IsNull() {
+		t.Fatal("Disown(0) returned null orphan")
+	}
+	if p, err := s.Pointer(0); err != nil || IsValid(p) {
+		t.Errorf("Pointer(0) after Disown = %v, %v; want <nil>, <nil>", p, err)
+	}
+	if err := s.Adopt(1, o); err != nil {
+		t.Fatal("Adopt(1):", err)
+	}
+	p, err := s.Pointer(1)
+	if err != nil {
+		t.Fatal("Pointer(1):", err)
+	}
+	if ToText(p) != "hi" {
+		t.Errorf("Pointer(1) = %q; want \"hi\"", ToText(p))
+	}
+	if addr := pointerAddress(p); addr != text.Address() {
+		t.Errorf("adopted text address = %v; want %v", addr, text.Address())
+	}
+	if n := len(seg.Data()); n != size {
+		t.Errorf("segment size after adopt = %d; want %d", n, size)
+	}
+
+	if o, err := s.Disown(5); err != nil || !o.IsNull() {
+		t.Errorf("Disown(5) = %v, %v; want null orphan", o.Pointer(), err)
+	}
+}
+
+func TestAdoptAcrossSegments(t *testing.T) {
+	msg, seg, err := NewMessage(MultiSegment([][]byte{make([]byte, 0, 16)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewRootStruct(seg, ObjectSize{PointerCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := NewText(seg, "far away")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text.Segment() == s.Segment() {
+		t.Fatal("text allocated in same segment as struct")
+	}
+	if err := s.Adopt(0, NewOrphan(text)); err != nil {
+		t.Fatal("Adopt:", err)
+	}
+	p, err := s.Pointer(0)
+	if err != nil {
+		t.Fatal("Pointer(0):", err)
+	}
+	if ToText(p) != "far away" {
+		t.Errorf("Pointer(0) = %q; want \"far away\"", ToText(p))
+	}
+	if p.Segment() != text.Segment() || pointerAddress(p) != text.Address() {
+		t.Error("adopted text was copied")
+	}
+	if n := msg.NumSegments(); n != 2 {
+		t.Errorf("NumSegments() = %d; want 2", n)
+	}
+}
+
+func TestPointerListDisownAdopt(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewPointerList(seg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := NewStruct(seg, ObjectSize{DataSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.SetUint64(0, 42)
+	if err := l.Adopt(0, NewOrphan(st)); err != nil {
+		t.Fatal("Adopt(0):", err)
+	}
+	o, err := l.Disown(0)
+	if err != nil {
+		t.Fatal("Disown(0):", err)
+	}
+	if p, err := l.At(0); err != nil || IsValid(p) {
+		t.Errorf("At(0) after Disown = %v, %v; want <nil>, <nil>", p, err)
+	}
+	if x := ToStruct(o.Pointer()).Uint64(0); x != 42 {
+		t.Errorf("orphan.Uint64(0) = %d; want 42", x)
+	}
+	if err := l.Adopt(1, o); err != nil {
+		t.Fatal("Adopt(1):", err)
+	}
+	p, err := l.At(1)
+	if err != nil {
+		t.Fatal("At(1):", err)
+	}
+	if s := ToStruct(p); s.Address() != st.Address() || s.Uint64(0) != 42 {
+		t.Errorf("At(1) = %v; want struct at %v", p, st.Address())
+	}
+}
+
+func TestAdoptErrors(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewRootStruct(seg, ObjectSize{PointerCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, otherSeg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewText(otherSeg, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Adopt(0, NewOrphan(other)); err != errOrphanMessage {
+		t.Errorf("Adopt(orphan from other message) error = %v; want %v", err, errOrphanMessage)
+	}
+
+	l, err := NewCompositeList(seg, ObjectSize{DataSize: 8}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Adopt(0, NewOrphan(l.Struct(0))); err != errOrphanListMember {
+		t.Errorf("Adopt(list element) error = %v; want %v", err, errOrphanListMember)
+	}
+
+	if err := s.Adopt(0, Orphan{}); err != nil {
+		t.Errorf("Adopt(null orphan) error: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package pogs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/internal/nodemap"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// Extract copies s into val, which must be a pointer to a Go struct.
+// typeID is the ID of s's struct type.
+func Extract(val interface{}, typeID uint64, s capnp.Struct) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errExtractType
+	}
+	e := new(extracter)
+	return e.extractStruct(v.Elem(), typeID, s)
+}
+
+type extracter struct {
+	nodes nodemap.Map
+}
+
+func (e *extracter) extractStruct(val reflect.Value, typeID uint64, s capnp.Struct) error {
+	n, err := e.nodes.Find(typeID)
+	if err != nil {
+		return err
+	}
+	if n.Which() != schema.Node_Which_structGroup {
+		return fmt.Errorf("pogs: node %#x is not a struct", typeID)
+	}
+	var which uint16
+	if n.StructGroup().DiscriminantCount() > 0 {
+		w, err := whichField(val)
+		if err != nil {
+			return err
+		}
+		which = s.Uint16(discriminantOffset(n))
+		w.SetUint(uint64(which))
+	}
+	fields, err := n.StructGroup().Fields()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		name, err := f.Name()
+		if err != nil {
+			return err
+		}
+		vf, ok := fieldByName(val, name)
+		if !ok {
+			continue
+		}
+		if isInactive(f, which) {
+			vf.Set(reflect.Zero(vf.Type()))
+			continue
+		}
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			err = e.extractField(vf, f, s)
+		case schema.Field_Which_group:
+			err = e.extractStructValue(vf, f.Group().TypeId(), s)
+		}
+		if err != nil {
+			return fmt.Errorf("%v (field %s)", err, name)
+		}
+	}
+	return nil
+}
+
+// extractStructValue extracts s into val, which may be a struct or a
+// pointer to a struct.  A null s leaves val as its zero value.
+func (e *extracter) extractStructValue(val reflect.Value, typeID uint64, s capnp.Struct) error {
+	if !capnp.IsValid(s) {
+		val.Set(reflect.Zero(val.Type()))
+		return nil
+	}
+	switch {
+	case val.Kind() == reflect.Struct:
+		return e.extractStruct(val, typeID, s)
+	case val.Kind() == reflect.Ptr && val.Type().Elem().Kind() == reflect.Struct:
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return e.extractStruct(val.Elem(), typeID, s)
+	default:
+		return typeError(schema.Type_Which_structGroup, val.Type())
+	}
+}
+
+func (e *extracter) extractField(val reflect.Value, f schema.Field, s capnp.Struct) error {
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	def, err := f.Slot().DefaultValue()
+	if err != nil {
+		return err
+	}
+	off := f.Slot().Offset()
+	if bits := primitiveBits(typ.Which()); bits > 0 {
+		var x uint64
+		switch bits {
+		case 1:
+			if s.Bit(capnp.BitOffset(off)) {
+				x = 1
+			}
+		case 8:
+			x = uint64(s.Uint8(capnp.DataOffset(off)))
+		case 16:
+			x = uint64(s.Uint16(capnp.DataOffset(off * 2)))
+		case 32:
+			x = uint64(s.Uint32(capnp.DataOffset(off * 4)))
+		case 64:
+			x = s.Uint64(capnp.DataOffset(off * 8))
+		}
+		return setPrimitive(val, typ.Which(), x^defaultBits(def))
+	}
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		return nil
+	case schema.Type_Which_interface:
+		return errInterface
+	}
+	p, err := s.Pointer(uint16(off))
+	if err != nil {
+		return err
+	}
+	if !capnp.IsValid(p) {
+		if p, err = defaultPointer(def); err != nil {
+			return err
+		}
+	}
+	return e.extractPointer(val, typ, p)
+}
+
+// defaultPointer returns the default value of a pointer field.
+func defaultPointer(def schema.Value) (capnp.Pointer, error) {
+	switch def.Which() {
+	case schema.Value_Which_text, schema.Value_Which_data:
+		// Text and data are stored in the value's only pointer.
+		return def.Struct.Pointer(0)
+	case schema.Value_Which_list:
+		return def.List()
+	case schema.Value_Which_structField:
+		return def.StructField()
+	case schema.Value_Which_anyPointer:
+		return def.AnyPointer()
+	default:
+		return nil, nil
+	}
+}
+
+func (e *extracter) extractPointer(val reflect.Value, typ schema.Type, p capnp.Pointer) error {
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		switch {
+		case val.Kind() == reflect.String:
+			val.SetString(capnp.ToText(p))
+		case isBytes(val.Type()):
+			val.SetBytes([]byte(capnp.ToText(p)))
+		default:
+			return typeError(typ.Which(), val.Type())
+		}
+	case schema.Type_Which_data:
+		if !isBytes(val.Type()) {
+			return typeError(typ.Which(), val.Type())
+		}
+		val.SetBytes(copyBytes(capnp.ToData(p)))
+	case schema.Type_Which_structGroup:
+		return e.extractStructValue(val, typ.StructGroup().TypeId(), capnp.ToStruct(p))
+	case schema.Type_Which_list:
+		et, err := typ.List().ElementType()
+		if err != nil {
+			return err
+		}
+		return e.extractList(val, et, capnp.ToList(p))
+	case schema.Type_Which_anyPointer:
+		if val.Type() != pointerType {
+			return typeError(typ.Which(), val.Type())
+		}
+		if p == nil {
+			val.Set(reflect.Zero(pointerType))
+		} else {
+			val.Set(reflect.ValueOf(p))
+		}
+	case schema.Type_Which_interface:
+		return errInterface
+	}
+	return nil
+}
+
+func (e *extracter) extractList(val reflect.Value, et schema.Type, l capnp.List) error {
+	if val.Kind() != reflect.Slice {
+		return typeError(schema.Type_Which_list, val.Type())
+	}
+	if !capnp.IsValid(l) {
+		val.Set(reflect.Zero(val.Type()))
+		return nil
+	}
+	n := l.Len()
+	sl := reflect.MakeSlice(val.Type(), n, n)
+	for i := 0; i < n; i++ {
+		if err := e.extractElem(sl.Index(i), et, l, i); err != nil {
+			return err
+		}
+	}
+	val.Set(sl)
+	return nil
+}
+
+func (e *extracter) extractElem(val reflect.Value, et schema.Type, l capnp.List, i int) error {
+	if bits := primitiveBits(et.Which()); bits > 0 {
+		var x uint64
+		switch bits {
+		case 1:
+			if (capnp.BitList{List: l}).At(i) {
+				x = 1
+			}
+		case 8:
+			x = uint64(capnp.UInt8List{List: l}.At(i))
+		case 16:
+			x = uint64(capnp.UInt16List{List: l}.At(i))
+		case 32:
+			x = uint64(capnp.UInt32List{List: l}.At(i))
+		case 64:
+			x = capnp.UInt64List{List: l}.At(i)
+		}
+		return setPrimitive(val, et.Which(), x)
+	}
+	switch et.Which() {
+	case schema.Type_Which_void:
+		return nil
+	case schema.Type_Which_structGroup:
+		return e.extractStructValue(val, et.StructGroup().TypeId(), l.Struct(i))
+	}
+	p, err := capnp.PointerList{List: l}.At(i)
+	if err != nil {
+		return err
+	}
+	return e.extractPointer(val, et, p)
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+var (
+	errExtractType = errors.New("pogs: Extract requires a pointer to a struct")
+	errInterface   = errors.New("pogs: interface fields are not supported")
+)
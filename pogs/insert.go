@@ -0,0 +1,297 @@
+package pogs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/internal/nodemap"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// Insert copies val, a Go struct or a pointer to one, into s.  typeID
+// is the ID of s's struct type.  Objects that val's fields point to are
+// allocated in s's segment.
+func Insert(typeID uint64, s capnp.Struct, val interface{}) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return errInsertType
+	}
+	in := new(inserter)
+	return in.insertStruct(typeID, s, v)
+}
+
+type inserter struct {
+	nodes nodemap.Map
+}
+
+func (in *inserter) insertStruct(typeID uint64, s capnp.Struct, val reflect.Value) error {
+	n, err := in.nodes.Find(typeID)
+	if err != nil {
+		return err
+	}
+	if n.Which() != schema.Node_Which_structGroup {
+		return fmt.Errorf("pogs: node %#x is not a struct", typeID)
+	}
+	var which uint16
+	if n.StructGroup().DiscriminantCount() > 0 {
+		w, err := whichField(val)
+		if err != nil {
+			return err
+		}
+		which = uint16(w.Uint())
+		if err := s.SetUint16Checked(discriminantOffset(n), which); err != nil {
+			return err
+		}
+	}
+	fields, err := n.StructGroup().Fields()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		if isInactive(f, which) {
+			continue
+		}
+		name, err := f.Name()
+		if err != nil {
+			return err
+		}
+		vf, ok := fieldByName(val, name)
+		if !ok {
+			continue
+		}
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			err = in.insertField(s, f, vf)
+		case schema.Field_Which_group:
+			if vf.Kind() == reflect.Ptr {
+				if vf.IsNil() {
+					continue
+				}
+				vf = vf.Elem()
+			}
+			if vf.Kind() != reflect.Struct {
+				err = typeError(schema.Type_Which_structGroup, vf.Type())
+				break
+			}
+			err = in.insertStruct(f.Group().TypeId(), s, vf)
+		}
+		if err != nil {
+			return fmt.Errorf("%v (field %s)", err, name)
+		}
+	}
+	return nil
+}
+
+func (in *inserter) insertField(s capnp.Struct, f schema.Field, val reflect.Value) error {
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	def, err := f.Slot().DefaultValue()
+	if err != nil {
+		return err
+	}
+	off := f.Slot().Offset()
+	if bits := primitiveBits(typ.Which()); bits > 0 {
+		x, err := primitive(val, typ.Which())
+		if err != nil {
+			return err
+		}
+		x ^= defaultBits(def)
+		switch bits {
+		case 1:
+			return s.SetBitChecked(capnp.BitOffset(off), x != 0)
+		case 8:
+			return s.SetUint8Checked(capnp.DataOffset(off), uint8(x))
+		case 16:
+			return s.SetUint16Checked(capnp.DataOffset(off*2), uint16(x))
+		case 32:
+			return s.SetUint32Checked(capnp.DataOffset(off*4), uint32(x))
+		default:
+			return s.SetUint64Checked(capnp.DataOffset(off*8), x)
+		}
+	}
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		return nil
+	case schema.Type_Which_interface:
+		return errInterface
+	}
+	p, err := in.newPointer(s.Segment(), typ, val)
+	if err != nil {
+		return err
+	}
+	return s.SetPointer(uint16(off), p)
+}
+
+// newPointer allocates an object holding val.  It returns nil for nil
+// pointers and slices.
+func (in *inserter) newPointer(seg *capnp.Segment, typ schema.Type, val reflect.Value) (capnp.Pointer, error) {
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		switch {
+		case val.Kind() == reflect.String:
+			return capnp.NewText(seg, val.String())
+		case isBytes(val.Type()):
+			if val.IsNil() {
+				return nil, nil
+			}
+			return capnp.NewText(seg, string(val.Bytes()))
+		default:
+			return nil, typeError(typ.Which(), val.Type())
+		}
+	case schema.Type_Which_data:
+		if !isBytes(val.Type()) {
+			return nil, typeError(typ.Which(), val.Type())
+		}
+		if val.IsNil() {
+			return nil, nil
+		}
+		return capnp.NewData(seg, val.Bytes())
+	case schema.Type_Which_structGroup:
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return nil, nil
+			}
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			return nil, typeError(typ.Which(), val.Type())
+		}
+		id := typ.StructGroup().TypeId()
+		n, err := in.nodes.Find(id)
+		if err != nil {
+			return nil, err
+		}
+		st, err := capnp.NewStruct(seg, structSize(n))
+		if err != nil {
+			return nil, err
+		}
+		return st, in.insertStruct(id, st, val)
+	case schema.Type_Which_list:
+		if val.Kind() != reflect.Slice {
+			return nil, typeError(typ.Which(), val.Type())
+		}
+		if val.IsNil() {
+			return nil, nil
+		}
+		et, err := typ.List().ElementType()
+		if err != nil {
+			return nil, err
+		}
+		return in.newList(seg, et, val)
+	case schema.Type_Which_anyPointer:
+		if val.Type() != pointerType {
+			return nil, typeError(typ.Which(), val.Type())
+		}
+		if val.IsNil() {
+			return nil, nil
+		}
+		return val.Interface().(capnp.Pointer), nil
+	default:
+		return nil, errInterface
+	}
+}
+
+func (in *inserter) newList(seg *capnp.Segment, et schema.Type, val reflect.Value) (capnp.List, error) {
+	n := int32(val.Len())
+	if bits := primitiveBits(et.Which()); bits > 0 {
+		l, err := newPrimitiveList(seg, bits, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i := 0; i < int(n); i++ {
+			x, err := primitive(val.Index(i), et.Which())
+			if err != nil {
+				return capnp.List{}, err
+			}
+			switch bits {
+			case 1:
+				capnp.BitList{List: l}.Set(i, x != 0)
+			case 8:
+				capnp.UInt8List{List: l}.Set(i, uint8(x))
+			case 16:
+				capnp.UInt16List{List: l}.Set(i, uint16(x))
+			case 32:
+				capnp.UInt32List{List: l}.Set(i, uint32(x))
+			case 64:
+				capnp.UInt64List{List: l}.Set(i, x)
+			}
+		}
+		return l, nil
+	}
+	switch et.Which() {
+	case schema.Type_Which_void:
+		return capnp.NewVoidList(seg, n).List, nil
+	case schema.Type_Which_structGroup:
+		id := et.StructGroup().TypeId()
+		node, err := in.nodes.Find(id)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		l, err := capnp.NewCompositeList(seg, structSize(node), n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i := 0; i < int(n); i++ {
+			v := val.Index(i)
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					continue
+				}
+				v = v.Elem()
+			}
+			if v.Kind() != reflect.Struct {
+				return capnp.List{}, typeError(et.Which(), v.Type())
+			}
+			if err := in.insertStruct(id, l.Struct(i), v); err != nil {
+				return capnp.List{}, err
+			}
+		}
+		return l, nil
+	case schema.Type_Which_interface:
+		return capnp.List{}, errInterface
+	}
+	l, err := capnp.NewPointerList(seg, n)
+	if err != nil {
+		return capnp.List{}, err
+	}
+	for i := 0; i < int(n); i++ {
+		p, err := in.newPointer(seg, et, val.Index(i))
+		if err != nil {
+			return capnp.List{}, err
+		}
+		if err := l.Set(i, p); err != nil {
+			return capnp.List{}, err
+		}
+	}
+	return l.List, nil
+}
+
+func newPrimitiveList(seg *capnp.Segment, bits uint32, n int32) (capnp.List, error) {
+	switch bits {
+	case 1:
+		l, err := capnp.NewBitList(seg, n)
+		return l.List, err
+	case 8:
+		l, err := capnp.NewUInt8List(seg, n)
+		return l.List, err
+	case 16:
+		l, err := capnp.NewUInt16List(seg, n)
+		return l.List, err
+	case 32:
+		l, err := capnp.NewUInt32List(seg, n)
+		return l.List, err
+	default:
+		l, err := capnp.NewUInt64List(seg, n)
+		return l.List, err
+	}
+}
+
+var errInsertType = errors.New("pogs: Insert requires a struct or a pointer to a struct")
@@ -0,0 +1,234 @@
+// Package pogs copies data between Cap'n Proto structs and plain old Go
+// structs using reflection.
+//
+// Each Cap'n Proto field is copied to or from the exported Go field
+// with the same name, with the first letter capitalized.  A `capnp`
+// struct tag overrides the Cap'n Proto name that a Go field matches,
+// and a tag of "-" excludes the Go field.  Cap'n Proto fields without a
+// matching Go field are skipped.
+//
+// Field types map to Go types as follows:
+//
+//	Bool                   bool
+//	Int8 ... Int64         int8 ... int64
+//	UInt8 ... UInt64       uint8 ... uint64
+//	Float32, Float64       float32, float64
+//	Text                   string or []byte
+//	Data                   []byte
+//	enum                   a type whose kind is uint16
+//	struct, group          a struct or a pointer to a struct
+//	List(T)                a slice of the type that T maps to
+//	AnyPointer             capnp.Pointer
+//
+// A struct with a union needs a Go field named Which, whose kind is
+// uint16, to hold the discriminant.  Only the union member that Which
+// selects is copied.  Interface fields aren't supported.
+//
+// The schema nodes for the types are found by ID in the schemas
+// registry.
+package pogs
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/schema"
+)
+
+// fieldByName returns the Go field of v that holds the Cap'n Proto
+// field called name.
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		switch tag := sf.Tag.Get("capnp"); tag {
+		case "-":
+			continue
+		case "":
+			if sf.Name == exportName(name) {
+				return v.Field(i), true
+			}
+		default:
+			if tag == name {
+				return v.Field(i), true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func exportName(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// whichField returns the Go field of v that holds the discriminant.
+func whichField(v reflect.Value) (reflect.Value, error) {
+	w := v.FieldByName("Which")
+	if !w.IsValid() {
+		return reflect.Value{}, fmt.Errorf("pogs: %v has no Which field for union", v.Type())
+	}
+	if w.Kind() != reflect.Uint16 {
+		return reflect.Value{}, fmt.Errorf("pogs: %v.Which is %v; want uint16 kind", v.Type(), w.Type())
+	}
+	return w, nil
+}
+
+// isInactive reports whether f is a union member that which doesn't
+// select.
+func isInactive(f schema.Field, which uint16) bool {
+	dv := f.DiscriminantValue()
+	return dv != schema.Field_noDiscriminant && dv != which
+}
+
+func discriminantOffset(n schema.Node) capnp.DataOffset {
+	return capnp.DataOffset(n.StructGroup().DiscriminantOffset() * 2)
+}
+
+func structSize(n schema.Node) capnp.ObjectSize {
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructGroup().DataWordCount()) * 8,
+		PointerCount: n.StructGroup().PointerCount(),
+	}
+}
+
+// primitiveBits returns the size of a primitive type in bits or zero
+// if t is not a primitive type.
+func primitiveBits(t schema.Type_Which) uint32 {
+	switch t {
+	case schema.Type_Which_bool:
+		return 1
+	case schema.Type_Which_int8, schema.Type_Which_uint8:
+		return 8
+	case schema.Type_Which_int16, schema.Type_Which_uint16, schema.Type_Which_enum:
+		return 16
+	case schema.Type_Which_int32, schema.Type_Which_uint32, schema.Type_Which_float32:
+		return 32
+	case schema.Type_Which_int64, schema.Type_Which_uint64, schema.Type_Which_float64:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// defaultBits returns the bits that a primitive field's value is
+// XORed with to encode it.
+func defaultBits(v schema.Value) uint64 {
+	switch v.Which() {
+	case schema.Value_Which_bool:
+		if v.Bool() {
+			return 1
+		}
+	case schema.Value_Which_int8:
+		return uint64(uint8(v.Int8()))
+	case schema.Value_Which_int16:
+		return uint64(uint16(v.Int16()))
+	case schema.Value_Which_int32:
+		return uint64(uint32(v.Int32()))
+	case schema.Value_Which_int64:
+		return uint64(v.Int64())
+	case schema.Value_Which_uint8:
+		return uint64(v.Uint8())
+	case schema.Value_Which_uint16:
+		return uint64(v.Uint16())
+	case schema.Value_Which_uint32:
+		return uint64(v.Uint32())
+	case schema.Value_Which_uint64:
+		return v.Uint64()
+	case schema.Value_Which_float32:
+		return uint64(math.Float32bits(v.Float32()))
+	case schema.Value_Which_float64:
+		return math.Float64bits(v.Float64())
+	case schema.Value_Which_enum:
+		return uint64(v.Enum())
+	}
+	return 0
+}
+
+// primitiveKinds maps primitive types to the kind of Go value that
+// holds them.
+var primitiveKinds = map[schema.Type_Which]reflect.Kind{
+	schema.Type_Which_bool:    reflect.Bool,
+	schema.Type_Which_int8:    reflect.Int8,
+	schema.Type_Which_int16:   reflect.Int16,
+	schema.Type_Which_int32:   reflect.Int32,
+	schema.Type_Which_int64:   reflect.Int64,
+	schema.Type_Which_uint8:   reflect.Uint8,
+	schema.Type_Which_uint16:  reflect.Uint16,
+	schema.Type_Which_uint32:  reflect.Uint32,
+	schema.Type_Which_uint64:  reflect.Uint64,
+	schema.Type_Which_float32: reflect.Float32,
+	schema.Type_Which_float64: reflect.Float64,
+	schema.Type_Which_enum:    reflect.Uint16,
+}
+
+// setPrimitive stores the encoded bits of a primitive value in v.
+func setPrimitive(v reflect.Value, t schema.Type_Which, bits uint64) error {
+	if v.Kind() != primitiveKinds[t] {
+		return typeError(t, v.Type())
+	}
+	switch t {
+	case schema.Type_Which_bool:
+		v.SetBool(bits != 0)
+	case schema.Type_Which_int8:
+		v.SetInt(int64(int8(bits)))
+	case schema.Type_Which_int16:
+		v.SetInt(int64(int16(bits)))
+	case schema.Type_Which_int32:
+		v.SetInt(int64(int32(bits)))
+	case schema.Type_Which_int64:
+		v.SetInt(int64(bits))
+	case schema.Type_Which_float32:
+		v.SetFloat(float64(math.Float32frombits(uint32(bits))))
+	case schema.Type_Which_float64:
+		v.SetFloat(math.Float64frombits(bits))
+	default:
+		v.SetUint(bits)
+	}
+	return nil
+}
+
+// primitive returns the encoded bits of the primitive value in v.
+func primitive(v reflect.Value, t schema.Type_Which) (uint64, error) {
+	if v.Kind() != primitiveKinds[t] {
+		return 0, typeError(t, v.Type())
+	}
+	switch t {
+	case schema.Type_Which_bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	case schema.Type_Which_int8:
+		return uint64(uint8(v.Int())), nil
+	case schema.Type_Which_int16:
+		return uint64(uint16(v.Int())), nil
+	case schema.Type_Which_int32:
+		return uint64(uint32(v.Int())), nil
+	case schema.Type_Which_int64:
+		return uint64(v.Int()), nil
+	case schema.Type_Which_float32:
+		return uint64(math.Float32bits(float32(v.Float()))), nil
+	case schema.Type_Which_float64:
+		return math.Float64bits(v.Float()), nil
+	default:
+		return v.Uint(), nil
+	}
+}
+
+var pointerType = reflect.TypeOf((*capnp.Pointer)(nil)).Elem()
+
+// isBytes reports whether t is a []byte type.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func typeError(t schema.Type_Which, gt reflect.Type) error {
+	return fmt.Errorf("pogs: can't copy %v to or from Go type %v", t, gt)
+}
@@ -0,0 +1,259 @@
+package pogs
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"zombiezen.com/go/capnproto"
+	"zombiezen.com/go/capnproto/internal/nodemap"
+	"zombiezen.com/go/capnproto/internal/schematest"
+)
+
+const (
+	pointID      = schematest.PointID
+	shapeID      = schematest.ShapeID
+	everythingID = schematest.EverythingID
+)
+
+type Color uint16
+
+const (
+	red Color = iota
+	green
+	blue
+)
+
+type Point struct {
+	X, Y int32
+}
+
+const (
+	shapeCircle   = 0
+	shapeSquare   = 1
+	shapePosition = 2
+	shapeNone     = 3
+)
+
+type Shape struct {
+	Which    uint16
+	Name     string
+	Color    Color
+	Circle   float64
+	Square   *Point
+	Position position
+	Tags     []string
+}
+
+type position struct {
+	X       float32
+	Caption string `capnp:"label"`
+}
+
+type Everything struct {
+	B      bool
+	I8     int8
+	U16    uint16
+	I32    int32
+	U64    uint64
+	F32    float32
+	F64    float64
+	Text   string
+	Data   []byte
+	Point  Point
+	Points []*Point
+	Ints   []int16
+	Bools  []bool
+	Colors []Color
+	Matrix [][]float64
+}
+
+// newStruct returns a new struct with the size of the struct type
+// typeID.
+func newStruct(t *testing.T, typeID uint64) capnp.Struct {
+	n, err := new(nodemap.Map).Find(typeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := capnp.NewRootStruct(seg, capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructGroup().DataWordCount()) * 8,
+		PointerCount: n.StructGroup().PointerCount(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		typeID uint64
+		val    interface{}
+	}{
+		{pointID, &Point{X: -1, Y: 42}},
+		{shapeID, &Shape{Which: shapeCircle, Name: "ball", Color: blue, Circle: 1.5}},
+		{shapeID, &Shape{Which: shapeSquare, Color: red, Square: &Point{X: 3, Y: 4}, Tags: []string{"a", "", "c"}}},
+		{shapeID, &Shape{Which: shapePosition, Color: green, Position: position{X: 0.5, Caption: "top"}}},
+		{shapeID, &Shape{Which: shapeNone, Color: green, Tags: []string{}}},
+		{everythingID, &Everything{
+			B:      true,
+			I8:     -8,
+			U16:    0xfffe,
+			I32:    -5,
+			U64:    1 << 63,
+			F32:    float32(math.Inf(-1)),
+			F64:    math.Pi,
+			Text:   "",
+			Data:   []byte{0, 1, 2},
+			Point:  Point{X: 1, Y: 2},
+			Points: []*Point{{X: 1}, {Y: 2}},
+			Ints:   []int16{-1, 0, 1},
+			Bools:  []bool{true, false, true},
+			Colors: []Color{blue, red},
+			Matrix: [][]float64{{1, 2}, nil, {}},
+		}},
+	}
+	for _, test := range tests {
+		s := newStruct(t, test.typeID)
+		if err := Insert(test.typeID, s, test.val); err != nil {
+			t.Errorf("Insert(%#x, s, %+v): %v", test.typeID, test.val, err)
+			continue
+		}
+		out := reflect.New(reflect.TypeOf(test.val).Elem())
+		if err := Extract(out.Interface(), test.typeID, s); err != nil {
+			t.Errorf("Extract after Insert(%#x, s, %+v): %v", test.typeID, test.val, err)
+			continue
+		}
+		if !reflect.DeepEqual(out.Interface(), test.val) {
+			t.Errorf("Extract(Insert(%#x, s, %+v)) = %+v", test.typeID, test.val, out.Interface())
+		}
+	}
+}
+
+func TestInsertEncoding(t *testing.T) {
+	s := newStruct(t, everythingID)
+	err := Insert(everythingID, s, Everything{I32: -5, U16: 7, Text: "hi", Colors: []Color{green}})
+	if err != nil {
+		t.Fatal("Insert:", err)
+	}
+	if x := s.Uint32(4); x != 0 {
+		t.Errorf("i32 = -5 encoded as %#x; want 0 (XORed with default)", x)
+	}
+	if x := s.Uint16(2); x != 7 {
+		t.Errorf("u16 encoded as %d; want 7", x)
+	}
+	if p, err := s.Pointer(0); err != nil || capnp.ToText(p) != "hi" {
+		t.Errorf("text pointer = %q, %v; want \"hi\", <nil>", capnp.ToText(p), err)
+	}
+	if p, err := s.Pointer(1); err != nil || capnp.IsValid(p) {
+		t.Errorf("nil data encoded as %v, %v; want null pointer", p, err)
+	}
+	p, err := s.Pointer(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := (capnp.UInt16List{List: capnp.ToList(p)}); l.Len() != 1 || l.At(0) != uint16(green) {
+		t.Errorf("colors = %v; want [1]", l)
+	}
+}
+
+func TestExtractDefaults(t *testing.T) {
+	s := newStruct(t, everythingID)
+	var e Everything
+	if err := Extract(&e, everythingID, s); err != nil {
+		t.Fatal("Extract:", err)
+	}
+	if e.I32 != -5 {
+		t.Errorf("I32 = %d; want -5", e.I32)
+	}
+	if e.Text != "hi" {
+		t.Errorf("Text = %q; want \"hi\"", e.Text)
+	}
+	if e.Data != nil || e.Points != nil || e.Matrix != nil {
+		t.Errorf("null pointers extracted as %v, %v, %v; want nil slices", e.Data, e.Points, e.Matrix)
+	}
+
+	var sh Shape
+	if err := Extract(&sh, shapeID, newStruct(t, shapeID)); err != nil {
+		t.Fatal("Extract:", err)
+	}
+	if sh.Color != green {
+		t.Errorf("Color = %d; want %d", sh.Color, green)
+	}
+}
+
+func TestExtractUnionClearsInactive(t *testing.T) {
+	s := newStruct(t, shapeID)
+	if err := Insert(shapeID, s, &Shape{Which: shapeCircle, Circle: 2}); err != nil {
+		t.Fatal("Insert:", err)
+	}
+	sh := Shape{Which: shapeSquare, Square: &Point{X: 1}}
+	if err := Extract(&sh, shapeID, s); err != nil {
+		t.Fatal("Extract:", err)
+	}
+	if sh.Which != shapeCircle || sh.Circle != 2 || sh.Square != nil {
+		t.Errorf("Extract = %+v; want circle 2 with no square", sh)
+	}
+}
+
+func TestTags(t *testing.T) {
+	type shape struct {
+		Which    uint16
+		Name     string `capnp:"-"`
+		Title    string `capnp:"name"`
+		Position *struct {
+			Label string
+		}
+	}
+	s := newStruct(t, shapeID)
+	in := &shape{Which: shapePosition, Name: "ignored", Title: "tri", Position: &struct{ Label string }{"top"}}
+	if err := Insert(shapeID, s, in); err != nil {
+		t.Fatal("Insert:", err)
+	}
+	if p, _ := s.Pointer(0); capnp.ToText(p) != "tri" {
+		t.Errorf("name = %q; want \"tri\"", capnp.ToText(p))
+	}
+	if p, _ := s.Pointer(1); capnp.ToText(p) != "top" {
+		t.Errorf("position.label = %q; want \"top\"", capnp.ToText(p))
+	}
+	var out shape
+	if err := Extract(&out, shapeID, s); err != nil {
+		t.Fatal("Extract:", err)
+	}
+	if out.Name != "" || out.Title != "tri" || out.Position == nil || out.Position.Label != "top" {
+		t.Errorf("Extract = %+v; want Title \"tri\" and Position.Label \"top\"", out)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := newStruct(t, everythingID)
+	if err := Extract(Point{}, pointID, s); err != errExtractType {
+		t.Errorf("Extract into non-pointer error = %v; want %v", err, errExtractType)
+	}
+	if err := Insert(pointID, s, 42); err != errInsertType {
+		t.Errorf("Insert of int error = %v; want %v", err, errInsertType)
+	}
+	if err := Insert(shapeID, s, struct{ Name string }{"x"}); err == nil {
+		t.Error("Insert of union struct without Which succeeded; want error")
+	}
+	if err := Insert(pointID, s, struct{ X string }{"x"}); err == nil {
+		t.Error("Insert of string into Int32 field succeeded; want error")
+	}
+	var wrong struct{ Points []string }
+	if err := Extract(&wrong, everythingID, s); err != nil {
+		t.Errorf("Extract of null list into []string: %v", err)
+	}
+	if err := Insert(everythingID, s, Everything{Points: []*Point{{}}}); err != nil {
+		t.Fatal("Insert:", err)
+	}
+	if err := Extract(&wrong, everythingID, s); err == nil {
+		t.Error("Extract of List(Point) into []string succeeded; want error")
+	}
+	if err := Extract(&Point{}, 0x1234, s); err == nil {
+		t.Error("Extract with unknown type ID succeeded; want error")
+	}
+}
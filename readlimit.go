@@ -0,0 +1,56 @@
+package capnp
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// A ReadLimiter tracks the number of bytes read from a message in order
+// to avoid amplification attacks as detailed in
+// https://capnproto.org/encoding.html#amplification-attack.
+// It is safe to use from multiple goroutines.
+type ReadLimiter struct {
+	limit uint64
+}
+
+// canRead reports whether sz bytes may be read and, if so, deducts
+// them from the limit.
+func (rl *ReadLimiter) canRead(sz uint64) bool {
+	for {
+		curr := atomic.LoadUint64(&rl.limit)
+		ok := curr >= sz
+		var next uint64
+		if ok {
+			next = curr - sz
+		}
+		if atomic.CompareAndSwapUint64(&rl.limit, curr, next) {
+			return ok
+		}
+	}
+}
+
+// Reset sets the number of bytes allowed to be read.
+func (rl *ReadLimiter) Reset(limit uint64) {
+	atomic.StoreUint64(&rl.limit, limit)
+}
+
+// Unread increases the limit by sz.
+func (rl *ReadLimiter) Unread(sz Size) {
+	atomic.AddUint64(&rl.limit, uint64(sz))
+}
+
+// Default read limits.
+const (
+	defaultTraverseLimit = 64 << 20 // 64 MiB
+	defaultDepthLimit    = 64
+)
+
+// maxDepth is the depth limit given to objects that are created rather
+// than read, since they can't be part of a pointer cycle.
+const maxDepth = ^uint(0)
+
+// Errors returned when a read limit is exceeded.
+var (
+	ErrTraverseLimit = errors.New("capnp: read traversal limit reached")
+	ErrDepthLimit    = errors.New("capnp: depth limit reached")
+)
@@ -0,0 +1,157 @@
+package capnp
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestReadLimiter(t *testing.T) {
+	var rl ReadLimiter
+	rl.Reset(42)
+	if !rl.canRead(40) {
+		t.Fatal("canRead(40) with limit 42 = false; want true")
+	}
+	if rl.canRead(3) {
+		t.Error("canRead(3) with limit 2 = true; want false")
+	}
+	if rl.canRead(1) {
+		t.Error("canRead(1) after exhausting limit = true; want false")
+	}
+	rl.Unread(8)
+	if !rl.canRead(8) {
+		t.Error("canRead(8) after Unread(8) = false; want true")
+	}
+	rl.Reset(16)
+	if !rl.canRead(16) {
+		t.Error("canRead(16) after Reset(16) = false; want true")
+	}
+}
+
+func TestTraverseLimit(t *testing.T) {
+	msg, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewRootStruct(seg, ObjectSize{PointerCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := NewData(seg, make([]byte, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := root.SetPointer(0, data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the root struct costs one word.  The list costs 64 bytes.
+	msg.TraverseLimit = 8 + 64*3
+	p, err := msg.Root()
+	if err != nil {
+		t.Fatal("Root:", err)
+	}
+	root = ToStruct(p)
+	for i := 0; i < 3; i++ {
+		if _, err := root.Pointer(0); err != nil {
+			t.Fatalf("read #%d of pointer: %v", i+1, err)
+		}
+	}
+	if _, err := root.Pointer(0); err != ErrTraverseLimit {
+		t.Errorf("read #4 of pointer error = %v; want %v", err, ErrTraverseLimit)
+	}
+
+	msg.ReadLimiter().Reset(64)
+	if _, err := root.Pointer(0); err != nil {
+		t.Errorf("read after ReadLimiter().Reset(64): %v", err)
+	}
+}
+
+func TestTraverseLimitEmptyElements(t *testing.T) {
+	// Each element of a list of empty elements is charged a word, so
+	// these lists take 4 GiB of the default 64 MiB limit.
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"void list", rawWords(
+			rawListPointer(0, voidList, 1<<29-1),
+		)},
+		{"empty struct list", rawWords(
+			rawListPointer(0, compositeList, 0),
+			rawStructPointer(1<<29-1, ObjectSize{}),
+		)},
+	}
+	for _, test := range tests {
+		msg := &Message{Arena: SingleSegment(test.data)}
+		if _, err := msg.Root(); err != ErrTraverseLimit {
+			t.Errorf("%s: Root() error = %v; want %v", test.name, err, ErrTraverseLimit)
+		}
+	}
+}
+
+func TestTraverseLimitEmptyStruct(t *testing.T) {
+	// The root struct's pointer points to an empty struct.  Reading
+	// either struct costs a word.
+	msg := &Message{
+		Arena: SingleSegment(rawWords(
+			rawStructPointer(0, ObjectSize{PointerCount: 1}),
+			rawStructPointer(-1, ObjectSize{}),
+		)),
+		TraverseLimit: 8 * 2,
+	}
+	p, err := msg.Root()
+	if err != nil {
+		t.Fatal("Root:", err)
+	}
+	root := ToStruct(p)
+	if _, err := root.Pointer(0); err != nil {
+		t.Fatal("read #1 of pointer:", err)
+	}
+	if _, err := root.Pointer(0); err != ErrTraverseLimit {
+		t.Errorf("read #2 of pointer error = %v; want %v", err, ErrTraverseLimit)
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	// The root struct has a single pointer that points to itself.
+	data := make([]byte, 16)
+	binary.LittleEndian.PutUint64(data[0:], uint64(rawStructPointer(0, ObjectSize{PointerCount: 1})))
+	binary.LittleEndian.PutUint64(data[8:], uint64(rawStructPointer(-1, ObjectSize{PointerCount: 1})))
+	msg := &Message{
+		Arena:      SingleSegment(data),
+		DepthLimit: 4,
+	}
+	p, err := msg.Root()
+	if err != nil {
+		t.Fatal("Root:", err)
+	}
+	for i := 0; i < 3; i++ {
+		p, err = ToStruct(p).Pointer(0)
+		if err != nil {
+			t.Fatalf("read at depth %d: %v", i+2, err)
+		}
+		if !IsValid(p) {
+			t.Fatalf("read at depth %d returned invalid pointer", i+2)
+		}
+	}
+	if _, err := ToStruct(p).Pointer(0); err != ErrDepthLimit {
+		t.Errorf("read at depth 5 error = %v; want %v", err, ErrDepthLimit)
+	}
+}
+
+func TestDefaultDepthLimitStopsCycle(t *testing.T) {
+	data := make([]byte, 16)
+	binary.LittleEndian.PutUint64(data[0:], uint64(rawStructPointer(0, ObjectSize{PointerCount: 1})))
+	binary.LittleEndian.PutUint64(data[8:], uint64(rawStructPointer(-1, ObjectSize{PointerCount: 1})))
+	msg := &Message{Arena: SingleSegment(data)}
+	p, err := msg.Root()
+	for n := 0; err == nil; n++ {
+		if n > defaultDepthLimit {
+			t.Fatalf("followed %d pointers without error", n)
+		}
+		p, err = ToStruct(p).Pointer(0)
+	}
+	if err != ErrDepthLimit {
+		t.Errorf("error = %v; want %v", err, ErrDepthLimit)
+	}
+}
@@ -5,6 +5,7 @@ package testcapnp
 import (
 	context "golang.org/x/net/context"
 	capnp "zombiezen.com/go/capnproto"
+	schemas "zombiezen.com/go/capnproto/schemas"
 	server "zombiezen.com/go/capnproto/server"
 )
 
@@ -1024,3 +1025,41 @@ func (p Adder_add_Results_Promise) Struct() (Adder_add_Results, error) {
 	s, err := p.Pipeline.Struct()
 	return Adder_add_Results{s}, err
 }
+
+const schema_ef12a34b9807e19c = "x\xda̖]h\x1cU\x14\xc7\x7f\xb33qSR\xa5\x1bZ\xd3Ҋ\xb6\xb6\xdaT\xba[D\x90\x06\xac\x16\xb3F\xac_I\xa9hEa\xb2{٤lv\xd6Ɇ\x9a\x16\xa9\xd6\x06\xb5*\xd2\xf8b\xa0\x16\xfc" +
+	"\b\xa5E\xc1B#\xf8\xe2[E\x03\x8aUR\xccC\xb1\xf5\xc5\x0f\x90Ԫ\xa0>\x14\x99\xe4\xce\xce\xcc\xdd\xf9ب\x0f\x9e\x87]\xee\xb9\xf7\xfc\xef\xff\xfeϹ\xf7\f\xc0\x9f\x1a\x00@\x8a\x16\xe0[\xe7\a\x00\xe8\x06" +
+	"Z\xb8\x8a\xa3\x17\xd3o\xecx\xa7}\xce7\x15j3)\xd8\r̦\xa0\x04t\xeap{\xcc\xfa\xb3_\xee\xf8\xfe\xd7\xf3\xe6sm\x80\x0e\xf5}\xd4uB\x87q`H\x874\x9e\r\xca\xf1\xa0\xe2\xff\xa2\xed\xcaȕ" +
+	"\xe3G\x0e%\xe1\xf6\xea0\r<\xa1\xc4?\xa6C\x0f0\xa6\xf8\x9fl\x9b\xbc\xf4z\xc7\xc1\x89U\x80\x86\xdfҁ\x91óO\xa3i{\xc5x\xf3L\xa6w\xff\x8c\x8a\xab)\xb8\x0e\xaf\xdd!\xb8B\x87;\x1b\xddT" +
+	"\xde\xfe\xe1d\xf7\xb3\x17\x0e'\xe90&\xf5}U9\xefKR\x87S\x8aߺ4\xb6t\xcd\xe6\xde\xd3\xed\t:L\xe8p\xb9y\x19\xf8\xa0\xe7\xe7]\x8f\xe7?\xfb(\t\xf7\x19\x1d\xfeZ\x04\xee\xe4W\x9f/\x7f~" +
+	"\xfa\xccx\x92\x0e\xa6\x0e'C\xeaIH\x1d\x8e\xa8:\\{\xff\xe49s\xdb\xc4\n@\v0\xf6\xaf\x82Q\x1d\xfaC\xf26\x16\x91\xb7G\xb7\xbdp`n\xc9\x1f/&\xe1\x9e\xd0aO\b\xeeT\x04n~\xd3\xeaw" +
+	"?y\xa4#\xf1^\xcc\xc8z8\xaf\x9cwV\xea\xa0\x19Ёg_\xbfuvf:\xbf\xe7\xc7\xf6\x84\xfa\xfd%\xa2\x1e~\x8f\xe0\xfb\xe9\xd6o\xf6\x1f:\x9d\x7f9\tw\x85\x11^\x0f\xab\x8cp\xdc\xeb\x7f[ݶ" +
+	"뽣\xaf%\xe9\xb0Հ\xc3\xc0v#\xa8\xc3\x1d\x06\xf4\x00B\xf1ϝ{05ul\xf6X&!o\x0f\x18\xf0]#-z\r\xd8\xdb\xe8f\xe7\xe5\x9b\xc5\xe8\xc6\xee\xe3I\xb8\xef\x1b\xf0Sc8\xa7\"t" +
+	"\xa8\x89\xe1Z\xb6`V+U\x00X)\xf1\xddw\xb9\x13\xe8\xf2\xbd\xa7\x1b\x00\xdb\xf7\xael\x90\xf3\xee\xfdZ\v\xf4\xf9\xeal\xad\x9cw\xf5\xbe\x0e\xb8\x15\xb8\u05ec\x14\xcb\xc2\xfd\xbf\xc7,\xd4,{t\xc1_\x126\xdc" +
+	"m\x96\xcb\x0f\xd9Ea/\xd0\"_\x18\xb0\x9c\xc1\xf6₯\x15\xd0Hq\xe1\x96\xd1\x1b\x97\x1d8\xf1\xf12 \x85\xc6\r\x00hLu_s\x93\xf6ᖋk\xa4\xff.\xe9_J\xd04\xc9\xd7\xd1\xc1'C\xdd" +
+	"\xc2֏k\xb0\xcf\x1a\xea\x1f\x14\xfbD%[\xb0\x86r%+7\x1fl[5+gW\v\xb9\xc1JM\xd8\x15\xb3\x9c\x8b\xc2\xf5t\xefr\xa5\xc0\xdbC\x03@\xa7\xa5>nX\xefI\x16\x883|q\xfe~\xe5\xf6" +
+	"\x97\x8c<o\x94-\x01\xd2@E\xecm\xe0\x05Z\"\x9fl=p\xfdæm\x0e\rG\x9d\xb7q}\x9f\x18\x1e)\xd7d\xc0\xc29\x8c\x86\xbd]\xbb\x1a\xe8\xf2\x86\xb4\x02:\x1a\xcbe\xbe\a$\xf9\x8c\U0009d85e" +
+	"7\x13\xa1C\x90oIؾ\xb90\x9d\xdd~\xe8\xf6\xaf\f\xb0)F\xe7\x16 \r\x0e\xcfR\xb2\xbe%ag\x9d\x95\x9e\xa6\x11\xf3u\r}\xf3\xeaU\n\xe7/\xfb\x98\xdbw2\xc0\xc1&\xea\xa4$j\x0e\xfeN\xf1" +
+	"Ԉ\xa8\x14D\xdc9\xea<\xb2J\x90w\xaa\xe6\xf2~\x9fr?u4Vʼ\x8b\xa7\xab\xa2P\x13E\x7f]\xc4Ykr\xfe\xa3y\xf7y\x15\xdb\f\xef\xf6\x98z\xad\xfcw|ݧ2\xb6^\xdd~\xed\xf6\xd7" +
+	"f\xebU\x14\x06,?\xb2!\xf1\xdd\xf7?\x92O։\xf42\u074c^\xebb\xf4*\x98\xd5\xc0\xfdU\xf7_\xc4\xfd\xf6\xf3\xabg\xf4\x7f\xc4\xcfmy\xb1\xf9t\xbf;\xfc\xdf\t\xeb\x9aȧY,&\xbd\xef\xf3\xfb" +
+	"g\xcdb\xd1˞{\xdeh}:\x95z_/\xf5\xd9\"\xf5\xd1\x02\xbby\xb6Y\x89\xdb(\xe3n\x93q&\x00\x04v\x0e\xb3\xa8\xf9\xfe\x7f\x19\x1f\xaa\xcb\"߁\xb8\xbee\xcfC\xfds~\x7f\x0f\x00\xc1\x18\xe5\xac"
+
+func init() {
+	schemas.RegisterCompressed(schema_ef12a34b9807e19c,
+		0x8161ddf3e74bd0d1,
+		0x841756c6a41b2a45,
+		0x8491a7fe75fe0bce,
+		0x88f809ef7f873e58,
+		0x8ae08044aae8a26e,
+		0x8b45b4847bd839c8,
+		0x8f9cac550b1bf41f,
+		0x92c5ca8314cdd2a5,
+		0x993e61d6a54c166f,
+		0x99821793f0a50b5e,
+		0x9ed99eb5024ed6ef,
+		0xa74428796527f253,
+		0xb4512d1c0c85f06f,
+		0xb9c9455b55ed47b0,
+		0xd57b5111c59d048c,
+		0xe96a45cad5d1a1d3,
+		0xef12a34b9807e19c)
+}
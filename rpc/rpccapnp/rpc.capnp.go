@@ -5,6 +5,7 @@ package rpccapnp
 import (
 	strconv "strconv"
 	capnp "zombiezen.com/go/capnproto"
+	schemas "zombiezen.com/go/capnproto/schemas"
 )
 
 type Message struct{ capnp.Struct }
@@ -2517,3 +2518,75 @@ func (l Exception_Type_List) Set(i int, v Exception_Type) {
 	ul := capnp.UInt16List{List: l.List}
 	ul.Set(i, uint16(v))
 }
+
+const schema_b312981b2552a250 = "x\xda̚{\x8cT\xd5\x1d\xc7?gg\x81e\xdd\x17\xb0H\xa1\x9a\xfa\xa8M\xb5\x15ĘjhM\xe5\x19!\x18\xd6u\xa9@%\xedݙ\x03\\\x98\x9d;ܹ\v\xac\x91\x14[m\xd4J\x8a\xc6\a\x1am\xd5" +
+	"h\xa2\x16# [\xb1\x95F\tM\xb5Ѫ\xf1\x11m4\x15\x13Sm\xda\xc4\xd6GE\x1e\xdb\xcc\xce9\xf7q\xe6\xce\xdcagi\xfc\xfd\xb1\xd93\xf7~\x7f\xe7\x9c\xdf\xf9=\xbe\xe7\x9c\v\xb0a,\x00@\x03c" +
+	"\x80;\xc7\xe1\xcb2`\fc\xe9z\xa0\xfb\xac\xafn\x9f\xf8D#\xd5eW\n\xba\x81\xc1\x14L\x11p\x7f#\\X\xe5\xfd\xf3wn\xdd\xf2\xb5_?yK3 \x10~?\x82q@\x1bZ^o\x84[\x81w\x1a" +
+	"!4<\xdej\x84)\xa9r\xbd\arSfnyi\xf1\x87\xf1z\x03\x99\xda\x04\x0f\x00\xa77E\xf5\x9e\xda\x04\x1b\xcb\xd5\xf2\xda\xe2{7\xfd劵?+\xe9M\xf9zS\x86\xde\xeb\x9b\xe0j\xe0FCo\xf1" +
+	"\xf7_\x8ar\xbdw\xfd\xe87\xd3>\xdb\xf9\xc1_ە^\xddOQo\xb1\x9d\x02\x00\xc67\xc3?\xca\xe1\xb46\xc3\xe3\xe5?3\xeb\xca'.\u07ba{\xea\xafJ\xe3m0\xec0\xd6\xd7\xfbX3\xdc\x04\f6G" +
+	"ǻ\xab\x19\xfe\x143\xdet\xdb\xe7\xfbwO\xdf\xfc\xaai\xdf(\x1a\xeeo)\xe9}\xa4%\xfa䡖x\xfb\xe6\x9an\xfcb\xe9\x1d\xfb\x7f\x1f\xbfn\r4\xe8\xf9\xb6\u00ad\xc0\xe4֨\xde\t\xad\xf1v\xb8\xd3" +
+	"{yskv\xdacI\xe3ݥ\xf4>e\xe8\x1dl\x8d\x1f\xef\x84\v\xf7\xb5\xff\xe2;\x99CI~6\xb9\r\x1e\x05Nm\x8b\xea\x9d\xda\x16\xaf\xf7\xb2w~(\xff\xb6\xa7\xf7\xf5\xc9J\xaf\ue9e8W\xd0H\x03" +
+	"\x00\xb0\xbc-\xde\x1fV\xb6\xc1\xdf\xcb\x7ffŎg\x0f\xafk\\{\x8f9\xde\x06c\xbc\x17\xb7\x97\xec0\xbf=:\xde\xd9\xed\xf1\xf6=\xf9\x92\xa5\xdbz\a\x9f\x7f-\xc9\x0e\a\xdb\xe1&\xe0CC\xef\xfb\x15\xf4>" +
+	".\xde\xddָ\xfd\xe0\xe1\xa4\xf1^ߡ\xe2\xadÈ\xb7\x8ex\xbd\xcf\x1e\x9e\xb4\xac\xa7\xf3\xe9ۓ\xfc\xec\xf4\t\xb0\x1f8{BT\xefY\x13\xe2\xd7\xed\xbb\xde\x1d\x17\x9fa\xb5\xdd]\xd2K\xc5\xf1\xde8\xa1d" +
+	"\xdf[\f\xbd[+\xe8ݹ\xf7\xe7'\x1dx\xe7\xcc\xeb\xe3\xc7\xeb'p\x8e\xaa\xf1\x8e\x99\x18\xd5+&\u00951q|\xdf)\u038bo\xec\xe2ͤu\x93\x93\xe0\xcf\xc0\xfaI0\x85@\x06&ŏ\xf7\xdam?" +
+	"\x98<ﶓ?\x9e\xaa\xf4\xea~P\xf6\xd5\xf2\xafI\xf0\x1ephRt\xbc\x9fVл\xb9ᣃG\xc7\xe5_M\x1a\xef\xd2N\x98(@vF\xf5Z\x9d\xf1z\xa7\xd9/\xcd\x1a\xfb\xe4Yo$\xe9\xdd\xd7" +
+	"\t\x0f\x00\x7f\xec\x8c\xda\xe1\xc5\xce\xf8x[\xb6o\xf1\xf7\u07fb\xe3\xe6ݝ@C\xa8\x9f\xb2\xba9\xb9d\xdf}\x93\xa3\xe3}j2\xac.W\x8b\x9bOOO[\xf9\\\x1e\x00\x98\a\b\x84_O\xaf\x05\xe6\x84\xea" +
+	"\xe0f\xa0;T\xbf\x8a\xedsB\xf5a\xa0\xf8\x7f(\xaf\xf7\xab\xb6\xce\xc7\xeb\x95>\x9dG\xfbT[\xe7%\x1bX\x11\xca/\xb6z\xae\xf3\x82T\xfat<[\xaa\x7f\x1d\x87+\x017\x14?+\x15^\xfb\xfdr\xf5\\" +
+	"\xfb\xd1rJ\xf1\xae\xfda9%\xbf\xd7\xf6]\xa9\xe6{\x99,\x14\xacՒ9\x8e\xe3\x15<\xd7\xca\x03\x00s\xadl\x16\xa0[z\xfdn\x0e\x16\xd89\xbb\xb0\xa6\xd8.8\xd9\r\x92n\x99\x95VA2\xcf.Ⱦ" +
+	"^\xcb]\xed\x00@\x97\xebl\xb03\x92\xd9\xe9\xb4\xcc{\xb0ȱs\x04\xfd\xf4X\xeej\xe9\x01]\xd6@ֱ2̵\xf2\xf3d!\xed\xday\xcfqK\xf8>\xbb 3\xb3s\x85\x8d҅\x9e5\xb6\x9b\xe9\xb2" +
+	"\\o \xfa\xe6\xfcME\xfd\xb6\x93\x03\x00\x9a\x00A\x03\xef~k\xe0̎\x9f<\xf2\x87\x0e\xa0\x01\xc1i\x00\b\x06\xe7\xb5}C\xfc\xf6\xbc\x83\xa7\xa8\xdf/\x02@\xd0BT\x04\xb0\xa8\xe4?a\xf7\xf1%\xee\xfd9" +
+	"\x02\xaev\xfazmy\xb5\xccMO;}3V;3\x86Ѯ\xe393\xdc|zF\x99:\xdf?g\xe9\x15\bD\xa8\xf1\xa5h\x04\x86\x86\xf4\xafa\x19\x10\xe0\x06M6\tH!\xb8N\x94\xe6'86\x14h" +
+	"\v\xe5\x1d\x01\xe7\aM\xaeQ\xb8\x1b\x14.\xc5Q\x1f\xd7\x10\xae\x13\x02\xce\t\x9alQ\xb8\x9b\x15\xae\x91#>.\x15\xce\xe3\x02f\x05M\xaeS\xb8m\n7\x86\xc3>.̝\xb7\x1a\xb8\x1b\x14\xee6\x85\x1b\xcb\x17" +
+	">n\f\x81\xdc\"`N\xd0\xe4f\x85ۮp\xe38\xe4オ\x00\xb7\x1b\xb8m\nw\x8f\u008d\xe7s\x1f\x17\xce?w\tX\x1b4ٮp\x0f*\\\x03\xff\xf5qMa\xde'\xa0;\\g\x14n\x87\xc2" +
+	"5\xf3\x99\x8f\x1bO \x8f\x14}-h\xf2\xb0\xc2\xedQ\xb8\x93\xf8\xd4\xc75\x87\xf3\xa71\xbf\x1d\n\xb7W\xe1Z\xf8\xc4ǝ\x14\xe6u\xc6:\xecT\xb8\xa7\x15\xae\x95\x8f}\\86\x9e2\xfce\x8f\xc2=\xa3p" +
+	"M\xfc\xc7ǵ\x86뇀\x15A\x93\xa7\x15\xee9\x85\xeb\xcf\xd9}\xf9\xac\xec\x939Of\x80\x0e\x80\xd0>\tC:\x88\x17\xab\xd7q\xbd\xd0\xf3Ju\xa7\x12>\xad\xf2\xa3~\xae\xebF\xadxW\xe5\xd5\x0ec\x1f" +
+	"R+~\x95\xca\xc7\x1d\xc6~\xa3\xf6\xfeKy\xbc\xc3\xd8WԎ/\xe5\x7f\xfd\\\u05fdZ\xf1No\xc1\xc9JO^am\x90\x00\x13\xa9.\x95\x9e\xf7\x1auK\xf7\xa7\xeb\xfa\xf1\x8eg\x9e,\xfe\x1d\xf9x\xf2\xaa" +
+	"\xfe\xe9~t\xbd\xaf\xd9/U\xdd\xd4\xcf5?\xa8\x15\xbfV\xd5[\xfd\\\xf3\x89Z\xf1\x19\xa3\x9e\xeb\xf74\x8f\xa9\xd9?\xfc\xfaf2\v\x10\b\x9a\xfc\xfa\x16\xfc\x1a\x96\xb3\x89\xe6\x81o\x02)\x04\x17\xa0\xeb[X[" +
+	" 3\x81\xbb\x83&\x17(\xdc%\n\xb7\xbe_\x16\x8a\xb4aa\x06\x80HN\x8e\x93J\xcf32\xefʴ\xe5\xc9̒\u07b52\xed-\xccT\xf7\x8b$\xbf\t쥙\x17\b\x04\xa7$\xd8iа\xd3\x1e5\xdf" +
+	"g\x12촏h^߫p\a\xd0u\vD\f\x0fx\x16\xe8\r\x9a<\xa3p/\xa0\xf9\x03\xea\xfd\xd2\xffZ\x9e\a\x16\x05M\x9eS\xb8\xd7\xd0< \xe8/<\xdbW\x8cq\xbe\xa0po\xa2y@\xd4*\xfa\x9c" +
+	"\xe8u\xa2\xfcȔF`K\xa8.\x8b\xd0\xfc\xf6\x13\xc8\x01\xd5\xdfˣ\xec?\x9e\"\xc2\x1d\xba_\xc5\xf3k\x8d/;\xe7Iw\x95\x95\x96j \xe3\x13\xc6Q\xe9y\x9f\xf4\xd68\x19\xa5\x04\xf3\xb4\xa7\\*=\xcf" +
+	"[\xae\xd5W\bƫ\xf7)\xb5Χ s\x99nY\xe8\xcfz\x85\x1e\a\xb0\xb2Ygc@\xfe{,;;\x1c\x18\"a|\xa2\xa6\xf8\x9anvGK\f\xdf\x0e\xf3\xca\xf9\x86\x1f^\x02\xa4\x10,\xa6\x9co\x87" +
+	"y\xe5B\xc3\xef/U\xb8\x1e?Ύ\x0e\xe9x\t\xdb\xf6r#\xae\xbb\x14\xee*\x85+\xf2\x0f\xe92b\x19p\xfa݂̮\xa2N\xf1\xfc%\x1a\xad\xfc\xa7w\x9c\x81\b\xb5\x8f\xab\x96\a_1\xec\xfc\xb2\xb2\xd7" +
+	"\xdb\xfe\xfa\xe0\xef\x05E\b\xfd\x16p\x13\x81\xbc\xadp\x1f\xf8\xeb3\x14\xbb\x1fz\x9f(\x9f~W\xe1\xfe\xe9\xe7\xc1c\xb1\xfb\xa1\x0f\x89\xf2\xfe\x0f\x14\xee\x13\xf4>\xeah\xec~\xe8\xdf\xc6\xfc>R\xb8#~\xfe<\x12" +
+	"뷇\x80G\x83&G\x14\xae\xc9\xdfG\x1d\x1ej\x88\xf1\xdb1\x02v\a\xcd\xe1\xf7\x8b\xb8N\x7f\x1f\xf5E\xec~h\x82\x88\xf6שp\xa7)\x9c5\xbc\xaf_\x98IʏI\xcf\x15\xff\xec*\xa6\x9d\xb9V\xbe" +
+	"\x90\x14\xff\xf1U0\xaco8\x19\x8c8\x7fI\xe3<b\xe4\xfb\x8a\\Zfe\xa6\u07b8T\xf3\xb9B\xe6\xbc\xf9قܸF\xba\xb2\xae8\xb7\xd6\xc9\x05\xaeӷ\xc4[#\xdd\xcbU\x11\xacc\xfdJ<\xb7\xa8" +
+	"\xb1'\x92A\xea\xcf\x1f\xfa\xa4*\xba\xe6\xa3\xc17\xe3\xf2\xc7L\xe0V\x029Q|S\xf9{\xa9f\x85\x1c~\xc4\xfe\x1eʷ\xa5\x9d`\x14Ӓ`\xaf\xf9F\x1e\x9b\xa7\xe6\xdd\xe5\xdbk(\xf6\xfc\xe92\xe0\x8c\xaa" +
+	"\xf5\xf0Xl\xbe\xbd\xdc\xe8Ϭ\x87\xf9ҹ\xe1\xc2L\x82\x1d\x93윶\xf2\x91\xf8\xd4\xe7\xab\xff\xef<\x10^\x9f\xe1\x95\x1f\x81?O\v\x9a|]\xd9\xeb<\x02\x7f\x161\xebs.\xd1\xf3\xa5o+\xdcE\ng" +
+	"\xd7\xedǫ\xa4+si9\xd7\xe9\xcfyu\xe8\xf1\xedc\x9e@\xd7n\x9fY\t\xf6\t\xa3\xf4}\xe3\xb9F\xdd\x1fm^\x9fvr\x9e\xdc\xe4\xc5\xceo\xba~\xa8\xf0&_\r\xcf\xd02\xd6\xf1\xc7j~\xd9\x18\xbe" +
+	"\x1a\x8e3\xdb\xe0CY\x85\xdb\x14\xe2\xab@\x19\xaf\xe97\xec\x99W\xb8k|>t$\x96\xd7\f\x18\xf6\xf4\x14n\x8b\xc2\x15\x89\xbat\x17;N\xbe\xd7J\xaf\xab\xc7\xef\xd2\xd2\xde\x10\xd6T\x9f\x1f\x97\xea\xd7\xc8\xeb\xa9" +
+	">'\xaa\xdf\xff\xf5\x8d\xcb\xf1\xe7\xef\x15U\xf37T\xca߳\xaa\xe6\xef\xf8s\x83\xa4\xfc\xfde\xd9W\xbb2m\xe7m\x99\xf3\x00F\x85\x8f\xcc.\xf3\x94\x13\xbd>a;/V\xb8e\xa1\xf59-f}\x96\x1aq" +
+	"x\xa2\xd6g\xd8\xef\vA}\x1c\xa9}uʯ\xff<@\xdfP~y\xe3fN\x95\xf5\xfc\xb2\xc4\xcd:9P\xe4\xf0\xa3\x10/\xe6M\xb1Yχ\x86*\xd5\xf3ު\xfc=\xfe>t\xa6Q'\xcfS\xb8\xefi" +
+	"\xbeӗw\\Of\xe6Z\xf9z\xfd>r\xaf\xdd\x01\x10\xba\xaf\xaf9?\x05y_ݠ\x13\xf1\xb8ZxϜ\x04\xdeS\x89\x17.\x8aᅗ*\xdc05ɍ|\xfd\xd3V\xbe\xc7\xea\xcdJ\x00\"_6" +
+	"\x96K\n\x91\xc8\xcf\xdb\x12\xedg~y\x00\x02\xc1W\x12\xfc\xec.\xa2\xf7\x9a\xb7\xa9\xf1\xdc\x17\xf2\xb38\xfb\xddK\xf4\x9e\xf8\x1e\x85{8ī\xe2\xe2\xff!\xe3\x1c\xfbA\x85\xdb\x19\xe2U\"\x86\x8f=f\xf8\xf5\x0e" +
+	"\x85\xdb\xeb\x9f3\xc5\u07fb\x0f\x1a8\xf3>aL\x85{\xf7}\x06o4\xef\x05rN\xae\xae\xf3\x87\x12\x0f\xbc\xd4)\x94\xae}G\x1c\x87%=\xea+\x13\xea\xe7\x93zD\xf5\xea\xa97/\x04簁\x8d\xc2\xef\xeb" +
+	"\xef\x80F\x90g\x8c\xcc\x05\x00\x8d\x80@\xf8߱\t`\x1a\xb0$\x1f\xcc\xf7D\xdd\xefu\xc7\xe4\xebţ]\x0f]+WX\xe5\xb8}Ǚ\x8f\xb4=\x8e?\x1fE\xed<}I\xa5\xfb\xd2\xca\xf5\uf72ay\xfd" +
+	"\xd8P\xa5\xbc\xfe\xd3*\xfb\xfd\x9c\xe3\xe4\xeb\x89\xdb\xd5\xd2\xebr\x86/\xab\x16\xd82\x9b\x19\xf1\xfdR`\xa7J_\x84\x1d\x7f\x1dL:\x1f\xa1\x82\xbdfU\xf1[\xbb\xce{\xe0\rvn\xf8\x10\xab\xfe}a\xf4\v9" +
+	"\x1d\xaf\xfa{K\xa1\xfc\xa5g /\x89\x9c'\xc4\xdb\xcb2\xe6}\x95\x9a\xf7\x1a\x02\xfe\x1ag/\t\xfc.h\xb2F\xe1<\xbf~\xc5\xf3\xde\xf5\xc6=\xbey. *\xdc3o6\xe2`\x93\xc2]\xa7p\xae\xb4\n" +
+	"E\x93\xb4$ط%\xe1;\x91\x85\x85\xb9\xc37p\x85\x05V\x7f\xd6c\xc4\xfb\x10\xff\xbb\x93~\xd7굳\xb67P=\x0e\x92\xe2\xc4S\xeb\xd9\x0e\x10\xfa\xbe\xd6|\xaf\xbdv\xff\x99\xae}$\xbc\xc2\x1dƊ\x99" +
+	"\xe7i\x02\x008\xdd\xc8\xf3\x1aq\xaa\xc1\x87R\x00\xc0T\x83גּ\xec\xe1\xdb\x10g\x83t\x8btWfJ\xbfg\xecB\xda\xc9\xe5dZ\xd5:\xf3\xbb\xb0\xff\r\x00\xa0T\xc8\xe9"
+
+func init() {
+	schemas.RegisterCompressed(schema_b312981b2552a250,
+		0x836a53ce789d4cd4,
+		0x8523ddc40b86b8b0,
+		0x91b79f1f808db032,
+		0x95bc14545813fbc1,
+		0x9a0e61223d96743b,
+		0x9c6a046bfbc1ac5a,
+		0x9e19b28d3db3573a,
+		0xad1a6c0d7dd07497,
+		0xb28c96e23f4cbd58,
+		0xb312981b2552a250,
+		0xbbc29655fa89086e,
+		0xd37007fde1f0027d,
+		0xd37d2eb2c2f80e63,
+		0xd4c9b56290554016,
+		0xd562b4df655bdd4d,
+		0xd625b7063acf691a,
+		0xd800b1d6cd6f1ca0,
+		0xdae8b0f61aab5f99,
+		0xe94ccf8031176ec4,
+		0xf316944415569081,
+		0xf964368b0fbd3711,
+		0xfbe1980490e001af)
+}
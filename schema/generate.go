@@ -0,0 +1,7 @@
+// Package schema provides the types that the Cap'n Proto compiler uses
+// to describe schemas, as defined in schema.capnp.  capnpc-go reads its
+// input with these types, so it must be rebuilt after this package is
+// regenerated.
+package schema
+
+//go:generate bash -c "capnp compile -o- schema.capnp | capnpc-go -promises=false"
@@ -1,4 +1,4 @@
-package main
+package schema
 
 // AUTO GENERATED - DO NOT EDIT
 
@@ -6,6 +6,7 @@ import (
 	math "math"
 	strconv "strconv"
 	capnp "zombiezen.com/go/capnproto"
+	schemas "zombiezen.com/go/capnproto/schemas"
 )
 
 const (
@@ -2624,3 +2625,110 @@ func (s CodeGeneratorRequest_RequestedFile_Import_List) At(i int) CodeGeneratorR
 func (s CodeGeneratorRequest_RequestedFile_Import_List) Set(i int, v CodeGeneratorRequest_RequestedFile_Import) error {
 	return s.List.SetStruct(i, v.Struct)
 }
+
+const schema_a93fc509624c72d9 = "x\xda\xe4\x9c}\x90Tՙ\xc6\x7f\xe7\xce\xf403\xcc\xc0̀|\x0e\x06\x19H\x94Ȁ\x88\x06XYa\x00\r\x14\x18>4jj\xa9\xa5g\xfa\xc2t\xd2\xd3=t\xdfV\x86\xc5\n\xb1bi\xb9\x9bҸ\x12" +
+	"?\x12\xadTV\xabB\x94]ؕM$\x1f*%+Z\x98Ȯ\x94a\x83\xbbj\xc5h\xb9\xcb\n\xbb\x1a\xbf\x9d\xad\x9e>\xe7~\x9c>\xfd1}Gk\xad}\xff\x99\xb9}\xfby\xcf9\xef9\xe7}\xcf\xfb\xdc" +
+	"\xf76\xc0K\x8d\x00\x00\x16\x11ৣq\xe5\x16 B\x1d'\xd2k\xba\x1b\x0e_\xbag\x14\xa5\xe5\x89\b|\x1d8\x1c\x81\x9f\x02\xd1:\xf8R\x89\xefO|\xa0\xe3\xbd5\x0f\xdd\xf8\x87f@\x10q۩c\x14P" +
+	"G\x1d\x00\xf0\xdd:\xb8\x03\xb8\xa7\x0e\xbe\x80'\a\xea`bM\xa1\xde\xfd\x7f3\xf6\xccѩ\x1b\x1em\x03\x84\xaf\x1dAp\x04\xc7\x1a\xe0\x15\xe0\xc5\x06\x02wN4\xc0\xa5\x85j\xe9:4\xf6\xfd\x9f_\xf5ؿ\xe5" +
+	"\xf5\x8a\xa2z\xdfk\x807\x80HcP\xafh\x84\xeb\v\xd5rъ\x0f\xbe\xf4\xfd\xfd?\xbc\xbfM\xdaA\xe9\xcd\xd9A\xe0ɦFx\xa6\x10N\xb4\x11n\x13\x85\x9f\xdf\xfd\xf6\x85\xcb/\xfe\x87\xd5\a\xca\xe9\x9d\xdc" +
+	"\x04\a\v\xe1C\x9f\x9b\xecp\xdb9\x87N\xfff\xe3\x17^/\xa7wmS\u07be\xba\xaco2\xdba\xfa\x9d\xcd;\x1e~\xf0\xd6\xfd\xe5\xf4\x1ek\x82C\x85p\x8e\x15\xd1{\xbbո\xf4\xf9\xc9\x13\xfe\xb3\x9c\xde9" +
+	"\xcd\xf9y\xd3\xe5\x82f\xf8w\xab\xf0\xf3?\x7fk\xf5\xe7\x1eX\xb7\xf7\xde\xfc\xfa\xadq\xd7o-\xa3\x00\x8bZ5o-p/`\xb7\xc0D<\xd9\xd6b\x9e\xb7\xb6\xa7>\xde\xf9\xab5\xfb\xef\x1a\a\xd4\xfa\xdaѿ" +
+	"w\xb8\x15\xda\x04\x1co\r\xae\xb3\xe7Z\xa1\x06\xc1\xc9V\xb0\xf0\x1aH9\x8f|\xe3\xf2HǓ\xe3d\x7f\x95\xdeZ\xcd\x0e϶\x9a\xed\x9b\xfb\xfc\xb5\u008fi\x9d\xf6\xbb\xde\xdf=\xfb\xe13\xe5\xf4\xeem3\xaf\xdf" +
+	"\xfdm\xe6u\xf6\x87\xd17\xdf|\xe8\xf8\xed?7\xeb\xcd\xfb+\x80cm\xf0\xaf\x85p\x8e\xb7\x99\xd7\xc3\xda{\xaf<\xf9\x1f;o\xbd+?o\u009d7K\xdbǋ\xc6\xc1A`ٸ\xa0}\x97\x8c\x83\xbf+T" +
+	"˻/<\xf0\xdc\xfd\xb3\xbf\xb7G\u05eb\xfb\x87S\xe3\xe0\x10\xf0\xb6\xa6\xf7\xcc8s\x7fw\xad\xbd\xf0O^9\xcan}\x9dE4\xbdk\xc7Ï\x80\xab\xc6\a\xf5\xae\x1f\x0fO\x19\xd6\xd9\xe6\xa3\xef\xbd|j{" +
+	"\xe69]o\xbe\xbf\xe3Pr\xed\x04\xb8\x03\x88N\b\xea\xdd4\x01\xe6\xd6\x16\xea\xbd\xeb\x8d͍\xb3\x16\xbdvs\x9bԫ\xda\x11\xdaz\xb8v\x8a\xd9\xef\\;ż\x1e\xf6\xdc\xfc\xcd)۶\x8f\xb9\xbf\x9c\xde\x1b" +
+	"\x8a\xe8\xbda\x8aپ\xff\xf2Ok\xdeܜZ\xfcp9\xbd'\xa6\x98\xd7\xef\x8bE\xf4>\xd6\xf4\xc1;\xf1g\xfe\xf2T9\xbd\x8b\xa6\x9a\xfd䒩f\xbdgV\xfd\xe9\x8f^\xbf\xf4\xeaCf\xbd5\xd4\x02\x00" +
+	"\xf7L5\xfb\xb3\xfb\xa6\x9a\xd7\xefM\xab\xa3o\xcd|\xe7\xd8}Ӥ^Վ\xde\xdf\xe6vXlXOg\xb5\x9b\xfb\xeb\xbc\xf2զ\xb6\xa7\xdf<XNo\xb6\x1d~b\xd0{S\xbby=|\xb1\xcb\xde|\xfa" +
+	"\xaa\x05\xb7\xe7\xd7/E\xf7۞v\xb8\x17\xd8\xdf\x1e<?\x1c.\xa2\xf7\xe9o\xf4\xec\xb9i\xc1\v\x0f\xe5\xfd\x8e\xe5\xb6#4\xbf\xfev;<\x03\x88i\xc1}\xf1Q\xbbپ\x1f\xbev\xff\xf7&<\xdbs$\xaf" +
+	"W\x14\xe8U\xf2഼?ۯ\xe9\xdd;\xcdl\xdf\xef7\xed{\xe1\x9fOv\xfc\xbaY\xf6\xb7\xd8>n8;o\x87ֳ\x83z\x9b\xcf6\xef\xe3\xae쬇\x9aw\x1f9S\xceO\xee\x9e\x01\x87\x80\xfbf" +
+	"\x04\xf5\xde3\xc3l\x87\xcfO\xdep\xf0\x86\xdbv\x1fk\x06,\u07fc\x15\xc4ݎ\xbc}\x97t\x04\xf5.ꀧ\n\xd5\xf2\xeb\x17\x1a\xcf\xf9\xe3e\x87\x1f\xd3׃\xde߽\x1d\xd0&\xe0\x97\x1d\xc1x\xfct\x87" +
+	"پ\xdd|\xabsߘ7~3\x03\x10\b\xb7\x1d]滋p\xa3\x80M3\x83z\xed\x99f;\xbczuG\xdb_/[\xf7\xb7\xf3\xa4^Վ\xbe~[g\xc1A\x01\xe7\xcd\n\xdaa\xd6,s\x7f3=" +
+	"\xbdv_\xb4\xb3'ڟ\xec\a8_;\xaf\xae\af\xfb\xce\x15k\x81\xf9\xbe\xb8\xb8\n\xd8\xe0\x8bg\xb9\xeb\xaf\xf9\xe2P\xeez\xb1/~\xac\x94\xfa\xd4z^&\xf5\xa9u\xb9D^\xab\xf5\xb4H\xeaS\xeb w" +
+	"\xdd훿\xdc\xf5O\x80+R1\x1bಸ\x9d\x88\x01+\x93\xd9>;\x1dM:\x00\xc0\xc6l\xbf\x9d\xeeID3\x19\x00Xk;\xbd\xa9\x18\\9\xd0o\x03t\xa5\xa3\xc9\x18\xf0\xd5h\"k\x03˒ɔ" +
+	"\x13u\xe2\xa9$\x90ӗ\xb0\xfb줳1\xbe\xc3\x06`y*f_n'\xedt\xd4I\xa57\xd8۲v\xc6\x01\xa8\a\x04\x16/}q\xa0\xa3\xe5\x9b{~\xd5\x02X\b\xda\x01\x10\x1cX1\xe6\xf3\xe2\x1f\xe7" +
+	"\xbd<I~\xbe\x00\x00A\x13A\x11\xc0bw~p\xc5\xf4\xbd6\x01;R}\xddq{\x87\x9d\xec\xecI\xf5\xcdݚ\x9a;4\xa3锓\x9a\xab\xab(\x98\xf7\xc5\xcaxn\xff\x85\x9b\x175\xc8\xf9U\xf9L\x83" +
+	"\x9c\x8fu\xd1t\xb4\xcfv\xec4\x00p\x85\x9dq옧h!\xf8\xa2\v\x80\xc0/\x03\x02\xa6x\x978\x02j\x10\xec\x12`!|\xdf\x16\xc1x-\xa0ۻd\xa7\xc4\xdd\"q\x16`I\x9c\xe5\x8f\v\"xN" +
+	"\xbdE\xe2\ue538\x1a\x1f\xae\x06O\xbe+\xa0˻\xe4\xaf$\xeen\x89\xab\x03\x04\x00\"0\xda\xddZ?\uf538}\x127\xca\xd7^\xc4\xefo4\xdc\xc3\x12wD\xe2\xea\x19\x1c\x04@\xb6\xad䰀\xd9\xde%" +
+	"\x8fK\xdcQ\x89k\xe0\xe3Aܾzy\xe4\xd3\x02\x16\x03\xb9\xbf\x9f+t\x0f4\xf2Q\x00\xa7\xf2\xc4\x13Z{\xba\x8c\xe6\xc3\x00N\xe5\x81O\b\xd8P\x02\xd7\xc4\a\x01\x9c\xca\xf3\xf6\x8a\xbc_(&ͼ\x1f\xc0" +
+	"\xa9<n\xb7ȯ\xd7bR\vD\x00\x10LǓoi\xb8]Ҟ?\x90\xf6\x8c\x00\xd3\x05\x80\xe0\x1c\x7f\x1c\xd3\xc6w\xb7\xc4= q\xf1\x18\x004PZ\x8aݏ\xc53\xfd\x89\xe8\xc0\x15\xd1>\xdb\xe8\x0f\n" +
+	"\xedYVϺ\xb4\xbd%\xbe}\x8d\x9d\xdc\xea\xf4R_F_\xb1\xfb\x99\x9eT\xbf\xbd*V\xf5\xb8\x92\xae\xff\xc8\x000\xa6\x84\x8e\x1a\x04-\x00>\xbf\xa4\x7f\xa7\x18>\xea\xfa\xf5a\xb6#\xe3Q\xa5\xedl\x89'l" +
+	"BH\xc6Ig{\x1c\xa8\x05\x04\x16g\xbe3wb\xdb\xe6G\x0f\xd5\x02\x16\x82\xf1%\xe2F\xb7\x8b\xbf<\x9d\xca\xf6\xfboc'\xb3}\x00\xf1\xa4c\xa7\xb7D{l\x00\xa0'\x95\xcc8\x01\xfb\x00@\xbf\xf2\xf3\x19" +
+	"\x86e/\x15?*\xb5W<3\x14M\xe3=\xc6hQ(\xa2\xe8:\xd4\xe2Z\xa7\x1e\xa9@ \xa8-\x13\x9f\x9a\t\xfa\xb7z9\xbe\xf1\x80\x85 )7`\xb5\xfb\xaf\xb0\x9fz\x04\x05\x81\xa0\xbeL?\xcf\xd3\xfa9" +
+	"S\xf6s\x1e\xa5\xe3\xe8\x1c\x82\xf1\xf7\\\x89[0B\xe3\v\xeb\xe7\n\xed\xa3v\x04\x00\xb4\xfb\xec2\n\x80\xe0I\xf8\x00\xe0\xcd8<\"\xc7\xf7\xb8\xcf.M\x00\xd2\xc6J~\t|ݻ\xe4\x17\x12w\x04\xef|\xd1" +
+	"\f\x80\b\xf4\xfd0\xf0\xf7\xde%G$\xeey\xbc\xf3\xc5K\x00\b\x1a\xf1\xe4\x18\xc1\xf3\xc5Q\x89\xfb\xad\xc4\xd5\x02c\x01\x10\xf8\xe8v\x8e\x03wx\x97\xfcV\xe2~\x8f\x17\x9f\xea\r>\xe2e\xc0O\x0e\xfe^\xe2N" +
+	"\xe3\x9dgj\x00\x10r\x9c\x00p\nX\xec]\xf2\xba\xc4\xd5ʸ\x16\x8b:ѫS\xe9\xd8\xf2T6\xe9\x10\xcc=LR\xec~\x7fj\xc83)5\xd5\xebI\xdb[\xectڎ\xad\x89g\x9c\x95ɞT,\x9e" +
+	"\xdc\xea\xda\xd2\xcb't\xdc\xd8\xe2\xfeiȗV\xeb\x97b\xf1LO:\xde\x17OF\x93\x8e;\xb8\x10\xe3\xf3\xeb\xfbʖ-\x19\xdb)\x1d\x9f\xfd~\xcc\x1c\xaf\xecD,S\xb9\x7f/\xc63\x8f\xa9x?\xe7#\x91" +
+	"\xdf\x0f\xabu7F\xf3\xc3_\xd3\xfcM\r\x82s\x01\v\x81\xad\xf2\xbca\xc6'\x95\xbfV\xdf\xff`\x04\xad7\x8cc\xac槻\f~z\xa9\xcf\x1f\xe5\xd1\"p\x0e_\xa2\xf9\xa3K$\xee\x1a\x89\xeb\x1b\xcac3" +
+	"\x15\x8f[\xe5\xe5\x15\x8f\xdb͛\xed\xccp\xec\xab\xf8\x80\xea\xed\x9b?\x91\x98\xec\xda2\xcc\xf8\xa7\xecڪſ\xf9%\xe2\x9f#y\x01Ֆ\xe2/\xf4~\xb7\x14\x19\xcfu\x92GP\xf7\x15\xbfQ)\xbe\xd0\x1e\xc1" +
+	"\x93\xd9\x02\x83]\xda\xf0d\x93\x96\x9f]#\xf3\x90\x98/\xbf\xee\a@\xf8X?\x88j\xf9\xe7f\x89K\xf8\xf2\xebm\x00\xee\x19\x14\x00\xe2\"\xb8N{%\xce\xf1\xe5\xd7i\x00\x04g\xe1\xc96\xad\xbd~\x89\xdb)\xbc" +
+	"\xf8\x97\x01@0\x81 \x7fp\xabwY\xc0\x03D\x00\a\x00\xc1D\x8d\aH{\x97|[\xe2n\xf7\xe5\xf3Y\x00\x04\x93\xf0\xe4;\xda\xf8t\x1e`\x14p\x1d\x00\x82\xc9x\xb2[\xc3)\x1e\xe0\x87n>\x0f\xd7\x03 " +
+	"\x02\xe7\xb1\xfb4\xdc\x0f$\xee\xc7n>\x0f\xdb\x01\x10Lœ\a5\xbb\xfcX\xe2\x1e\x91\xb8F`\x00\xc0\xe5\xa4\x00`\xbff\x97}\x12\xf7\v\x89\x1b\r\xec\x00@0\rO\x1e\xd5\xfa\xf93\x89{R⚀\xbf" +
+	"\x00@p6\x9e<!\x82\xe7\x97'%\xee912\xfbω\xa6\xb7\xdaN\xe62\x95\x86U\x1b\xb7\xa5\x9e\xe52=\n\xabg\xa5L\xbfFB\x8f\x9f\xde\f\xabo\xa3:\\\x87ԣ\xe8װz\xaeJJ?\x17" +
+	"R\x8f\x9b\x00\x87ԳJK\x97\xc3\xeaS\xf4sX=C\xc9\xed\b\xf4G\xa7\xbbG&\xffvW\x03\xd4\x02\x02\xe1\xd6+\b`\a\x90L\xad\xf0\x9dd\x83y\x9d\xa9\xa5\x03Z\xbc߇\xf4Se\xf2\xddG\t\xf2" +
+	"r?\x93\xb8'}y\x9d\x80\x02\xde\xf8\t\x82\xf1\xe9q\x89;\xe9\xcb\xeb\x14\xce\xfb\x1fNh\xf9\xd9I\x89{\xdd\xcd\xeb\x06\x03|\xa5\xaa\xb7x\x95ҼjD\xe3qU=ų\x94\xe6G\xeb4k\xaaz\x89\x03" +
+	"ڹT\x97\xb0<@O*f\x7f%\x1dsٗj\xf3\x9dO\x8b\xb7\xf3\xe7U\xeaYPe\xfd\x1e\x1cT\xff\xfb%\x93H9\x00[\xa5#J\xa5c\xf1d4a\xd8'\x9d\xfaV\b\xac\xe4\xaa\xdb/lg\xa8" +
+	"K-\xbe}fz~\x11\xd5\xf2\xfd?\x93v\xed\xf5\xed3\xd3\xf3\v[[\xbf\x9b%.\xe1\xdbg5P\xf0\xfc\"\xae\xe59\xbd\x12\xe7\xf8\xf6\xd9.(\xe0O\xb6i|\x86#q\xbb$.%s\xe3j\xf3\xe2\xb0" +
+	"琘\xbd%\x9aMxK\xa9\xda|\xa07\x1a[\xb9\xbd?\x11\xef\x89;+\xf2*G\xdaOwn\xf5\x93\xc5\xfe\xbc\\\xcds\xa0\x0e@[\x1f:?\x9a\xb3۪\xd8\b\xf1\x7f\xf9\xfe\xa9\xad\xe3k\xaf\x16|\xcf" +
+	"\xc3굼pu\x89\xfcN\xf0\xf1`\x1d\x14\xf0x\x17h\xb8y\x12w\x89\xc4\xc5\xfb\xf2\x93@H\xb1\xb7\a\xf5T\xeb\x17\x03v\xd2N\x85\x02A\x93k'\xf3\x8aX\xa9\xedץr\xbck\xca\xc4\xd3UZ<\xfd" +
+	"\xb2\xc4]Y&\x9e\xae\xd7\xe2\xe9:\xcd?\xfc\x7f\x8b7\x81\xf9ӊ\x14*\xe5\xff\xa7\x94\xe1?L\xf3W\x8e\xff\b\xcb\xdfw\xcbz\ne\x17U\xefQ\xa9\xbf\v\xd8\xc5=-{#\x99^\xc6.Ǵu\xad\xf3" +
+	"\xea\xc5\xecr\\[\xd7\xcfK\xdcK\x86\xf3\x9e\x85'/\x027\x968\xefE\x8a\xd4\x17\xbcJ0_W\xbc\xfa[x\xbc\x82)>\x9f\xd1\xf6\xd1i\x89k\xf2\xf1&\xa6\xf8ܠ=\u05ee\x97y\xf7xQ\xc8\xff\xe7" +
+	"\xfeWҪ\xf14-\x12\xd7.\xbc\xfd^k\x88\x13\x93E0>\xb7K\xdcB\xf1\x7fk\xbf\x0f=\xe7\xccg\xc1C%Aծ\xfb\xb4\x9d\xc9&\x1c\x9f\"\xc2\xed\xa3O\xcb\x0f\r\x8d_\x15A\x85ط\xf9\xf1{" +
+	"\x8a\xaaգ\xe2\xec\xbaO\xe9\xf9s\xc0\xdf\xf8'\x0e\x102\x1e\xea\xe7\r\xbfD\xac\xa0\xbf\xc1\x92\xfb\xd1\xf2\xce\x1b&\x7fӠ\xe1j%\xae\xc5R\xfb\xea#\x17g\xe1I\xb3\x86\xab\x97\xb8\xf1\x96\xf2S\x1f\xba\xb8\x1a" +
+	"\xff>\xb6\x82~\xbfI\xe2&Y\xcao|\xe0\xe2\xfc\xfe\xe6,\r\xd7\"q\xed\x96\xf2o\xef\x0fb\xf07\x935\xdcx\x89\x9bn)\x7f\U000dec5ei\x9a\x86\x9b$q3-\xe5\x17\xdfuq\xfe=}\x8e\x15<" +
+	"\x97\xb6Kܹ\x96\xe2[\xdf1\x9e\x17gi\xb8\xe9\x12w\xbe\xa5\xf8\xd6?\xba8\xff^=O\xc3͔\xb8y\x96\xe2[\xdf\x1eĐ\xb7̱\x82y\xf7\xb9\x12\xb7\xc0R|\xeb[.\xce\xff\xdc\xf7\x02\rw\xbe" +
+	"\xc4-\xb4\x14\xdf\xfa?.\xce\xef3/\xd2\xd6\xcb<\x89\xbbD\xe2\x9a\xf9o\x17\u05cc'\x8b4\xdc\x02\x89[*qc8\x13\xe0#T=\xff\x12\xab4\x8f1\x96\xd3\x01\x9c\xaaןU\x06\xd7\u009b\x01\x9c\xaa\xc7" +
+	"o\x96\xf3\x90\xfbk\xaack\xe5\xbf\x028Uo?\xcd\n\xc6{]\xda8\x15\xc0\xa9z\xf4\x06\xabt]\xd9u\xa9x̻\x1a\xbet\xa7R\x89\x10\xf0\\}\xcf\u0090\xf8\v.\x0e\x87\xbfp~8\xfc\xc5\v\xaa" +
+	"\x87\x93\ri\x80lH\x03dC\x1a \x1b\xd2\x00[\x12\xa9h\x98\x0e\f\xe1Ct\xc0\xb1\xb7\x87ʑs\xf5$!\xe0$\xe2\x19\a_\xbd\xdb'UO\xa7\xd7\xd1E\x93\x03\xeb\xf2%,\x80\xe9\\љ\uf669" +
+	"\xfeL\xaf{\xf0\x9f\xb7U݃\xaa\x1b\xb7\xf3\xf5\xe8W\xaa\x93J\xb5\xfcTa\xff\xf2\x16\xab7\xf0\x83z=C \xee\x95\xc8;\xcf\x1aF\xde\x19\x967\x1aѼs\xc8\x1e\xc1\xba3\x93]Z\xaa\xb4˄ϴ" +
+	"]\x8a\u05ff\bC\xbd\xc5p\xec2\xf13m\x97\xa0\ah2\xe4\rm\x04\xf9\xb7\xb4w\xc9\n9\xbeu\x04\xf3\x06\x01\xe0{\xefn-\xa5\xcf-\x96V\xb7\xafޫ[\x84\xf9}]%\xd9\xe4P\xddM:\x1aO" +
+	"\xdaa\x0e0\xfdZխ\xca\xe7\xf2T\x8e\x9bՕ\xb4_\xa7OI\xbd\x81\x8f\x1e\xa7\xad\xaf\xae2\xeb+\x02\x05u+s\x80\x1d\xde%\xe7K\xdcB\x89\v[\xd7\xee\x0eaU2fo\x1f!\x9eW\xb7S1\xe3" +
+	"j<\xbe\x1a\xff$-\xce\xec(\x11g>\x91\xfe{\xf4\x80\xfb\xbe\x91z\x8f\xb2\x01\x98\xef{\xff1\x02t\x01\x1bs\xf3\x90\xfb?\x9e\x1c\xaa䬤~\xdb\xf4|b\xa6\x7f^\x87Q\xef\xa8\xfa\xa7\x7fgL\xc5\xe3" +
+	"\xedTCp\xa5R~\xbe\xab$??8X\x8c\x9f\x9fm\xf0+\x9b%\xce\xf2\xf1\x11\x16\x9el\xd2ڻF\xe2b#\xb4\x1f\xba\xe3\xc9X)\xbb\xe9vW\xeb\xa0R\xbbǓ\xbdv:\xc4\xe3\x19ü\xa9%\x17" +
+	"\x98\xb7\xfa2|Py\x7fd\xe6\x83\xe6h\xf3\xa6ǻl\xb2;\x95MV\xef\x99\xc3>\xcf\f\xd8\xc7\xff\x80|\x04y\xb2\x16\x83]*\xe1\xc9L\xef\xfdU\u0093\xf9\xeb7\x86Ó\x89*y2\x85\x1b.Of" +
+	"Uɓ\xa9\xf6\x86˓)\xdcpy2\x85\x1b.O&\xaa\xe4\xc9\xc4g\x88'\xd3\xf3<\x9d'[(q+$.Ǔ\x99\xf2\xafe\x1a\xee\x12\x89\xfb\xb2\xc4)\x9eL\xcfOV\xcayX)y\xb2^\x89s" +
+	"$N\xf1d\xfa\xf9}\x9bƓ\xf5K\xdcN\x89S<\x99~\xbe\x1d\xd0x\xb2\xed\x12\xf7m\x89\x1b)\x9e\xac\xda:\bE\x13Y\xa5\xe1E\xef+\x9a\xa8\xa64\xbc\xe8}E\x13Ֆ\x86\x17\xbd\xafh\xa2Hi8" +
+	"\x91\x12<\xd3B\xa0N\xfb\\\x97\xba\x12\xf8\x9c\x01\xaa=\x17*\x9e\xac\xda\xfa\x1cœU{\x1eQ<Yci8\x8d\xa5\xf0\x17/`\xb4\xf6\xb9.\xa3\xcb\xf0dU\xbf\xc7+y\xb2\xe6\xd2\xf0\xa2\xf7\x15O\xd6V" +
+	"\x1a^\xf4\xbe\xe2ת\xce\vF\x84\x97\xf3\x15\x9a\x96\xe9o\xb9\xfb:\x9fW\xad\xe8<`\xb5\xfd\t\x9c\xb7\xb4*\xddJ\xf3\x87)%\xf2\aˇ\xd3\xf3\x87\xf9\x86\xfc\xc1\xe3%\xccu\x10k5\x9c^\x17\x14\xb6\xae" +
+	"$\xec{5#\xca\xf7\xe8?\n\xa2է\xf8-s\xadf\x17\x01\x00\xac\af\xe0\x89\xe5\xb3\xfflC\xfcX\xa6է\xd5\x02\x00K4>(\x02\x00,\xd2ޟ\xab\x03\x00.\xd2\xf2\x93Q\x00\xc0\x1c\xad\x8e\xc5\xee" +
+	"\xebw\x06\x86\xf26\ar\x7f\a\x1c\x1b\xc0\xb9>\xd55\xe0\xd8\x19\xd7\x0f\xa6\xb2i\xff\av|k\xaf\xe3\xfb@\xbe\xd7I<\x99\x88'\xed婾\xfeT&\xee\xd8A{\x16\xfbQ\x15_\xfd\xb8\xf7\xbb7\x90\x06" +
+	"\xe4\xb7\xec\x98|ӣ\x92z\xa9\xf9\x86\xbcl\xa9a]\xfb\x91K4\x9eD\x7f\x0f/)\x7f\xb9\xa0ҼV\xfd\xbe\x8e\xfe\x9db\xf8\xb4\x7f\x9c\xc3\xe0-\x94\xbd*m\xa7\xec|t\x06-\xae\xe6E\xfd>\x91\x00\x16" +
+	"\x03\xab\xfa\xfaSi\x87\xd0\xfe\xa9T\xfd\xe1\xea\x12~\xa6T\xfd\xa1\x7fݯ\xd1\xea\x9a\xc3\xfa\xa7\xdc\xef>\xa8\x9a\xa60q=>d\xbf\xca\xdf\xebT\xf6\xff\x84\xe6\xb9S\xcd'\x9fB]b)\xfe#\xec\xfc\x84\xad" +
+	"7\xfb\xdf\x01\x00\xad\xa8w\xc8"
+
+func init() {
+	schemas.RegisterCompressed(schema_a93fc509624c72d9,
+		0x87e739250a60ea97,
+		0x903455f06065422b,
+		0x9500cce23b334d80,
+		0x978a7cebdc549a4d,
+		0x97b14cbe7cfec712,
+		0x9aad50a41f4af45f,
+		0x9dd1f724f4614a85,
+		0x9e0e78711a7f87a9,
+		0x9ea0b19b37fb4435,
+		0xa93fc509624c72d9,
+		0xa9962a9ed0a4d7f8,
+		0xabd73485a9636bc9,
+		0xac3a6f60ef4cc6d3,
+		0xae504193122357e5,
+		0xb18aa5ac7a0d9420,
+		0xb54ab3364333f598,
+		0xb9521bccf10fa3b1,
+		0xbaefc9120c56e274,
+		0xbb90d5c287870be6,
+		0xbfc546f6210ad7ce,
+		0xc2573fe8a23e49f1,
+		0xc42305476bb4746f,
+		0xc863cd16969ee7fc,
+		0xcafccddb68db1d11,
+		0xce23dcd2d7b00c9b,
+		0xcfea0eb02e810062,
+		0xd07378ede1f9cc60,
+		0xd1958f7dba521926,
+		0xdebf55bbfa0fc242,
+		0xe682ab4cf923a417,
+		0xe82753cff0c2218f,
+		0xec1619d4400a0290,
+		0xed8bca69f7fb0cbf,
+		0xf1c8950dab257542)
+}
@@ -0,0 +1,182 @@
+// Package schemas provides a registry of the schema nodes parsed by the
+// Cap'n Proto compiler.  Packages that need to reflect on Cap'n Proto
+// types at run time, like pogs, look up the nodes they need by ID.
+//
+// Code generated by capnpc-go embeds the nodes of its schema file and
+// registers them in the default registry when the package is
+// initialized, so importing a generated package is enough to make its
+// types available.
+package schemas
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// A Schema is a collection of schema nodes parsed by the Cap'n Proto
+// compiler.
+type Schema struct {
+	// String is a Cap'n Proto message in the stream format whose root
+	// is a CodeGeneratorRequest holding the nodes.
+	String string
+
+	// Nodes is the list of IDs of the nodes in String.
+	Nodes []uint64
+
+	// Compressed reports whether String is compressed with zlib.
+	Compressed bool
+}
+
+// A Registry is a mapping of node IDs to the schemas that contain them.
+// The zero value is an empty registry.  It is safe to call a Registry's
+// methods from multiple goroutines.
+type Registry struct {
+	mu sync.Mutex
+	m  map[uint64]*record
+}
+
+// A record is a registered schema.  The decompressed message is kept
+// once it has been found.
+type record struct {
+	schema *Schema
+	data   []byte
+}
+
+// Register indexes the nodes in s.  It is an error to register a node
+// that is already in the registry from a different schema.
+func (reg *Registry) Register(s *Schema) error {
+	if len(s.Nodes) == 0 {
+		return errNoNodes
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, id := range s.Nodes {
+		if old := reg.m[id]; old != nil && old.schema.String != s.String {
+			return &dupeError{id}
+		}
+	}
+	if reg.m == nil {
+		reg.m = make(map[uint64]*record)
+	}
+	r := &record{schema: s}
+	for _, id := range s.Nodes {
+		if reg.m[id] == nil {
+			reg.m[id] = r
+		}
+	}
+	return nil
+}
+
+// Find returns the message holding the node with the given ID.  The
+// returned slice is a copy that the caller may modify.
+func (reg *Registry) Find(id uint64) ([]byte, error) {
+	data, err := reg.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// find returns the registry's cached copy of the message holding the
+// node with the given ID, decompressing it on first use.
+func (reg *Registry) find(id uint64) ([]byte, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	r := reg.m[id]
+	if r == nil {
+		return nil, &notFoundError{id}
+	}
+	if r.data != nil {
+		return r.data, nil
+	}
+	if !r.schema.Compressed {
+		r.data = []byte(r.schema.String)
+		return r.data, nil
+	}
+	zr, err := zlib.NewReader(bytes.NewReader([]byte(r.schema.String)))
+	if err != nil {
+		return nil, &decompressError{id, err}
+	}
+	data, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, &decompressError{id, err}
+	}
+	r.data = data
+	return data, nil
+}
+
+var defaultRegistry Registry
+
+// Register registers the nodes in data in the default registry.  It
+// panics if a node is already registered from different data.
+func Register(data string, ids ...uint64) {
+	err := defaultRegistry.Register(&Schema{
+		String: data,
+		Nodes:  ids,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegisterCompressed registers the nodes in the zlib-compressed data in
+// the default registry.  It panics if a node is already registered from
+// different data.
+func RegisterCompressed(data string, ids ...uint64) {
+	err := defaultRegistry.Register(&Schema{
+		String:     data,
+		Nodes:      ids,
+		Compressed: true,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Find returns a copy of the message holding the node with the given ID
+// from the default registry or nil if the node hasn't been registered.
+func Find(id uint64) []byte {
+	data, err := defaultRegistry.Find(id)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// IsNotFound reports whether err is returned when a node isn't in a
+// registry.
+func IsNotFound(err error) bool {
+	_, ok := err.(*notFoundError)
+	return ok
+}
+
+type notFoundError struct {
+	id uint64
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("schemas: no node with ID %#x", e.id)
+}
+
+type dupeError struct {
+	id uint64
+}
+
+func (e *dupeError) Error() string {
+	return fmt.Sprintf("schemas: node %#x registered twice", e.id)
+}
+
+type decompressError struct {
+	id  uint64
+	err error
+}
+
+func (e *decompressError) Error() string {
+	return fmt.Sprintf("schemas: decompressing schema for node %#x: %v", e.id, e.err)
+}
+
+var errNoNodes = errors.New("schemas: schema has no nodes")
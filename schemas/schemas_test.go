@@ -0,0 +1,116 @@
+package schemas
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var reg Registry
+	if _, err := reg.Find(1); !IsNotFound(err) {
+		t.Errorf("empty Registry.Find(1) error = %v; want not found", err)
+	}
+	if err := reg.Register(&Schema{String: "a", Nodes: []uint64{1, 2}}); err != nil {
+		t.Fatal("Register:", err)
+	}
+	if err := reg.Register(&Schema{String: "b", Nodes: []uint64{3}}); err != nil {
+		t.Fatal("Register:", err)
+	}
+	tests := []struct {
+		id   uint64
+		data string
+	}{
+		{1, "a"},
+		{2, "a"},
+		{3, "b"},
+	}
+	for _, test := range tests {
+		data, err := reg.Find(test.id)
+		if err != nil || string(data) != test.data {
+			t.Errorf("Registry.Find(%d) = %q, %v; want %q, <nil>", test.id, data, err, test.data)
+		}
+	}
+	if _, err := reg.Find(4); !IsNotFound(err) {
+		t.Errorf("Registry.Find(4) error = %v; want not found", err)
+	}
+}
+
+func TestRegistryDuplicate(t *testing.T) {
+	var reg Registry
+	if err := reg.Register(&Schema{String: "a", Nodes: []uint64{1}}); err != nil {
+		t.Fatal("Register:", err)
+	}
+	if err := reg.Register(&Schema{String: "a", Nodes: []uint64{1}}); err != nil {
+		t.Errorf("Register same schema twice: %v", err)
+	}
+	if err := reg.Register(&Schema{String: "b", Nodes: []uint64{2, 1}}); err == nil {
+		t.Error("Register different schema with same node succeeded; want error")
+	}
+	if _, err := reg.Find(2); !IsNotFound(err) {
+		t.Errorf("after failed Register, Registry.Find(2) error = %v; want not found", err)
+	}
+}
+
+func TestRegistryNoNodes(t *testing.T) {
+	var reg Registry
+	if err := reg.Register(&Schema{String: "a"}); err != errNoNodes {
+		t.Errorf("Register with no nodes error = %v; want %v", err, errNoNodes)
+	}
+}
+
+func TestRegistryCompressed(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte("hello"))
+	zw.Close()
+	var reg Registry
+	if err := reg.Register(&Schema{String: buf.String(), Nodes: []uint64{1}, Compressed: true}); err != nil {
+		t.Fatal("Register:", err)
+	}
+	if err := reg.Register(&Schema{String: "hello", Nodes: []uint64{2}, Compressed: true}); err != nil {
+		t.Fatal("Register:", err)
+	}
+	for i := 0; i < 2; i++ {
+		data, err := reg.Find(1)
+		if err != nil || string(data) != "hello" {
+			t.Errorf("Registry.Find(1) = %q, %v; want \"hello\", <nil>", data, err)
+		}
+	}
+	if _, err := reg.Find(2); err == nil || IsNotFound(err) {
+		t.Errorf("Registry.Find(2) of corrupt data error = %v; want decompress error", err)
+	}
+}
+
+func TestRegistryFindCopies(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte("hello"))
+	zw.Close()
+	var reg Registry
+	if err := reg.Register(&Schema{String: "a", Nodes: []uint64{1}}); err != nil {
+		t.Fatal("Register:", err)
+	}
+	if err := reg.Register(&Schema{String: buf.String(), Nodes: []uint64{2}, Compressed: true}); err != nil {
+		t.Fatal("Register:", err)
+	}
+	tests := []struct {
+		id   uint64
+		data string
+	}{
+		{1, "a"},
+		{2, "hello"},
+	}
+	for _, test := range tests {
+		data, err := reg.Find(test.id)
+		if err != nil {
+			t.Errorf("Registry.Find(%d): %v", test.id, err)
+			continue
+		}
+		data[0] = 'x'
+		data, err = reg.Find(test.id)
+		if err != nil || string(data) != test.data {
+			t.Errorf("after modifying result, Registry.Find(%d) = %q, %v; want %q, <nil>", test.id, data, err, test.data)
+		}
+	}
+}
@@ -1,3 +1,4 @@
+//go:build !nocapnpstrings
 // +build !nocapnpstrings
 
 package capnp
@@ -123,3 +124,11 @@ func (ssa *singleSegmentArena) String() string {
 func (msa *multiSegmentArena) String() string {
 	return fmt.Sprintf("multi-segment arena [%d segments]", len(*msa))
 }
+
+func (pa *PooledArena) String() string {
+	return fmt.Sprintf("pooled arena [%d segments]", len(pa.segs))
+}
+
+func (oa *optionsArena) String() string {
+	return fmt.Sprintf("arena [%d segments, %d bytes]", len(oa.segs), oa.total)
+}
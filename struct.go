@@ -2,10 +2,11 @@ package capnp
 
 // Struct is a pointer to a struct.
 type Struct struct {
-	seg   *Segment
-	off   Address
-	size  ObjectSize
-	flags structFlags
+	seg        *Segment
+	off        Address
+	size       ObjectSize
+	flags      structFlags
+	depthLimit uint
 }
 
 // NewStruct creates a new struct, preferring placement in s.
@@ -19,9 +20,10 @@ func NewStruct(s *Segment, sz ObjectSize) (Struct, error) {
 		return Struct{}, err
 	}
 	return Struct{
-		seg:  seg,
-		off:  addr,
-		size: sz,
+		seg:        seg,
+		off:        addr,
+		size:       sz,
+		depthLimit: maxDepth,
 	}, nil
 }
 
@@ -84,6 +86,12 @@ func (p Struct) Address() Address {
 	return p.off
 }
 
+// Size returns the size of the struct's data and pointer sections.
+// Reads past the end of either section return default values.
+func (p Struct) Size() ObjectSize {
+	return p.size
+}
+
 // HasData reports whether the struct has a non-zero size.
 func (p Struct) HasData() bool {
 	return !p.size.isZero()
@@ -104,17 +112,37 @@ func (p Struct) Pointer(i uint16) (Pointer, error) {
 	if p.seg == nil || i >= p.size.PointerCount {
 		return nil, nil
 	}
-	return p.seg.readPtr(p.pointerAddress(i))
+	return p.seg.readPtr(p.pointerAddress(i), p.depthLimit)
 }
 
-// SetPointer sets the i'th pointer in the struct to src.
+// SetPointer sets the i'th pointer in the struct to src.  It returns an
+// error if i is past the end of the pointer section.
 func (p Struct) SetPointer(i uint16, src Pointer) error {
 	if p.seg == nil || i >= p.size.PointerCount {
-		panic(errOutOfBounds)
+		return errOutOfBounds
 	}
 	return p.seg.writePtr(copyContext{}, p.pointerAddress(i), src)
 }
 
+// Disown removes the i'th pointer from the struct, setting it to null,
+// and returns the object it referred to as an orphan.  If i is past the
+// end of the pointer section, Disown returns a null orphan.
+func (p Struct) Disown(i uint16) (Orphan, error) {
+	if p.seg == nil || i >= p.size.PointerCount {
+		return Orphan{}, nil
+	}
+	return disown(p.seg, p.pointerAddress(i), p.depthLimit)
+}
+
+// Adopt sets the i'th pointer in the struct to o's object without
+// copying it.  o must be in the same message as the struct.
+func (p Struct) Adopt(i uint16, o Orphan) error {
+	if p.seg == nil || i >= p.size.PointerCount {
+		return errOutOfBounds
+	}
+	return adopt(p.seg, p.pointerAddress(i), o)
+}
+
 func (p Struct) pointerAddress(i uint16) Address {
 	ptrStart := p.off.addSize(p.size.DataSize)
 	return ptrStart.element(int32(i), wordSize)
@@ -135,11 +163,23 @@ func (p Struct) Bit(n BitOffset) bool {
 }
 
 // SetBit sets the bit that is n bits from the start of the struct to v.
+// It panics if the bit is outside the struct's data section.
 func (p Struct) SetBit(n BitOffset, v bool) {
+	if err := p.SetBitChecked(n, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetBitChecked sets the bit that is n bits from the start of the struct
+// to v, returning an error if the bit is outside the data section.
+func (p Struct) SetBitChecked(n BitOffset, v bool) error {
 	if !p.bitInData(n) {
-		panic(errOutOfBounds)
+		return errOutOfBounds
 	}
 	addr := p.off.addOffset(n.offset())
+	if p.seg.readOnly {
+		return errReadOnly
+	}
 	b := p.seg.readUint8(addr)
 	if v {
 		b |= n.mask()
@@ -147,6 +187,7 @@ func (p Struct) SetBit(n BitOffset, v bool) {
 		b &^= n.mask()
 	}
 	p.seg.writeUint8(addr, b)
+	return nil
 }
 
 func (p Struct) dataAddress(off DataOffset, sz Size) (addr Address, ok bool) {
@@ -193,39 +234,95 @@ func (p Struct) Uint64(off DataOffset) uint64 {
 }
 
 // SetUint8 sets the 8-bit integer that is off bytes from the start of the struct to v.
+// It panics if the integer is outside the struct's data section.
 func (p Struct) SetUint8(off DataOffset, v uint8) {
+	if err := p.SetUint8Checked(off, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetUint8Checked sets the 8-bit integer that is off bytes from the start
+// of the struct to v, returning an error if the integer is outside the
+// data section.
+func (p Struct) SetUint8Checked(off DataOffset, v uint8) error {
 	addr, ok := p.dataAddress(off, 1)
 	if !ok {
-		panic(errOutOfBounds)
+		return errOutOfBounds
+	}
+	if p.seg.readOnly {
+		return errReadOnly
 	}
 	p.seg.writeUint8(addr, v)
+	return nil
 }
 
 // SetUint16 sets the 16-bit integer that is off bytes from the start of the struct to v.
+// It panics if the integer is outside the struct's data section.
 func (p Struct) SetUint16(off DataOffset, v uint16) {
+	if err := p.SetUint16Checked(off, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetUint16Checked sets the 16-bit integer that is off bytes from the start
+// of the struct to v, returning an error if the integer is outside the
+// data section.
+func (p Struct) SetUint16Checked(off DataOffset, v uint16) error {
 	addr, ok := p.dataAddress(off, 2)
 	if !ok {
-		panic(errOutOfBounds)
+		return errOutOfBounds
+	}
+	if p.seg.readOnly {
+		return errReadOnly
 	}
 	p.seg.writeUint16(addr, v)
+	return nil
 }
 
 // SetUint32 sets the 32-bit integer that is off bytes from the start of the struct to v.
+// It panics if the integer is outside the struct's data section.
 func (p Struct) SetUint32(off DataOffset, v uint32) {
+	if err := p.SetUint32Checked(off, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetUint32Checked sets the 32-bit integer that is off bytes from the start
+// of the struct to v, returning an error if the integer is outside the
+// data section.
+func (p Struct) SetUint32Checked(off DataOffset, v uint32) error {
 	addr, ok := p.dataAddress(off, 4)
 	if !ok {
-		panic(errOutOfBounds)
+		return errOutOfBounds
+	}
+	if p.seg.readOnly {
+		return errReadOnly
 	}
 	p.seg.writeUint32(addr, v)
+	return nil
 }
 
 // SetUint64 sets the 64-bit integer that is off bytes from the start of the struct to v.
+// It panics if the integer is outside the struct's data section.
 func (p Struct) SetUint64(off DataOffset, v uint64) {
+	if err := p.SetUint64Checked(off, v); err != nil {
+		panic(err)
+	}
+}
+
+// SetUint64Checked sets the 64-bit integer that is off bytes from the start
+// of the struct to v, returning an error if the integer is outside the
+// data section.
+func (p Struct) SetUint64Checked(off DataOffset, v uint64) error {
 	addr, ok := p.dataAddress(off, 8)
 	if !ok {
-		panic(errOutOfBounds)
+		return errOutOfBounds
+	}
+	if p.seg.readOnly {
+		return errReadOnly
 	}
 	p.seg.writeUint64(addr, v)
+	return nil
 }
 
 // structFlags is a bitmask of flags for a pointer.
@@ -274,7 +371,7 @@ func copyStruct(cc copyContext, dst, src Struct) error {
 	for j := uint16(0); j < numSrcPtrs && j < numDstPtrs; j++ {
 		srcAddr := srcPtrSect.element(int32(j), wordSize)
 		dstAddr := dstPtrSect.element(int32(j), wordSize)
-		m, err := src.seg.readPtr(srcAddr)
+		m, err := src.seg.readPtr(srcAddr, src.depthLimit)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,57 @@
+package capnp
+
+import (
+	"testing"
+)
+
+func TestStructChecked(t *testing.T) {
+	_, seg, err := NewMessage(SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sz, want := s.Size(), (ObjectSize{DataSize: 8, PointerCount: 1}); sz != want {
+		t.Errorf("Size() = %v; want %v", sz, want)
+	}
+
+	if err := s.SetUint32Checked(4, 0xdeadbeef); err != nil {
+		t.Errorf("SetUint32Checked(4, ...) error: %v", err)
+	}
+	if x := s.Uint32(4); x != 0xdeadbeef {
+		t.Errorf("Uint32(4) = %#x; want 0xdeadbeef", x)
+	}
+	if err := s.SetBitChecked(63, true); err != nil {
+		t.Errorf("SetBitChecked(63, true) error: %v", err)
+	}
+	if !s.Bit(63) {
+		t.Error("Bit(63) = false; want true")
+	}
+
+	tests := []struct {
+		name string
+		f    func() error
+	}{
+		{"SetBitChecked(64, true)", func() error { return s.SetBitChecked(64, true) }},
+		{"SetUint8Checked(8, 1)", func() error { return s.SetUint8Checked(8, 1) }},
+		{"SetUint16Checked(7, 1)", func() error { return s.SetUint16Checked(7, 1) }},
+		{"SetUint32Checked(6, 1)", func() error { return s.SetUint32Checked(6, 1) }},
+		{"SetUint64Checked(1, 1)", func() error { return s.SetUint64Checked(1, 1) }},
+		{"SetPointer(1, nil)", func() error { return s.SetPointer(1, nil) }},
+		{"Adopt(1, Orphan{})", func() error { return s.Adopt(1, Orphan{}) }},
+		{"Struct{}.SetUint8Checked(0, 1)", func() error { return Struct{}.SetUint8Checked(0, 1) }},
+	}
+	for _, test := range tests {
+		if err := test.f(); err != errOutOfBounds {
+			t.Errorf("%s error = %v; want %v", test.name, err, errOutOfBounds)
+		}
+	}
+	if err := catchPanic(func() { s.SetUint64(1, 1) }); err != errOutOfBounds {
+		t.Errorf("SetUint64(1, 1) panic = %v; want %v", err, errOutOfBounds)
+	}
+	if x := s.Uint64(8); x != 0 {
+		t.Errorf("Uint64(8) = %d; want 0", x)
+	}
+}
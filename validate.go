@@ -0,0 +1,248 @@
+package capnp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Validate checks that every object reachable from msg's root is well
+// formed.  It checks that pointers and list tags are in bounds, that far
+// pointers lead to valid landing pads, and that no object contains a
+// pointer to itself or one of its ancestors.  The message's traversal
+// and depth limits apply, but reading with Validate does not count
+// against the message's ReadLimiter.
+//
+// Text and Data are encoded identically, so Validate can't tell which
+// pointers refer to text.  Use ValidateType to also check that text is
+// a NUL-terminated list of bytes.
+//
+// Problems are reported as a *ValidationError.
+func Validate(msg *Message) error {
+	return ValidateType(msg, PointerType{})
+}
+
+// ValidateType is like Validate, but also checks that the objects
+// reachable from msg's root match root, the type of the root pointer.
+// Text must be a list of bytes that ends in a NUL byte.
+func ValidateType(msg *Message, root PointerType) error {
+	v := new(validator)
+	v.rlimit.Reset(msg.traverseLimit())
+	seg, err := msg.Segment(0)
+	if err != nil {
+		return &ValidationError{Path: "root", Err: err}
+	}
+	if !seg.regionInBounds(0, wordSize) {
+		return &ValidationError{Path: "root", Err: errPointerAddress}
+	}
+	return v.pointer(seg, 0, msg.depthLimit(), root)
+}
+
+// A PointerType describes what a pointer refers to, as far as
+// ValidateType needs to know.  The zero PointerType describes a pointer
+// whose object is only checked for structure.
+type PointerType struct {
+	// Text is true if the pointer refers to Text.
+	Text bool
+
+	// Elem describes the elements of a list: each pointer in a list of
+	// pointers, or each struct in a list of structs.  A nil Elem is the
+	// same as the zero PointerType.
+	Elem *PointerType
+
+	// Pointer returns the type of pointer i in s, a struct that the
+	// pointer refers to.  If Pointer is nil, s's pointers are untyped.
+	// If Pointer returns an error, ValidateType stops and returns it.
+	Pointer func(s Struct, i uint16) (PointerType, error)
+}
+
+// A ValidationError describes a malformed pointer found by Validate.
+type ValidationError struct {
+	// Segment and Address locate the pointer or object that is invalid.
+	Segment SegmentID
+	Address Address
+
+	// Path is the sequence of pointers followed from the root, like
+	// "root.ptr[2].list[5]".  ptr[i] is the i'th pointer in a struct and
+	// list[i] is the i'th element of a list.
+	Path string
+
+	// Err is the problem with the pointer.
+	Err error
+}
+
+// Error returns the error message.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v at %s (segment %d, address %d)", e.Err, e.Path, e.Segment, uint32(e.Address))
+}
+
+// A validator walks a message's objects, keeping track of the path to
+// the current object.
+type validator struct {
+	rlimit ReadLimiter
+	path   []pathStep
+
+	// visiting holds the objects on the current path.
+	visiting map[objectKey]struct{}
+}
+
+type pathStep struct {
+	list  bool
+	index int
+}
+
+type objectKey struct {
+	seg  SegmentID
+	addr Address
+}
+
+func (v *validator) push(list bool, i int) {
+	v.path = append(v.path, pathStep{list, i})
+}
+
+func (v *validator) pop() {
+	v.path = v.path[:len(v.path)-1]
+}
+
+func (v *validator) error(s *Segment, addr Address, err error) error {
+	var buf bytes.Buffer
+	buf.WriteString("root")
+	for _, step := range v.path {
+		if step.list {
+			buf.WriteString(".list[")
+		} else {
+			buf.WriteString(".ptr[")
+		}
+		buf.WriteString(strconv.Itoa(step.index))
+		buf.WriteByte(']')
+	}
+	return &ValidationError{
+		Segment: s.id,
+		Address: addr,
+		Path:    buf.String(),
+		Err:     err,
+	}
+}
+
+// pointer validates the pointer at off and the object it points to.
+// depthLimit is the depth limit of the object containing the pointer.
+func (v *validator) pointer(s *Segment, off Address, depthLimit uint, typ PointerType) error {
+	val := s.readRawPointer(off)
+	ps, poff, pval, err := s.resolveFarPointer(off, val)
+	if err != nil {
+		return v.error(s, off, err)
+	}
+	if pval == 0 {
+		return nil
+	}
+	if depthLimit == 0 {
+		return v.error(s, off, ErrDepthLimit)
+	}
+	p, err := ps.pointerValue(poff, pval, depthLimit-1)
+	if err != nil {
+		return v.error(ps, poff, err)
+	}
+	if !v.rlimit.canRead(readSize(p)) {
+		return v.error(ps, poff, ErrTraverseLimit)
+	}
+	if typ.Text {
+		if err := checkText(p); err != nil {
+			return v.error(ps, poff, err)
+		}
+		return nil
+	}
+	switch p := p.(type) {
+	case Struct:
+		if p.size.isZero() {
+			return nil
+		}
+		key := objectKey{p.seg.id, p.off}
+		if err := v.enter(key, p.seg); err != nil {
+			return err
+		}
+		defer delete(v.visiting, key)
+		return v.structPointers(p, typ)
+	case List:
+		if p.size.PointerCount == 0 || p.length == 0 {
+			return nil
+		}
+		key := objectKey{p.seg.id, p.off}
+		if err := v.enter(key, p.seg); err != nil {
+			return err
+		}
+		defer delete(v.visiting, key)
+		var elem PointerType
+		if typ.Elem != nil {
+			elem = *typ.Elem
+		}
+		for i := 0; i < p.Len(); i++ {
+			v.push(true, i)
+			var err error
+			if p.flags&isCompositeList != 0 {
+				err = v.structPointers(p.Struct(i), elem)
+			} else {
+				addr, _ := p.primitiveElem(i, ObjectSize{PointerCount: 1})
+				err = v.pointer(p.seg, addr, p.depthLimit, elem)
+			}
+			if err != nil {
+				return err
+			}
+			v.pop()
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// enter marks the object at key as being on the current path, returning
+// an error if it already is.
+func (v *validator) enter(key objectKey, s *Segment) error {
+	if v.visiting == nil {
+		v.visiting = make(map[objectKey]struct{})
+	}
+	if _, ok := v.visiting[key]; ok {
+		return v.error(s, key.addr, errPointerCycle)
+	}
+	v.visiting[key] = struct{}{}
+	return nil
+}
+
+// structPointers validates the pointers of s, a struct of type typ.
+func (v *validator) structPointers(s Struct, typ PointerType) error {
+	for i := uint16(0); i < s.size.PointerCount; i++ {
+		var ptyp PointerType
+		if typ.Pointer != nil {
+			var err error
+			ptyp, err = typ.Pointer(s, i)
+			if err != nil {
+				return err
+			}
+		}
+		v.push(false, int(i))
+		if err := v.pointer(s.seg, s.pointerAddress(i), s.depthLimit, ptyp); err != nil {
+			return err
+		}
+		v.pop()
+	}
+	return nil
+}
+
+// checkText returns an error if p is not a NUL-terminated list of bytes.
+func checkText(p Pointer) error {
+	l, ok := p.(List)
+	if !ok || l.flags != 0 || l.size != (ObjectSize{DataSize: 1}) {
+		return errTextNotByteList
+	}
+	if l.length == 0 || l.seg.readUint8(l.off+Address(l.length-1)) != 0 {
+		return errTextNotTerminated
+	}
+	return nil
+}
+
+var (
+	errPointerCycle      = errors.New("capnp: pointer cycle")
+	errTextNotByteList   = errors.New("capnp: text is not a list of bytes")
+	errTextNotTerminated = errors.New("capnp: text is not NUL-terminated")
+)
@@ -0,0 +1,338 @@
+package capnp
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		arena Arena
+		path  string
+		seg   SegmentID
+		addr  Address
+		err   error
+	}{
+		{
+			name:  "null root",
+			arena: SingleSegment(rawWords(0)),
+		},
+		{
+			name: "nested lists with shared text",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 2}),
+				rawListPointer(1, pointerList, 1),
+				rawListPointer(1, compositeList, 2),
+				rawListPointer(3, byte1List, 4),
+				rawStructPointer(2, ObjectSize{PointerCount: 1}),
+				0,
+				rawListPointer(0, byte1List, 4),
+				0x0000000000636261,
+			)),
+		},
+		{
+			name:  "empty segment",
+			arena: SingleSegment(nil),
+			path:  "root",
+			err:   errPointerAddress,
+		},
+		{
+			name:  "root out of bounds",
+			arena: SingleSegment(rawWords(rawStructPointer(4, ObjectSize{DataSize: 8}))),
+			path:  "root",
+			err:   errPointerAddress,
+		},
+		{
+			name: "list element out of bounds",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				0,
+				0,
+				rawListPointer(0, pointerList, 6),
+				0, 0, 0, 0, 0,
+				rawStructPointer(100, ObjectSize{DataSize: 8}),
+			)),
+			path: "root.ptr[2].list[5]",
+			addr: 72,
+			err:  errPointerAddress,
+		},
+		{
+			name: "far pointer landing pad is far pointer",
+			arena: MultiSegment([][]byte{
+				rawWords(rawFarPointer(1, 0)),
+				rawWords(rawFarPointer(0, 0)),
+			}),
+			path: "root",
+			seg:  1,
+			err:  errBadLandingPad,
+		},
+		{
+			name: "far pointer to missing segment",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				rawFarPointer(3, 0),
+			)),
+			path: "root.ptr[0]",
+			addr: 8,
+			err:  errSegmentOutOfBounds,
+		},
+		{
+			name: "list tag is not a struct",
+			arena: SingleSegment(rawWords(
+				rawListPointer(0, compositeList, 1),
+				rawListPointer(0, byte1List, 1),
+				0,
+			)),
+			path: "root",
+			err:  errBadTag,
+		},
+		{
+			name: "list tag larger than list",
+			arena: SingleSegment(rawWords(
+				rawListPointer(0, compositeList, 1),
+				rawStructPointer(5, ObjectSize{DataSize: 8}),
+				0,
+			)),
+			path: "root",
+			err:  errBadTag,
+		},
+		{
+			name: "cycle",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				rawStructPointer(-1, ObjectSize{PointerCount: 1}),
+			)),
+			path: "root.ptr[0]",
+			addr: 8,
+			err:  errPointerCycle,
+		},
+		{
+			name: "cycle through list",
+			arena: SingleSegment(rawWords(
+				rawListPointer(0, pointerList, 1),
+				rawListPointer(-1, pointerList, 1),
+			)),
+			path: "root.list[0]",
+			addr: 8,
+			err:  errPointerCycle,
+		},
+	}
+	for _, test := range tests {
+		err := Validate(&Message{Arena: test.arena})
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("%s: Validate: %v", test.name, err)
+			}
+			continue
+		}
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("%s: Validate = %v; want *ValidationError", test.name, err)
+			continue
+		}
+		if verr.Err != test.err || verr.Path != test.path || verr.Segment != test.seg || verr.Address != test.addr {
+			t.Errorf("%s: Validate = &ValidationError{Segment: %d, Address: %d, Path: %q, Err: %v}; want &ValidationError{Segment: %d, Address: %d, Path: %q, Err: %v}",
+				test.name, verr.Segment, verr.Address, verr.Path, verr.Err, test.seg, test.addr, test.path, test.err)
+		}
+	}
+}
+
+func TestValidateBuiltMessage(t *testing.T) {
+	msg, seg, err := NewMessage(MultiSegment([][]byte{make([]byte, 0, 16)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts, err := NewTextList(seg, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range []string{"a", "b", "c"} {
+		if err := texts.Set(i, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := root.SetPointer(0, texts); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(msg); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	// A struct pointing to itself is a cycle.
+	if err := root.SetPointer(1, root); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(msg); err == nil {
+		t.Error("Validate on cyclic message succeeded; want error")
+	}
+	if err := root.SetPointer(1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(msg); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestValidateTraverseLimit(t *testing.T) {
+	msg := &Message{
+		Arena: SingleSegment(rawWords(
+			rawStructPointer(0, ObjectSize{DataSize: 16}),
+			0,
+			0,
+		)),
+		TraverseLimit: 8,
+	}
+	err := Validate(msg)
+	if verr, ok := err.(*ValidationError); !ok || verr.Err != ErrTraverseLimit {
+		t.Errorf("Validate = %v; want %v", err, ErrTraverseLimit)
+	}
+}
+
+func TestValidateType(t *testing.T) {
+	// The root struct's first pointer is text and its second pointer is
+	// a list of text.  Its third pointer is untyped.
+	text := PointerType{Text: true}
+	root := PointerType{
+		Pointer: func(s Struct, i uint16) (PointerType, error) {
+			switch i {
+			case 0:
+				return text, nil
+			case 1:
+				return PointerType{Elem: &text}, nil
+			default:
+				return PointerType{}, nil
+			}
+		},
+	}
+	tests := []struct {
+		name  string
+		arena Arena
+		path  string
+		addr  Address
+		err   error
+	}{
+		{
+			name: "valid",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				rawListPointer(2, byte1List, 4),
+				rawListPointer(2, pointerList, 1),
+				0,
+				0x0000000000636261,
+				rawListPointer(-2, byte1List, 4),
+			)),
+		},
+		{
+			name: "null text",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				0,
+				0,
+				0,
+			)),
+		},
+		{
+			name: "text is not terminated",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				rawListPointer(2, byte1List, 3),
+				0,
+				0,
+				0x0000000000636261,
+			)),
+			path: "root.ptr[0]",
+			addr: 8,
+			err:  errTextNotTerminated,
+		},
+		{
+			name: "text is empty",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				rawListPointer(2, byte1List, 0),
+				0,
+				0,
+			)),
+			path: "root.ptr[0]",
+			addr: 8,
+			err:  errTextNotTerminated,
+		},
+		{
+			name: "text is a struct",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				rawStructPointer(2, ObjectSize{DataSize: 8}),
+				0,
+				0,
+				0x0000000000636261,
+			)),
+			path: "root.ptr[0]",
+			addr: 8,
+			err:  errTextNotByteList,
+		},
+		{
+			name: "text is a list of words",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				rawListPointer(2, byte8List, 1),
+				0,
+				0,
+				0,
+			)),
+			path: "root.ptr[0]",
+			addr: 8,
+			err:  errTextNotByteList,
+		},
+		{
+			name: "text list element is not terminated",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				0,
+				rawListPointer(1, pointerList, 2),
+				0,
+				rawListPointer(1, byte1List, 2),
+				rawListPointer(0, byte1List, 1),
+				0x0000000000000061,
+			)),
+			path: "root.ptr[1].list[1]",
+			addr: 40,
+			err:  errTextNotTerminated,
+		},
+		{
+			name: "data is not checked",
+			arena: SingleSegment(rawWords(
+				rawStructPointer(0, ObjectSize{PointerCount: 3}),
+				0,
+				0,
+				rawListPointer(0, byte1List, 3),
+				0x0000000000636261,
+			)),
+		},
+	}
+	for _, test := range tests {
+		err := ValidateType(&Message{Arena: test.arena}, root)
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("%s: ValidateType: %v", test.name, err)
+			}
+			continue
+		}
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("%s: ValidateType = %v; want *ValidationError", test.name, err)
+			continue
+		}
+		if verr.Err != test.err || verr.Path != test.path || verr.Address != test.addr {
+			t.Errorf("%s: ValidateType = &ValidationError{Address: %d, Path: %q, Err: %v}; want &ValidationError{Address: %d, Path: %q, Err: %v}",
+				test.name, verr.Address, verr.Path, verr.Err, test.addr, test.path, test.err)
+		}
+
+		// Without types, the message is well formed.
+		if err := Validate(&Message{Arena: test.arena}); err != nil {
+			t.Errorf("%s: Validate: %v", test.name, err)
+		}
+	}
+}